@@ -10,6 +10,7 @@ import (
 	"math/big"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/dustinxie/ecc"
 	"golang.org/x/crypto/argon2"
@@ -66,6 +67,12 @@ func NewAddress(pub *ecdsa.PublicKey) Address {
 	return Address(hex.EncodeToString(hash[:32]))
 }
 
+// validAddress reports whether the address is 32 bytes of lowercase hex
+func validAddress(addr Address) bool {
+	_, err := hex.DecodeString(string(addr))
+	return err == nil && len(addr) == 64 && string(addr) == strings.ToLower(string(addr))
+}
+
 func NewAccount() (Account, error) {
 	priv, err := ecdsa.GenerateKey(ecc.P256k1(), rand.Reader)
 	if err != nil {
@@ -14,6 +14,15 @@ import (
 
 const blocksFile = "blocks.json"
 
+// blockOverhead bounds the encoded size of a signed block without its
+// transactions
+const blockOverhead = 512
+
+// maxBlockLen bounds the lines of the block store. The received blocks are
+// bounded by the gRPC message size, which leaves room for the framing above
+// the max block size
+const maxBlockLen = MaxBlockSizeLimit + 1<<20
+
 type Block struct {
 	Number     uint64  `json:"number"`
 	Parent     Hash    `json:"parent"`
@@ -33,16 +42,29 @@ func InitBlockStore(dir string) error {
 	return nil
 }
 
-func NewBlock(number uint64, parent Hash, txs []SigTx) (Block, error) {
+// blockMerkle returns the merkle tree and root of the block transactions.
+// An empty block has no merkle tree and the zero root
+func blockMerkle(txs []SigTx) ([]Hash, Hash, error) {
+	if len(txs) == 0 {
+		return nil, Hash{}, nil
+	}
 	merkleTree, err := MerkleHash(txs, TxHash, TxPairHash)
+	if err != nil {
+		return nil, Hash{}, err
+	}
+	return merkleTree, merkleTree[0], nil
+}
+
+func NewBlock(clock Clock, number uint64, parent Hash, txs []SigTx) (Block, error) {
+	merkleTree, merkleRoot, err := blockMerkle(txs)
 	if err != nil {
 		return Block{}, err
 	}
 
 	blk := Block{
 		Number: number, Parent: parent, Txs: txs,
-		merkleTree: merkleTree, MerkleRoot: merkleTree[0],
-		Time: time.Now(),
+		merkleTree: merkleTree, MerkleRoot: merkleRoot,
+		Time: clock.Now(),
 	}
 	return blk, nil
 }
@@ -51,9 +73,13 @@ func (b Block) Hash() Hash {
 	return NewHash(b)
 }
 
+// SigBlock is a block signed by the authority or, on a chain of validators,
+// by its proposer. The commit certificate of the validators finalizes the
+// block and is not part of the block hash
 type SigBlock struct {
 	Block
-	Sig []byte `json:"sig"`
+	Sig    []byte  `json:"sig"`
+	Commit *Commit `json:"commit,omitempty"`
 }
 
 func NewSigBlock(blk Block, sig []byte) SigBlock {
@@ -64,9 +90,17 @@ func NewSigBlock(blk Block, sig []byte) SigBlock {
 }
 
 func (b SigBlock) Hash() Hash {
+	b.Commit = nil
 	return NewHash(b)
 }
 
+// Size returns the encoded size of the block in bytes without its commit
+func (b SigBlock) Size() int {
+	b.Commit = nil
+	jblk, _ := json.Marshal(b)
+	return len(jblk)
+}
+
 func (b SigBlock) String() string {
 	var bld strings.Builder
 	bld.WriteString(
@@ -128,23 +162,22 @@ func ReadBlocks(dir string) (
 	}
 	blocks := func(yield func(err error, blk SigBlock) bool) {
 		sca := bufio.NewScanner(file)
+		sca.Buffer(make([]byte, 0, 64<<10), maxBlockLen)
 		more := true
 
-		for sca.Scan() && more {
-			err := sca.Err()
-			if err != nil {
-				yield(err, SigBlock{})
-				return
-			}
-
+		for more && sca.Scan() {
 			var blk SigBlock
-			err = json.Unmarshal(sca.Bytes(), &blk)
+			err := json.Unmarshal(sca.Bytes(), &blk)
 			if err != nil {
 				more = yield(err, SigBlock{})
 				continue
 			}
 			more = yield(nil, blk)
 		}
+		err := sca.Err()
+		if more && err != nil {
+			yield(err, SigBlock{})
+		}
 	}
 
 	return blocks, close, nil
@@ -165,16 +198,16 @@ func ReadBlocksBytes(dir string) (
 	}
 	blocks := func(yield func(err error, jblk []byte) bool) {
 		sca := bufio.NewScanner(file)
+		sca.Buffer(make([]byte, 0, 64<<10), maxBlockLen)
 		more := true
 
-		for sca.Scan() && more {
-			err := sca.Err()
-			if err != nil {
-				yield(err, nil)
-				return
-			}
+		for more && sca.Scan() {
 			more = yield(nil, sca.Bytes())
 		}
+		err := sca.Err()
+		if more && err != nil {
+			yield(err, nil)
+		}
 	}
 	return blocks, close, nil
 }
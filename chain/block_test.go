@@ -0,0 +1,63 @@
+package chain
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestReadBlocksOverScannerDefault(t *testing.T) {
+	// The default buffer of a scanner stops at lines of 64 KiB
+	chain := newTestChain(t, Params{})
+	blk := chain.block(t, chain.txs(t, 500))
+	if blk.Size() <= 64<<10 {
+		t.Fatalf("expected a block over 64 KiB, got %d bytes", blk.Size())
+	}
+	dir := t.TempDir()
+	for range 2 {
+		err := blk.Write(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	blocks, closeBlocks, err := ReadBlocks(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeBlocks()
+	count := 0
+	for err, read := range blocks {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if read.Hash() != blk.Hash() {
+			t.Fatalf("expected block %v, got %v", blk.Hash(), read.Hash())
+		}
+		count++
+	}
+	if count != 2 {
+		t.Fatalf("expected 2 blocks, got %d", count)
+	}
+	jblk, err := json.Marshal(blk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blocksBytes, closeBytes, err := ReadBlocksBytes(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeBytes()
+	count = 0
+	for err, read := range blocksBytes {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(read, jblk) {
+			t.Fatalf("expected the encoded block, got %d bytes", len(read))
+		}
+		count++
+	}
+	if count != 2 {
+		t.Fatalf("expected 2 blocks, got %d", count)
+	}
+}
@@ -0,0 +1,21 @@
+package chain
+
+import "time"
+
+// MaxBlockTimeDrift bounds how far in the future of the local clock the time
+// of an applied block can be
+const MaxBlockTimeDrift = 15 * time.Second
+
+// Clock tells the time of the created blocks and transactions
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// SystemClock is the local clock of the node
+var SystemClock Clock = systemClock{}
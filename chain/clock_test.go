@@ -0,0 +1,65 @@
+package chain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestApplyBlockTime(t *testing.T) {
+	cases := []struct {
+		name string
+		// parent is the offset of the time of block 1 from the genesis time,
+		// zero when the tested block is block 1
+		parent time.Duration
+		// at returns the block time from the genesis time and the clock
+		at  func(gen, now time.Time) time.Time
+		err error
+	}{
+		{"after genesis", 0, func(gen, _ time.Time) time.Time {
+			return gen.Add(time.Nanosecond)
+		}, nil},
+		{"at genesis", 0, func(gen, _ time.Time) time.Time {
+			return gen
+		}, ErrBlockTime},
+		{"before genesis", 0, func(gen, _ time.Time) time.Time {
+			return gen.Add(-time.Second)
+		}, ErrBlockTime},
+		{"after parent", 30 * time.Second, func(gen, _ time.Time) time.Time {
+			return gen.Add(30*time.Second + time.Nanosecond)
+		}, nil},
+		{"at parent", 30 * time.Second, func(gen, _ time.Time) time.Time {
+			return gen.Add(30 * time.Second)
+		}, ErrBlockTime},
+		{"before parent", 30 * time.Second, func(gen, _ time.Time) time.Time {
+			return gen.Add(29 * time.Second)
+		}, ErrBlockTime},
+		{"at max drift", 0, func(_, now time.Time) time.Time {
+			return now.Add(MaxBlockTimeDrift)
+		}, nil},
+		{"beyond max drift", 0, func(_, now time.Time) time.Time {
+			return now.Add(MaxBlockTimeDrift + time.Nanosecond)
+		}, ErrBlockFuture},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			chain := newTestChain(t, Params{})
+			gen := chain.state.genesisTime
+			if c.parent > 0 {
+				err := chain.state.ApplyBlockToState(chain.blockAt(t, gen.Add(c.parent), chain.txs(t, 1)))
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			txs := chain.txs(t, 1)
+			blk := chain.blockAt(t, c.at(gen, chain.clock.now), txs)
+			err := chain.state.ApplyBlockToState(blk)
+			if c.err == nil && err != nil {
+				t.Fatal(err)
+			}
+			if c.err != nil && !errors.Is(err, c.err) {
+				t.Fatalf("expected %v, got %v", c.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package chain
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/dustinxie/ecc"
+)
+
+type VoteType string
+
+const (
+	Prevote   VoteType = "prevote"
+	Precommit VoteType = "precommit"
+)
+
+// Vote is a prevote or a precommit of a validator for a block in a round of
+// the consensus. The zero block hash votes for no block
+type Vote struct {
+	Type      VoteType `json:"type"`
+	Height    uint64   `json:"height"`
+	Round     uint32   `json:"round"`
+	BlockHash Hash     `json:"blockHash,omitzero"`
+}
+
+func (v Vote) Hash() Hash {
+	return NewHash(v)
+}
+
+type SigVote struct {
+	Vote
+	Validator Address `json:"validator"`
+	Sig       []byte  `json:"sig"`
+}
+
+func (a Account) SignVote(vote Vote) (SigVote, error) {
+	sig, err := ecc.SignBytes(a.prv, vote.Hash().Bytes(), ecc.LowerS|ecc.RecID)
+	if err != nil {
+		return SigVote{}, err
+	}
+	return SigVote{Vote: vote, Validator: a.Address(), Sig: sig}, nil
+}
+
+// signer recovers the address of the account that signed the hash
+func signer(hash Hash, sig []byte) (Address, error) {
+	pub, err := ecc.RecoverPubkey("P-256k1", hash.Bytes(), sig)
+	if err != nil {
+		return "", err
+	}
+	return NewAddress(pub), nil
+}
+
+func VerifyVote(vote SigVote) (bool, error) {
+	acc, err := signer(vote.Hash(), vote.Sig)
+	if err != nil {
+		return false, err
+	}
+	return acc == vote.Validator, nil
+}
+
+// Proposal is a block proposed in a round of the consensus. POLRound is the
+// round of the prevotes of a re-proposed block, or -1 for a new block
+type Proposal struct {
+	Height   uint64   `json:"height"`
+	Round    uint32   `json:"round"`
+	POLRound int32    `json:"polRound"`
+	Block    SigBlock `json:"block"`
+}
+
+func (p Proposal) Hash() Hash {
+	return NewHash(struct {
+		Height    uint64
+		Round     uint32
+		POLRound  int32
+		BlockHash Hash
+	}{p.Height, p.Round, p.POLRound, p.Block.Hash()})
+}
+
+type SigProposal struct {
+	Proposal
+	Proposer Address `json:"proposer"`
+	Sig      []byte  `json:"sig"`
+}
+
+func (a Account) SignProposal(prop Proposal) (SigProposal, error) {
+	sig, err := ecc.SignBytes(a.prv, prop.Hash().Bytes(), ecc.LowerS|ecc.RecID)
+	if err != nil {
+		return SigProposal{}, err
+	}
+	return SigProposal{Proposal: prop, Proposer: a.Address(), Sig: sig}, nil
+}
+
+func VerifyProposal(prop SigProposal) (bool, error) {
+	acc, err := signer(prop.Hash(), prop.Sig)
+	if err != nil {
+		return false, err
+	}
+	return acc == prop.Proposer, nil
+}
+
+// ConsensusMsg carries a proposal or a vote between the validators
+type ConsensusMsg struct {
+	Proposal *SigProposal `json:"proposal,omitempty"`
+	Vote     *SigVote     `json:"vote,omitempty"`
+}
+
+type CommitSig struct {
+	Validator Address `json:"validator"`
+	Sig       []byte  `json:"sig"`
+}
+
+// Commit is the certificate of the precommits of more than two thirds of the
+// validators for a block in a round
+type Commit struct {
+	Round uint32      `json:"round"`
+	Sigs  []CommitSig `json:"sigs"`
+}
+
+// NewCommit collects the signatures of the precommits for the block
+func NewCommit(round uint32, precommits []SigVote) *Commit {
+	sigs := make([]CommitSig, len(precommits))
+	for i, vote := range precommits {
+		sigs[i] = CommitSig{Validator: vote.Validator, Sig: vote.Sig}
+	}
+	return &Commit{Round: round, Sigs: sigs}
+}
+
+// Quorum returns the number of votes of more than two thirds of the
+// validators
+func Quorum(validators int) int {
+	return validators*2/3 + 1
+}
+
+// VerifyCommit checks that the commit of the block holds the precommits of a
+// quorum of distinct validators. The signers are checked before any
+// signature is recovered
+func VerifyCommit(blk SigBlock, validators []Address) error {
+	if blk.Commit == nil {
+		return fmt.Errorf("%w: missing commit", ErrBlockCommit)
+	}
+	sigs := blk.Commit.Sigs
+	if len(sigs) > len(validators) {
+		return fmt.Errorf(
+			"%w: %d signatures of %d validators", ErrBlockCommit,
+			len(sigs), len(validators),
+		)
+	}
+	signed := make(map[Address]bool, len(sigs))
+	for _, sig := range sigs {
+		if !slices.Contains(validators, sig.Validator) {
+			return fmt.Errorf("%w: %v is not a validator", ErrBlockCommit, sig.Validator)
+		}
+		if signed[sig.Validator] {
+			return fmt.Errorf("%w: duplicate signature of %v", ErrBlockCommit, sig.Validator)
+		}
+		signed[sig.Validator] = true
+	}
+	if len(signed) < Quorum(len(validators)) {
+		return fmt.Errorf(
+			"%w: %d of %d validators, expected %d", ErrBlockCommit,
+			len(signed), len(validators), Quorum(len(validators)),
+		)
+	}
+	vote := Vote{
+		Type: Precommit, Height: blk.Number, Round: blk.Commit.Round,
+		BlockHash: blk.Hash(),
+	}
+	hash := vote.Hash()
+	for _, sig := range sigs {
+		acc, err := signer(hash, sig.Sig)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrBlockCommit, err)
+		}
+		if acc != sig.Validator {
+			return fmt.Errorf("%w: invalid signature of %v", ErrBlockCommit, sig.Validator)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package chain
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestQuorum(t *testing.T) {
+	quorums := map[int]int{1: 1, 2: 2, 3: 3, 4: 3, 5: 4, 6: 5, 7: 5}
+	for validators, quorum := range quorums {
+		if got := Quorum(validators); got != quorum {
+			t.Errorf("expected quorum %d of %d validators, got %d", quorum, validators, got)
+		}
+	}
+}
+
+// newValidators creates n validator accounts and their addresses
+func newValidators(t *testing.T, n int) ([]Account, []Address) {
+	t.Helper()
+	accs := make([]Account, n)
+	addrs := make([]Address, n)
+	for i := range accs {
+		acc, err := NewAccount()
+		if err != nil {
+			t.Fatal(err)
+		}
+		accs[i], addrs[i] = acc, acc.Address()
+	}
+	return accs, addrs
+}
+
+// precommits signs the precommits of the signers for the block in the round
+func precommits(t *testing.T, blk SigBlock, round uint32, signers []Account) []SigVote {
+	t.Helper()
+	votes := make([]SigVote, len(signers))
+	for i, acc := range signers {
+		vote := Vote{Type: Precommit, Height: blk.Number, Round: round, BlockHash: blk.Hash()}
+		sigVote, err := acc.SignVote(vote)
+		if err != nil {
+			t.Fatal(err)
+		}
+		votes[i] = sigVote
+	}
+	return votes
+}
+
+func TestVerifyCommit(t *testing.T) {
+	outsider, _ := newValidators(t, 1)
+	cases := []struct {
+		name string
+		n    int
+		// signers returns the signers of the precommits from the validators
+		signers func(vals []Account) []Account
+		// round is the round of the commit, the precommits are of round 0
+		round  uint32
+		commit bool
+		// reason is part of the error of an invalid commit
+		reason string
+	}{
+		{"n=1 quorum", 1, func(v []Account) []Account { return v }, 0, true, ""},
+		{"n=1 no sigs", 1, func(v []Account) []Account { return nil }, 0, true, "0 of 1 validators"},
+		{"n=3 quorum", 3, func(v []Account) []Account { return v }, 0, true, ""},
+		{"n=3 below quorum", 3, func(v []Account) []Account { return v[:2] }, 0, true, "2 of 3 validators"},
+		{"n=4 quorum", 4, func(v []Account) []Account { return v[:3] }, 0, true, ""},
+		{"n=4 all", 4, func(v []Account) []Account { return v }, 0, true, ""},
+		{"n=4 below quorum", 4, func(v []Account) []Account { return v[:2] }, 0, true, "2 of 4 validators"},
+		{"duplicate sigs", 4, func(v []Account) []Account {
+			return []Account{v[0], v[1], v[1]}
+		}, 0, true, "duplicate signature"},
+		{"more sigs than validators", 3, func(v []Account) []Account {
+			return append(v, v[0])
+		}, 0, true, "4 signatures of 3 validators"},
+		{"non-validator", 4, func(v []Account) []Account {
+			return append(v[:2:2], outsider[0])
+		}, 0, true, "is not a validator"},
+		{"wrong round", 4, func(v []Account) []Account { return v }, 1, true, "invalid signature"},
+		{"missing commit", 4, func(v []Account) []Account { return v }, 0, false, "missing commit"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			vals, addrs := newValidators(t, c.n)
+			blk, err := NewBlock(SystemClock, 1, Hash{}, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sblk, err := vals[0].SignBlock(blk)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c.commit {
+				votes := precommits(t, sblk, 0, c.signers(vals))
+				sblk.Commit = NewCommit(c.round, votes)
+			}
+			err = VerifyCommit(sblk, addrs)
+			if c.reason == "" && err != nil {
+				t.Fatal(err)
+			}
+			if c.reason != "" &&
+				(!errors.Is(err, ErrBlockCommit) || !strings.Contains(err.Error(), c.reason)) {
+				t.Fatalf("expected %v: %v, got %v", ErrBlockCommit, c.reason, err)
+			}
+		})
+	}
+}
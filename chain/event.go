@@ -1,8 +1,24 @@
 package chain
 
 import (
+	"bufio"
 	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const (
+	// The events are persisted in files of eventsFileLen events, so that a
+	// replay only reads the files after its cursor. The files of the oldest
+	// events are removed beyond maxEventsFiles
+	eventsFileLen  = 10_000
+	maxEventsFiles = 10
+	// maxEventLen bounds the lines of the event files. Block events embed the
+	// whole block in base64
+	maxEventLen = 2 * maxBlockLen
 )
 
 type EventType uint64
@@ -11,18 +27,29 @@ const (
 	EvAll   EventType = 0
 	EvTx    EventType = 1
 	EvBlock EventType = 2
+	EvHTLC  EventType = 3
 )
 
 func NewEventType(eventStr string) EventType {
+	evType, err := ParseEventType(eventStr)
+	if err != nil {
+		panic(err)
+	}
+	return evType
+}
+
+func ParseEventType(eventStr string) (EventType, error) {
 	switch eventStr {
 	case "all":
-		return EvAll
+		return EvAll, nil
 	case "tx":
-		return EvTx
+		return EvTx, nil
 	case "blk", "block":
-		return EvBlock
+		return EvBlock, nil
+	case "htlc":
+		return EvHTLC, nil
 	default:
-		panic(fmt.Sprintf("unsupported event type: %v", eventStr))
+		return EvAll, fmt.Errorf("unsupported event type: %v", eventStr)
 	}
 }
 
@@ -32,12 +59,16 @@ func (t EventType) String() string {
 		return "tx"
 	case EvBlock:
 		return "blk"
+	case EvHTLC:
+		return "htlc"
 	default:
 		return "ev"
 	}
 }
 
 type Event struct {
+	Seq    uint64    `json:"seq"`
+	Height uint64    `json:"height"`
 	Type   EventType `json:"type"`
 	Action string    `json:"action"`
 	Body   []byte    `json:"body"`
@@ -49,25 +80,232 @@ func NewEvent(evType EventType, action string, body []byte) Event {
 
 func (e Event) String() string {
 	switch e.Type {
-	case EvTx:
+	case EvTx, EvHTLC:
 		var tx SigTx
 		err := json.Unmarshal(e.Body, &tx)
 		if err != nil {
 			return err.Error()
 		}
-		return fmt.Sprintf("%v %v\n%v", e.Type, e.Action, tx)
+		return fmt.Sprintf("%v %v #%v\n%v", e.Type, e.Action, e.Seq, tx)
 	case EvBlock:
 		var blk SigBlock
 		err := json.Unmarshal(e.Body, &blk)
 		if err != nil {
 			return err.Error()
 		}
-		return fmt.Sprintf("%v %v\n%v", e.Type, e.Action, blk)
+		return fmt.Sprintf("%v %v #%v\n%v", e.Type, e.Action, e.Seq, blk)
 	default:
 		return fmt.Sprintf("error: unsupported event type %v", e.Type)
 	}
 }
 
+// EventFilter selects events by type, action, block height, and the
+// transfers of transactions. Empty filters select all events
+type EventFilter struct {
+	Types     []EventType `json:"types,omitempty"`
+	Actions   []string    `json:"actions,omitempty"`
+	Account   string      `json:"account,omitempty"`
+	MinValue  uint64      `json:"minValue,omitempty"`
+	MinTxs    uint64      `json:"minTxs,omitempty"`
+	MinHeight uint64      `json:"minHeight,omitempty"`
+	MaxHeight uint64      `json:"maxHeight,omitempty"`
+}
+
+// Match evaluates the filter against the event. A block event matches the
+// account and the minimum value when any of its transactions does
+func (f EventFilter) Match(event Event) bool {
+	if len(f.Types) > 0 && !slices.Contains(f.Types, EvAll) &&
+		!slices.Contains(f.Types, event.Type) ||
+		len(f.Actions) > 0 && !slices.Contains(f.Actions, event.Action) ||
+		event.Height < f.MinHeight ||
+		f.MaxHeight > 0 && event.Height > f.MaxHeight {
+		return false
+	}
+	if len(f.Account) == 0 && f.MinValue == 0 && f.MinTxs == 0 {
+		return true
+	}
+	switch event.Type {
+	case EvTx, EvHTLC:
+		var tx SigTx
+		err := json.Unmarshal(event.Body, &tx)
+		if err != nil {
+			return false
+		}
+		return f.matchTx(tx)
+	case EvBlock:
+		var blk SigBlock
+		err := json.Unmarshal(event.Body, &blk)
+		if err != nil {
+			return false
+		}
+		if uint64(len(blk.Txs)) < f.MinTxs {
+			return false
+		}
+		if len(f.Account) == 0 && f.MinValue == 0 {
+			return true
+		}
+		return slices.ContainsFunc(blk.Txs, f.matchTx)
+	default:
+		return false
+	}
+}
+
+// matchTx matches every transfer of a batch transaction individually
+func (f EventFilter) matchTx(tx SigTx) bool {
+	prefix := strings.HasPrefix
+	for _, tr := range tx.Transfers() {
+		if (len(f.Account) == 0 ||
+			prefix(string(tx.From), f.Account) ||
+			prefix(string(tr.To), f.Account)) &&
+			tr.Value >= f.MinValue {
+			return true
+		}
+	}
+	return false
+}
+
+// eventsFile returns the index of the events file of the sequence number
+func eventsFile(seq uint64) uint64 {
+	if seq == 0 {
+		return 0
+	}
+	return (seq - 1) / eventsFileLen
+}
+
+func eventsPath(dir string, file uint64) string {
+	return filepath.Join(dir, fmt.Sprintf("events-%06d.json", file))
+}
+
+// eventsFiles returns the indices of the events files in order
+func eventsFiles(dir string) ([]uint64, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "events-*.json"))
+	if err != nil {
+		return nil, err
+	}
+	files := make([]uint64, 0, len(paths))
+	for _, path := range paths {
+		var file uint64
+		_, err := fmt.Sscanf(filepath.Base(path), "events-%d.json", &file)
+		if err != nil {
+			continue
+		}
+		files = append(files, file)
+	}
+	slices.Sort(files)
+	return files, nil
+}
+
+// Write appends the event to the events file of its sequence number. The
+// first event of a file removes the files of the oldest events
+func (e Event) Write(dir string) error {
+	file := eventsFile(e.Seq)
+	if e.Seq%eventsFileLen == 1 && file >= maxEventsFiles {
+		files, err := eventsFiles(dir)
+		if err != nil {
+			return err
+		}
+		for _, old := range files {
+			if old > file-maxEventsFiles {
+				break
+			}
+			err = os.Remove(eventsPath(dir, old))
+			if err != nil {
+				return err
+			}
+		}
+	}
+	path := eventsPath(dir, file)
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return json.NewEncoder(f).Encode(e)
+}
+
+// ReadEvents reads the persisted events after the sequence number in the
+// sequence order. Only the events files from the one of the sequence number
+// are read. An empty sequence is returned when no events have been persisted
+// yet
+func ReadEvents(dir string, after uint64) (
+	func(yield func(err error, event Event) bool), func(), error,
+) {
+	files, err := eventsFiles(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	first := eventsFile(after + 1)
+	var file *os.File
+	close := func() {
+		if file != nil {
+			file.Close()
+		}
+	}
+	events := func(yield func(err error, event Event) bool) {
+		for _, idx := range files {
+			if idx < first {
+				continue
+			}
+			close()
+			f, err := os.Open(eventsPath(dir, idx))
+			if err != nil {
+				file = nil
+				yield(err, Event{})
+				return
+			}
+			file = f
+			sca := bufio.NewScanner(file)
+			sca.Buffer(make([]byte, 0, 64<<10), maxEventLen)
+			for sca.Scan() {
+				var event Event
+				err := json.Unmarshal(sca.Bytes(), &event)
+				if err != nil {
+					if !yield(err, Event{}) {
+						return
+					}
+					continue
+				}
+				if event.Seq <= after {
+					continue
+				}
+				if !yield(nil, event) {
+					return
+				}
+			}
+			err = sca.Err()
+			if err != nil {
+				yield(err, Event{})
+				return
+			}
+		}
+	}
+
+	return events, close, nil
+}
+
+// LastEvent returns the sequence number of the last persisted event. Only
+// the last events file is read
+func LastEvent(dir string) (uint64, error) {
+	files, err := eventsFiles(dir)
+	if err != nil || len(files) == 0 {
+		return 0, err
+	}
+	events, closeEvents, err := ReadEvents(dir, files[len(files)-1]*eventsFileLen)
+	if err != nil {
+		return 0, err
+	}
+	defer closeEvents()
+	var seq uint64
+	for err, event := range events {
+		if err != nil {
+			return 0, err
+		}
+		seq = event.Seq
+	}
+	return seq, nil
+}
+
 type EventPublisher interface {
 	PublishEvent(event Event)
 }
@@ -0,0 +1,100 @@
+package chain
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestReadEvents(t *testing.T) {
+	cases := []struct {
+		name   string
+		events uint64
+		after  uint64
+		first  uint64
+		count  uint64
+	}{
+		{"no events", 0, 0, 0, 0},
+		{"all events", 5, 0, 1, 5},
+		{"after a cursor", 5, 3, 4, 2},
+		{"after the last event", 5, 5, 0, 0},
+		{"across files", eventsFileLen + 5, eventsFileLen - 2, eventsFileLen - 1, 7},
+		{"oldest files removed", (maxEventsFiles + 1) * eventsFileLen, 0, eventsFileLen + 1,
+			maxEventsFiles * eventsFileLen},
+		{"cursor before the oldest file", (maxEventsFiles+1)*eventsFileLen + 1, 5,
+			2*eventsFileLen + 1, (maxEventsFiles-1)*eventsFileLen + 1},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for seq := range c.events {
+				err := Event{Seq: seq + 1, Type: EvTx, Action: "validated"}.Write(dir)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			events, closeEvents, err := ReadEvents(dir, c.after)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer closeEvents()
+			var first, count uint64
+			for err, event := range events {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if count == 0 {
+					first = event.Seq
+				}
+				if event.Seq != first+count {
+					t.Fatalf("expected event %d, got %d", first+count, event.Seq)
+				}
+				count++
+			}
+			if first != c.first || count != c.count {
+				t.Fatalf("expected %d events from %d, got %d from %d", c.count, c.first, count, first)
+			}
+			last, err := LastEvent(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if last != c.events {
+				t.Fatalf("expected last event %d, got %d", c.events, last)
+			}
+		})
+	}
+}
+
+func TestReadEventsErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		line string
+		err  string
+	}{
+		{"invalid event", "{\"seq\": \n", "unexpected end"},
+		{"event too long", `{"body": "` + strings.Repeat("a", maxEventLen) + "\"}\n", "too long"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			dir := t.TempDir()
+			err := Event{Seq: 1}.Write(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			f, err := os.OpenFile(eventsPath(dir, 0), os.O_WRONLY|os.O_APPEND, 0600)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = f.WriteString(c.line)
+			f.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+			// The sequence is not restored from a partly read events file
+			_, err = LastEvent(dir)
+			if err == nil || !strings.Contains(err.Error(), c.err) {
+				t.Fatalf("expected error %q, got %v", c.err, err)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package chain
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 	"time"
@@ -11,11 +12,71 @@ import (
 
 const genesisFile = "genesis.json"
 
+// Duration is a time.Duration encoded as a string, e.g. "10s"
+type Duration time.Duration
+
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var str string
+	err := json.Unmarshal(data, &str)
+	if err != nil {
+		return err
+	}
+	dur, err := time.ParseDuration(str)
+	if err != nil {
+		return err
+	}
+	*d = Duration(dur)
+	return nil
+}
+
+const (
+	// DefaultMaxBlockTxs and DefaultMaxBlockSize limit the blocks when the
+	// genesis leaves the limits unset
+	DefaultMaxBlockTxs  = 1000
+	DefaultMaxBlockSize = 1 << 20
+	// MaxBlockSizeLimit caps the max block size of the genesis
+	MaxBlockSizeLimit = 32 << 20
+)
+
+// Params are the chain parameters. The zero block period leaves the node
+// default, the zero limits take the default limits
+type Params struct {
+	BlockPeriod  Duration `json:"blockPeriod,omitzero"`
+	MaxBlockTxs  int      `json:"maxBlockTxs,omitzero"`
+	MaxBlockSize int      `json:"maxBlockSize,omitzero"`
+}
+
+// TxLimit returns the max number of transactions in a block
+func (p Params) TxLimit() int {
+	if p.MaxBlockTxs == 0 {
+		return DefaultMaxBlockTxs
+	}
+	return p.MaxBlockTxs
+}
+
+// SizeLimit returns the max encoded size of a block in bytes
+func (p Params) SizeLimit() int {
+	if p.MaxBlockSize == 0 {
+		return DefaultMaxBlockSize
+	}
+	return p.MaxBlockSize
+}
+
 type Genesis struct {
-	Chain     string             `json:"chain"`
-	Authority Address            `json:"authority"`
-	Balances  map[Address]uint64 `json:"balances"`
-	Time      time.Time          `json:"time"`
+	Chain      string             `json:"chain"`
+	Authority  Address            `json:"authority"`
+	Balances   map[Address]uint64 `json:"balances"`
+	Validators []Address          `json:"validators,omitempty"`
+	Params     Params             `json:"params,omitzero"`
+	Time       time.Time          `json:"time"`
 }
 
 type SigGenesis struct {
@@ -40,6 +101,63 @@ func (g Genesis) Hash() Hash {
 	return NewHash(g)
 }
 
+// Validate checks the chain name, the addresses, the balances, and the
+// chain parameters of the genesis
+func (g Genesis) Validate() error {
+	if len(g.Chain) == 0 {
+		return fmt.Errorf("genesis: chain name is empty")
+	}
+	if !validAddress(g.Authority) {
+		return fmt.Errorf("genesis: invalid authority %v", g.Authority)
+	}
+	if len(g.Balances) == 0 {
+		return fmt.Errorf("genesis: no balances")
+	}
+	for acc, balance := range g.Balances {
+		if !validAddress(acc) {
+			return fmt.Errorf("genesis: invalid account %v", acc)
+		}
+		if balance == 0 {
+			return fmt.Errorf("genesis: zero balance of %v", acc)
+		}
+	}
+	seen := make(map[Address]bool, len(g.Validators))
+	for _, val := range g.Validators {
+		if !validAddress(val) {
+			return fmt.Errorf("genesis: invalid validator %v", val)
+		}
+		if seen[val] {
+			return fmt.Errorf("genesis: duplicate validator %v", val)
+		}
+		seen[val] = true
+	}
+	if g.Params.BlockPeriod < 0 {
+		return fmt.Errorf("genesis: negative block period")
+	}
+	if g.Params.MaxBlockTxs < 0 {
+		return fmt.Errorf("genesis: negative max block txs")
+	}
+	if g.Params.MaxBlockSize < 0 {
+		return fmt.Errorf("genesis: negative max block size")
+	}
+	if g.Params.MaxBlockSize > 0 && g.Params.MaxBlockSize < 2*blockOverhead {
+		return fmt.Errorf("genesis: max block size below %d bytes", 2*blockOverhead)
+	}
+	if g.Params.MaxBlockSize > MaxBlockSizeLimit {
+		return fmt.Errorf("genesis: max block size above %d bytes", MaxBlockSizeLimit)
+	}
+	return nil
+}
+
+// ValidatorSet returns the validators of the genesis, or the authority when
+// the genesis lists no validators
+func (g Genesis) ValidatorSet() []Address {
+	if len(g.Validators) == 0 {
+		return []Address{g.Authority}
+	}
+	return g.Validators
+}
+
 func NewSigGenesis(gen Genesis, sig []byte) SigGenesis {
 	return SigGenesis{
 		Genesis: gen,
@@ -69,23 +187,29 @@ func VerifyGen(gen SigGenesis) (bool, error) {
 }
 
 func (g SigGenesis) Write(dir string) error {
-	jgen, err := json.Marshal(g)
+	err := os.MkdirAll(dir, 0700)
+
 	if err != nil {
 		return err
 	}
+	return g.WriteFile(filepath.Join(dir, genesisFile))
+}
 
-	err = os.MkdirAll(dir, 0700)
-
+// WriteFile writes the signed genesis to the file
+func (g SigGenesis) WriteFile(path string) error {
+	jgen, err := json.Marshal(g)
 	if err != nil {
 		return err
 	}
-	path := filepath.Join(dir, genesisFile)
-
 	return os.WriteFile(path, jgen, 0600)
 }
 
 func ReadGenesis(dir string) (SigGenesis, error) {
-	path := filepath.Join(dir, genesisFile)
+	return ReadGenesisFile(filepath.Join(dir, genesisFile))
+}
+
+// ReadGenesisFile reads the signed genesis from the file
+func ReadGenesisFile(path string) (SigGenesis, error) {
 	jgen, err := os.ReadFile(path)
 	if err != nil {
 		return SigGenesis{}, err
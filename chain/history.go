@@ -0,0 +1,128 @@
+package chain
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	EntryGenesis  = "genesis"
+	EntryTransfer = "transfer"
+)
+
+// HistoryEntry is a balance change of an account with the running balance
+// after the change. Credit entries increase the balance, debit entries
+// decrease it
+type HistoryEntry struct {
+	BlockNumber uint64    `json:"blockNumber"`
+	Time        time.Time `json:"time"`
+	Hash        Hash      `json:"hash"`
+	Index       int       `json:"index"`
+	Kind        string    `json:"kind"`
+	From        Address   `json:"from"`
+	To          Address   `json:"to"`
+	Value       uint64    `json:"value"`
+	Credit      bool      `json:"credit"`
+	Memo        string    `json:"memo,omitempty"`
+	Balance     uint64    `json:"balance"`
+}
+
+func (e HistoryEntry) String() string {
+	sign, party := "-", e.To
+	if e.Credit {
+		sign, party = "+", e.From
+	}
+	return fmt.Sprintf(
+		"blk %4d   %v   tx %.7s   %-8s %.7s %s%-8d   bal %8d",
+		e.BlockNumber, e.Time.Format(time.RFC3339), e.Hash, e.Kind, party,
+		sign, e.Value, e.Balance,
+	)
+}
+
+// History follows the balance changes of an account from the genesis
+// through the applied blocks
+type History struct {
+	acc     Address
+	balance uint64
+	locks   map[Hash]SigTx
+}
+
+func NewHistory(acc Address) *History {
+	return &History{acc: acc, locks: make(map[Hash]SigTx)}
+}
+
+// ApplyGenesis returns the opening entry of an account with a genesis
+// balance
+func (h *History) ApplyGenesis(gen Genesis) []HistoryEntry {
+	balance, exist := gen.Balances[h.acc]
+	if !exist {
+		return nil
+	}
+	h.balance = balance
+	entry := HistoryEntry{
+		Time: gen.Time, Kind: EntryGenesis, From: gen.Authority, To: h.acc,
+		Value: balance, Credit: true, Balance: balance,
+	}
+	return []HistoryEntry{entry}
+}
+
+// ApplyBlock returns the entries of the account in the block in the order of
+// the transactions and their transfers
+func (h *History) ApplyBlock(blk SigBlock) []HistoryEntry {
+	entries := make([]HistoryEntry, 0)
+	add := func(tx SigTx, index int, kind string, from, to Address, value uint64, credit bool) {
+		if credit {
+			h.balance += value
+		} else {
+			h.balance -= value
+		}
+		entries = append(entries, HistoryEntry{
+			BlockNumber: blk.Number, Time: blk.Time, Hash: tx.Hash(),
+			Index: index, Kind: kind, From: from, To: to, Value: value,
+			Credit: credit, Memo: string(tx.Data), Balance: h.balance,
+		})
+	}
+	for _, tx := range blk.Txs {
+		if tx.HTLC != nil {
+			h.applyHTLC(tx, add)
+			continue
+		}
+		for i, tr := range tx.Transfers() {
+			if tx.From == h.acc {
+				add(tx, i, EntryTransfer, tx.From, tr.To, tr.Value, false)
+			}
+			if tr.To == h.acc {
+				add(tx, i, EntryTransfer, tx.From, tr.To, tr.Value, true)
+			}
+		}
+	}
+	return entries
+}
+
+// applyHTLC debits the locked value from the sender on lock and credits it
+// to the recipient on claim or back to the sender on refund
+func (h *History) applyHTLC(
+	tx SigTx,
+	add func(tx SigTx, index int, kind string, from, to Address, value uint64, credit bool),
+) {
+	switch tx.HTLC.Op {
+	case HTLCLock:
+		if tx.From != h.acc && tx.To != h.acc {
+			return
+		}
+		h.locks[tx.Hash()] = tx
+		if tx.From == h.acc {
+			add(tx, 0, HTLCLock, tx.From, tx.To, tx.Value, false)
+		}
+	case HTLCClaim:
+		lock, exist := h.locks[tx.HTLC.Lock]
+		if exist && lock.To == h.acc {
+			add(tx, 0, HTLCClaim, lock.From, lock.To, lock.Value, true)
+		}
+	case HTLCRefund:
+		lock, exist := h.locks[tx.HTLC.Lock]
+		if exist && lock.From == h.acc {
+			add(tx, 0, HTLCRefund, lock.From, lock.From, lock.Value, true)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package chain
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"time"
+)
+
+// HTLC operations. A lock escrows the transaction value for the recipient
+// under a hash lock and a time lock, the recipient claims the value by
+// revealing the preimage before the time lock, and the sender refunds the
+// value once the time lock has passed
+const (
+	HTLCLock   = "lock"
+	HTLCClaim  = "claim"
+	HTLCRefund = "refund"
+)
+
+type HTLC struct {
+	Op string `json:"op"`
+	// HashLock is the SHA-256 hash of the secret preimage (lock)
+	HashLock Hash `json:"hashLock,omitzero"`
+	// TimeLock is a block height or a Unix timestamp, see TxHeightLimit (lock)
+	TimeLock uint64 `json:"timeLock,omitempty"`
+	// Lock is the hash of the lock transaction (claim, refund)
+	Lock Hash `json:"lock,omitzero"`
+	// Preimage is the revealed secret (claim)
+	Preimage []byte `json:"preimage,omitempty"`
+}
+
+type LockStatus string
+
+const (
+	LockLocked   LockStatus = "locked"
+	LockClaimed  LockStatus = "claimed"
+	LockRefunded LockStatus = "refunded"
+)
+
+type Lock struct {
+	Hash     Hash       `json:"hash"`
+	From     Address    `json:"from"`
+	To       Address    `json:"to"`
+	Value    uint64     `json:"value"`
+	HashLock Hash       `json:"hashLock"`
+	TimeLock uint64     `json:"timeLock"`
+	Status   LockStatus `json:"status"`
+	Preimage []byte     `json:"preimage,omitempty"`
+}
+
+func (l Lock) String() string {
+	str := fmt.Sprintf(
+		"lock %.7s: %.7s -> %.7s %8d   %v   hlk %.7s   tlk %d",
+		l.Hash, l.From, l.To, l.Value, l.Status, l.HashLock, l.TimeLock,
+	)
+	if len(l.Preimage) > 0 {
+		str += fmt.Sprintf("   pre %x", l.Preimage)
+	}
+	return str
+}
+
+// NewSecret generates a random preimage and its hash lock
+func NewSecret() ([]byte, Hash, error) {
+	preimage := make([]byte, 32)
+	_, err := rand.Read(preimage)
+	if err != nil {
+		return nil, Hash{}, err
+	}
+	return preimage, HashLock(preimage), nil
+}
+
+func HashLock(preimage []byte) Hash {
+	return Hash(sha256.Sum256(preimage))
+}
+
+// timeLockPassed reports whether the height or the time of the including
+// block has reached the time lock
+func timeLockPassed(timeLock, height uint64, now time.Time) bool {
+	return boundValue(timeLock, height, now) >= timeLock
+}
+
+func (s *State) applyHTLC(tx SigTx, height uint64, now time.Time) error {
+	if len(tx.Batch) > 0 {
+		return fmt.Errorf("htlc: batch transfers are not supported\n%v\n", tx)
+	}
+	switch tx.HTLC.Op {
+	case HTLCLock:
+		if len(tx.To) == 0 || tx.Value == 0 {
+			return fmt.Errorf("htlc: lock requires recipient and value\n%v\n", tx)
+		}
+		if tx.HTLC.TimeLock == 0 || tx.HTLC.HashLock == (Hash{}) {
+			return fmt.Errorf("htlc: lock requires hash lock and time lock\n%v\n", tx)
+		}
+		if timeLockPassed(tx.HTLC.TimeLock, height, now) {
+			return fmt.Errorf("htlc: time lock %d has already passed\n%v\n", tx.HTLC.TimeLock, tx)
+		}
+		if s.balances[tx.From] < tx.Value {
+			return fmt.Errorf("%w\n%v\n", ErrTxFunds, tx)
+		}
+		s.balances[tx.From] -= tx.Value
+		hash := tx.Hash()
+		s.locks[hash] = Lock{
+			Hash: hash, From: tx.From, To: tx.To, Value: tx.Value,
+			HashLock: tx.HTLC.HashLock, TimeLock: tx.HTLC.TimeLock,
+			Status: LockLocked,
+		}
+		return nil
+	case HTLCClaim, HTLCRefund:
+		if len(tx.To) > 0 || tx.Value != 0 {
+			return fmt.Errorf("htlc: %v must not set to and value\n%v\n", tx.HTLC.Op, tx)
+		}
+		lock, exist := s.locks[tx.HTLC.Lock]
+		if !exist {
+			return fmt.Errorf("htlc: lock %.7s not found\n%v\n", tx.HTLC.Lock, tx)
+		}
+		if lock.Status != LockLocked {
+			return fmt.Errorf("htlc: lock %.7s already %v\n%v\n", lock.Hash, lock.Status, tx)
+		}
+		passed := timeLockPassed(lock.TimeLock, height, now)
+		if tx.HTLC.Op == HTLCClaim {
+			if tx.From != lock.To {
+				return fmt.Errorf("htlc: only %.7s can claim lock %.7s\n%v\n", lock.To, lock.Hash, tx)
+			}
+			if passed {
+				return fmt.Errorf("htlc: time lock %d has passed\n%v\n", lock.TimeLock, tx)
+			}
+			if HashLock(tx.HTLC.Preimage) != lock.HashLock {
+				return fmt.Errorf("htlc: preimage does not match hash lock\n%v\n", tx)
+			}
+			s.balances[lock.To] += lock.Value
+			lock.Status, lock.Preimage = LockClaimed, tx.HTLC.Preimage
+		} else {
+			if tx.From != lock.From {
+				return fmt.Errorf("htlc: only %.7s can refund lock %.7s\n%v\n", lock.From, lock.Hash, tx)
+			}
+			if !passed {
+				return fmt.Errorf("htlc: time lock %d has not passed\n%v\n", lock.TimeLock, tx)
+			}
+			s.balances[lock.From] += lock.Value
+			lock.Status = LockRefunded
+		}
+		s.locks[lock.Hash] = lock
+		return nil
+	default:
+		return fmt.Errorf("htlc: unsupported operation %v\n%v\n", tx.HTLC.Op, tx)
+	}
+}
+
+// HTLC returns the hash time-locked contract created by the lock transaction
+func (s *State) HTLC(hash Hash) (Lock, bool) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	lock, exist := s.locks[hash]
+	return lock, exist
+}
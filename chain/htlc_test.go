@@ -0,0 +1,119 @@
+package chain
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHTLC(t *testing.T) {
+	preimage, hashLock, err := NewSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The lock is included at height 1 with a time lock at height 3
+	const value, timeLock = 100, 3
+	type op struct {
+		op       string
+		preimage []byte
+		sender   bool
+		height   uint64
+	}
+	cases := []struct {
+		name   string
+		ops    []op
+		err    string
+		status LockStatus
+		owner  uint64
+		auth   uint64
+	}{
+		{"locked", nil, "", LockLocked, 1_000_000 - value, 0},
+		{"claim", []op{{HTLCClaim, preimage, false, 2}},
+			"", LockClaimed, 1_000_000 - value, value},
+		{"claim wrong preimage", []op{{HTLCClaim, []byte("secret"), false, 2}},
+			"preimage does not match", LockLocked, 1_000_000 - value, 0},
+		{"claim by the sender", []op{{HTLCClaim, preimage, true, 2}},
+			"can claim", LockLocked, 1_000_000 - value, 0},
+		{"claim timeout", []op{{HTLCClaim, preimage, false, timeLock}},
+			"has passed", LockLocked, 1_000_000 - value, 0},
+		{"claim twice", []op{{HTLCClaim, preimage, false, 2}, {HTLCClaim, preimage, false, 2}},
+			"already claimed", LockClaimed, 1_000_000 - value, value},
+		{"refund", []op{{HTLCRefund, nil, true, timeLock}},
+			"", LockRefunded, 1_000_000, 0},
+		{"refund before timeout", []op{{HTLCRefund, nil, true, 2}},
+			"has not passed", LockLocked, 1_000_000 - value, 0},
+		{"refund by the recipient", []op{{HTLCRefund, nil, false, timeLock}},
+			"can refund", LockLocked, 1_000_000 - value, 0},
+		{"claim after refund", []op{{HTLCRefund, nil, true, timeLock}, {HTLCClaim, preimage, false, 2}},
+			"already refunded", LockRefunded, 1_000_000, 0},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			chain := newTestChain(t, Params{})
+			now := chain.clock.now
+			lock := NewTx(chain.clock, chain.owner.Address(), chain.auth.Address(), value, 1, nil)
+			lock.HTLC = &HTLC{Op: HTLCLock, HashLock: hashLock, TimeLock: timeLock}
+			slock := chain.sign(t, lock)
+			err := chain.state.applyTxAt(slock, 1, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, op := range c.ops {
+				acc := chain.auth
+				if op.sender {
+					acc = chain.owner
+				}
+				tx := NewTx(chain.clock, acc.Address(), "", 0, chain.state.Nonce(acc.Address())+1, nil)
+				tx.HTLC = &HTLC{Op: op.op, Lock: slock.Hash(), Preimage: op.preimage}
+				stx, serr := acc.SignTx(tx)
+				if serr != nil {
+					t.Fatal(serr)
+				}
+				err = chain.state.applyTxAt(stx, op.height, now)
+			}
+			if c.err == "" && err != nil {
+				t.Fatal(err)
+			}
+			if c.err != "" && (err == nil || !strings.Contains(err.Error(), c.err)) {
+				t.Fatalf("expected error %q, got %v", c.err, err)
+			}
+			htlc, _ := chain.state.HTLC(slock.Hash())
+			if htlc.Status != c.status {
+				t.Fatalf("expected lock %v, got %v", c.status, htlc.Status)
+			}
+			owner, _ := chain.state.Balance(chain.owner.Address())
+			auth, _ := chain.state.Balance(chain.auth.Address())
+			if owner != c.owner || auth != c.auth {
+				t.Fatalf("expected balances %d %d, got %d %d", c.owner, c.auth, owner, auth)
+			}
+		})
+	}
+}
+
+func TestHTLCLock(t *testing.T) {
+	_, hashLock, err := NewSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		name string
+		htlc HTLC
+		err  string
+	}{
+		{"time lock passed", HTLC{Op: HTLCLock, HashLock: hashLock, TimeLock: 1}, "already passed"},
+		{"no hash lock", HTLC{Op: HTLCLock, TimeLock: 3}, "requires hash lock"},
+		{"no time lock", HTLC{Op: HTLCLock, HashLock: hashLock}, "requires hash lock"},
+		{"claim with a value", HTLC{Op: HTLCClaim, Lock: hashLock}, "must not set to"},
+		{"unsupported", HTLC{Op: "swap"}, "unsupported operation"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			chain := newTestChain(t, Params{})
+			tx := NewTx(chain.clock, chain.owner.Address(), chain.auth.Address(), 100, 1, nil)
+			tx.HTLC = &c.htlc
+			err := chain.state.applyTxAt(chain.sign(t, tx), 1, chain.clock.now)
+			if err == nil || !strings.Contains(err.Error(), c.err) {
+				t.Fatalf("expected error %q, got %v", c.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package chain
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+type TxStatus uint64
+
+const (
+	TxUnknown  TxStatus = 0
+	TxPending  TxStatus = 1
+	TxIncluded TxStatus = 2
+	TxRejected TxStatus = 3
+	TxExpired  TxStatus = 4
+)
+
+func (s TxStatus) String() string {
+	switch s {
+	case TxPending:
+		return "pending"
+	case TxIncluded:
+		return "included"
+	case TxRejected:
+		return "rejected"
+	case TxExpired:
+		return "expired"
+	default:
+		return "unknown"
+	}
+}
+
+// Final reports whether no further status transitions are expected. A
+// rejected transaction is pending again only when it is sent again
+func (s TxStatus) Final() bool {
+	return s == TxIncluded || s == TxRejected || s == TxExpired
+}
+
+type Receipt struct {
+	Hash        Hash      `json:"hash"`
+	Status      TxStatus  `json:"status"`
+	Reason      string    `json:"reason,omitempty"`
+	BlockNumber uint64    `json:"blockNumber,omitempty"`
+	BlockHash   Hash      `json:"blockHash"`
+	Index       uint64    `json:"index"`
+	Time        time.Time `json:"time"`
+}
+
+func (r Receipt) String() string {
+	switch r.Status {
+	case TxIncluded:
+		return fmt.Sprintf(
+			"tx %.7s: %v   blk %4d   %.7s   idx %d",
+			r.Hash, r.Status, r.BlockNumber, r.BlockHash, r.Index,
+		)
+	case TxRejected, TxExpired:
+		return fmt.Sprintf("tx %.7s: %v   %v", r.Hash, r.Status, r.Reason)
+	default:
+		return fmt.Sprintf("tx %.7s: %v", r.Hash, r.Status)
+	}
+}
+
+// maxReceipts bounds the number of receipts kept by the node
+const maxReceipts = 100_000
+
+// Receipts tracks the latest status of the transactions seen by the node.
+// The store is shared between the confirmed state and the pending pool. A
+// clone of the state keeps its receipts apart until the clone is committed,
+// so blocks that are never applied leave no receipts. The receipts of the
+// oldest transactions are dropped beyond maxReceipts
+type Receipts struct {
+	mtx      sync.RWMutex
+	receipts map[Hash]Receipt
+	order    []Hash
+	subs     map[Hash]map[chan Receipt]struct{}
+}
+
+func NewReceipts() *Receipts {
+	return &Receipts{
+		receipts: make(map[Hash]Receipt),
+		subs:     make(map[Hash]map[chan Receipt]struct{}),
+	}
+}
+
+func (r *Receipts) Receipt(hash Hash) (Receipt, bool) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	rcp, exist := r.receipts[hash]
+	return rcp, exist
+}
+
+// Subscribe returns a channel that receives every status change of the
+// transaction and a function to cancel the subscription
+func (r *Receipts) Subscribe(hash Hash) (chan Receipt, func()) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	chRcp := make(chan Receipt, 4)
+	if r.subs[hash] == nil {
+		r.subs[hash] = make(map[chan Receipt]struct{})
+	}
+	r.subs[hash][chRcp] = struct{}{}
+	unsubscribe := func() {
+		r.mtx.Lock()
+		defer r.mtx.Unlock()
+		delete(r.subs[hash], chRcp)
+		if len(r.subs[hash]) == 0 {
+			delete(r.subs, hash)
+		}
+	}
+	return chRcp, unsubscribe
+}
+
+func (r *Receipts) set(rcp Receipt) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	prev, exist := r.receipts[rcp.Hash]
+	resent := prev.Status == TxRejected && rcp.Status == TxPending
+	if exist && prev.Status.Final() && !rcp.Status.Final() && !resent {
+		return
+	}
+	rcp.Time = time.Now()
+	r.receipts[rcp.Hash] = rcp
+	if !exist {
+		r.order = append(r.order, rcp.Hash)
+		r.prune()
+	}
+	for chRcp := range r.subs[rcp.Hash] {
+		select {
+		case chRcp <- rcp:
+		default:
+		}
+	}
+}
+
+// prune drops the oldest receipts beyond maxReceipts. The receipts of the
+// pending transactions are kept
+func (r *Receipts) prune() {
+	for range len(r.order) {
+		if len(r.receipts) <= maxReceipts {
+			return
+		}
+		hash := r.order[0]
+		r.order = r.order[1:]
+		if r.receipts[hash].Status == TxPending {
+			r.order = append(r.order, hash)
+			continue
+		}
+		delete(r.receipts, hash)
+	}
+}
+
+// commit records the receipts of a committed clone of the state
+func (r *Receipts) commit(clone *Receipts) {
+	clone.mtx.RLock()
+	defer clone.mtx.RUnlock()
+	for _, hash := range clone.order {
+		rcp, exist := clone.receipts[hash]
+		if exist {
+			r.set(rcp)
+		}
+	}
+}
+
+func (r *Receipts) pending(hash Hash) {
+	r.set(Receipt{Hash: hash, Status: TxPending})
+}
+
+func (r *Receipts) reject(hash Hash, err error) {
+	reason, _, _ := strings.Cut(err.Error(), "\n")
+	r.set(Receipt{Hash: hash, Status: TxRejected, Reason: reason})
+}
+
+func (r *Receipts) expire(hash Hash, err error) {
+	reason, _, _ := strings.Cut(err.Error(), "\n")
+	r.set(Receipt{Hash: hash, Status: TxExpired, Reason: reason})
+}
+
+func (r *Receipts) include(hash Hash, number uint64, blkHash Hash, index int) {
+	r.set(Receipt{
+		Hash: hash, Status: TxIncluded,
+		BlockNumber: number, BlockHash: blkHash, Index: uint64(index),
+	})
+}
@@ -0,0 +1,170 @@
+package chain
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestTxStatusFinal(t *testing.T) {
+	final := map[TxStatus]bool{
+		TxUnknown: false, TxPending: false, TxIncluded: true, TxRejected: true,
+		TxExpired: true,
+	}
+	for status, exp := range final {
+		if status.Final() != exp {
+			t.Errorf("expected %v final %v", status, exp)
+		}
+	}
+}
+
+func TestReceipts(t *testing.T) {
+	cases := []struct {
+		name string
+		// run applies the transactions of the pool and returns the tx of
+		// the expected receipt
+		run    func(t *testing.T, c *testChain, txs []SigTx) SigTx
+		status TxStatus
+		reason string
+	}{
+		{"pending", func(t *testing.T, c *testChain, txs []SigTx) SigTx {
+			return txs[0]
+		}, TxPending, ""},
+		{"included", func(t *testing.T, c *testChain, txs []SigTx) SigTx {
+			blk, err := c.state.Clone().CreateBlock(c.auth, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = c.state.ApplyBlockToState(blk)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return txs[1]
+		}, TxIncluded, ""},
+		{"block of a clone", func(t *testing.T, c *testChain, txs []SigTx) SigTx {
+			blk, err := c.state.Clone().CreateBlock(c.auth, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = c.state.Clone().ApplyBlock(blk)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return txs[0]
+		}, TxPending, ""},
+		{"rejected after a block", func(t *testing.T, c *testChain, txs []SigTx) SigTx {
+			// Another transaction of the same nonce is included first
+			tx := NewTx(c.clock, c.owner.Address(), c.owner.Address(), 2, 1, nil)
+			stx, err := c.owner.SignTx(tx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = c.state.ApplyBlockToState(c.block(t, []SigTx{stx}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, exist := c.state.Pending.txs[txs[0].Hash()]; exist {
+				t.Fatal("expected the rejected tx to leave the pool")
+			}
+			return txs[0]
+		}, TxRejected, "invalid nonce"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			chain := newTestChain(t, Params{})
+			txs := chain.txs(t, 2)
+			chain.pool(t, txs)
+			tx := c.run(t, chain, txs)
+			rcp, exist := chain.state.Receipt(tx.Hash())
+			if !exist || rcp.Status != c.status {
+				t.Fatalf("expected %v receipt, got %v", c.status, rcp.Status)
+			}
+			if !strings.Contains(rcp.Reason, c.reason) {
+				t.Fatalf("expected reason %q, got %q", c.reason, rcp.Reason)
+			}
+		})
+	}
+}
+
+func TestReceiptsResent(t *testing.T) {
+	rcps := NewReceipts()
+	hash := NewHash("tx")
+	rcps.include(hash, 1, Hash{}, 0)
+	rcps.pending(hash)
+	if rcp, _ := rcps.Receipt(hash); rcp.Status != TxIncluded {
+		t.Fatalf("expected an included tx to stay included, got %v", rcp.Status)
+	}
+	hash = NewHash("rejected")
+	rcps.reject(hash, fmt.Errorf("invalid nonce"))
+	rcps.pending(hash)
+	if rcp, _ := rcps.Receipt(hash); rcp.Status != TxPending {
+		t.Fatalf("expected a resent rejected tx pending, got %v", rcp.Status)
+	}
+}
+
+func TestReceiptsPrune(t *testing.T) {
+	rcps := NewReceipts()
+	pending, oldest := NewHash("pending"), NewHash("oldest")
+	rcps.pending(pending)
+	rcps.include(oldest, 1, Hash{}, 0)
+	for i := range maxReceipts - 1 {
+		rcps.include(NewHash(i), 1, Hash{}, 0)
+	}
+	if _, exist := rcps.Receipt(oldest); exist {
+		t.Fatal("expected the oldest receipt dropped")
+	}
+	if _, exist := rcps.Receipt(pending); !exist {
+		t.Fatal("expected the pending receipt kept")
+	}
+}
+
+func TestApplyBlockToStatePersists(t *testing.T) {
+	chain := newTestChain(t, Params{})
+	dir := t.TempDir()
+	err := InitBlockStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain.state.SetBlockStore(dir)
+	blk := chain.block(t, chain.txs(t, 1))
+	// The same block relayed by two peers is applied and persisted once
+	var wg sync.WaitGroup
+	errs := make(chan error, 2)
+	for range 2 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- chain.state.ApplyBlockToState(blk)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	failed := 0
+	for err := range errs {
+		if err != nil {
+			failed++
+		}
+	}
+	if failed != 1 {
+		t.Fatalf("expected one failed apply, got %d", failed)
+	}
+	blocks, closeBlocks, err := ReadBlocks(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeBlocks()
+	count := 0
+	for err, read := range blocks {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if read.Hash() != blk.Hash() {
+			t.Fatalf("expected block %v, got %v", blk.Hash(), read.Hash())
+		}
+		count++
+	}
+	if count != 1 {
+		t.Fatalf("expected 1 persisted block, got %d", count)
+	}
+}
@@ -1,125 +1,319 @@
 package chain
 
 import (
+	"errors"
 	"fmt"
+	"log/slog"
 	"maps"
 	"slices"
 	"sync"
+	"time"
 )
 
 type State struct {
 	mtx         sync.RWMutex
+	blkMtx      sync.Mutex
 	authority   Address
+	validators  []Address
+	bft         bool
+	params      Params
 	balances    map[Address]uint64
 	nonces      map[Address]uint64
 	lastBlock   SigBlock
 	genesisHash Hash
+	genesisTime time.Time
 	txs         map[Hash]SigTx
+	locks       map[Hash]Lock
+	receipts    *Receipts
+	pool        bool
+	storeDir    string
+	onDrop      func(tx SigTx, err error)
+	clock       Clock
+	log         *slog.Logger
 	Pending     *State
 }
 
 func NewState(gen *SigGenesis) *State {
+	receipts := NewReceipts()
 	return &State{
 		authority:   gen.Authority,
+		validators:  slices.Clone(gen.ValidatorSet()),
+		bft:         len(gen.Validators) > 0,
+		params:      gen.Params,
 		balances:    maps.Clone(gen.Balances),
 		nonces:      make(map[Address]uint64),
 		genesisHash: gen.Hash(),
+		genesisTime: gen.Time,
 		txs:         make(map[Hash]SigTx),
+		locks:       make(map[Hash]Lock),
+		receipts:    receipts,
+		clock:       SystemClock,
+		log:         slog.Default(),
 		Pending: &State{
 			authority:   gen.Authority,
+			params:      gen.Params,
 			balances:    maps.Clone(gen.Balances),
 			nonces:      make(map[Address]uint64),
 			genesisHash: gen.Hash(),
 			txs:         make(map[Hash]SigTx),
+			locks:       make(map[Hash]Lock),
+			receipts:    receipts,
+			pool:        true,
+			clock:       SystemClock,
 		},
 	}
 }
 
+// SetBlockStore sets the directory where the applied blocks are persisted
+func (s *State) SetBlockStore(dir string) {
+	s.storeDir = dir
+}
+
+// OnPoolDrop sets the function called with the pending transactions that are
+// rejected or expire when the pending state is rebuilt. The function is
+// called with the state locked
+func (s *State) OnPoolDrop(drop func(tx SigTx, err error)) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.onDrop = drop
+}
+
+// SetLogger sets the logger of the rejected transactions of the created
+// blocks
+func (s *State) SetLogger(log *slog.Logger) {
+	s.log = log
+}
+
+// SetClock sets the clock of the created blocks and of the validity of the
+// transactions and the blocks
+func (s *State) SetClock(clock Clock) {
+	s.clock = clock
+	s.Pending.clock = clock
+}
+
+// Clone clones the state. The receipts of the clone are committed with the
+// clone
 func (s *State) Clone() *State {
 	s.mtx.RLock()
 	defer s.mtx.RUnlock()
+	receipts := NewReceipts()
 	return &State{
 		authority:   s.authority,
+		validators:  s.validators,
+		bft:         s.bft,
+		params:      s.params,
 		balances:    maps.Clone(s.balances),
 		nonces:      maps.Clone(s.nonces),
 		lastBlock:   s.lastBlock,
 		genesisHash: s.genesisHash,
+		genesisTime: s.genesisTime,
 		txs:         maps.Clone(s.txs),
+		locks:       maps.Clone(s.locks),
+		receipts:    receipts,
+		clock:       s.clock,
+		log:         s.log,
 		Pending: &State{
-			txs: maps.Clone(s.Pending.txs),
+			txs:      maps.Clone(s.Pending.txs),
+			receipts: receipts,
 		},
 	}
 }
 
+// Apply commits a clone of the state with its receipts, and rebuilds the
+// pending state on top of it
 func (s *State) Apply(clone *State) {
 	s.mtx.Lock()
 	defer s.mtx.Unlock()
 	s.balances = clone.balances
 	s.nonces = clone.nonces
+	s.locks = clone.locks
 	s.lastBlock = clone.lastBlock
-	s.Pending.balances = maps.Clone(s.balances)
-	s.Pending.nonces = maps.Clone(s.nonces)
+	s.receipts.commit(clone.receipts)
 
 	for _, tx := range clone.lastBlock.Txs {
 		delete(s.Pending.txs, tx.Hash())
 	}
+	s.resetPending(s.clock.Now())
+}
+
+// resetPending rebuilds the pending state from the confirmed state and the
+// remaining pending transactions. The expired transactions are purged, and
+// the failing ones are rejected and leave the pool. The signatures were
+// verified when the transactions entered the pool. The caller holds the
+// state lock
+func (s *State) resetPending(now time.Time) {
+	s.Pending.balances = maps.Clone(s.balances)
+	s.Pending.nonces = maps.Clone(s.nonces)
+	s.Pending.locks = maps.Clone(s.locks)
+	s.Pending.lastBlock = s.lastBlock
+	height := s.lastBlock.Number + 1
+	for _, tx := range sortedTxs(s.Pending.txs) {
+		err := s.Pending.applyVerifiedTx(tx, height, now)
+		if err == nil {
+			continue
+		}
+		delete(s.Pending.txs, tx.Hash())
+		if errors.Is(err, ErrTxExpired) {
+			s.receipts.expire(tx.Hash(), err)
+		} else {
+			s.receipts.reject(tx.Hash(), err)
+		}
+		if s.onDrop != nil {
+			s.onDrop(tx, err)
+		}
+	}
+}
+
+// sortedTxs returns the transactions in the order of their time
+func sortedTxs(txs map[Hash]SigTx) []SigTx {
+	sorted := slices.Collect(maps.Values(txs))
+	slices.SortFunc(sorted, func(a, b SigTx) int {
+		if a.Time.Before(b.Time) {
+			return -1
+		}
+		if b.Time.Before(a.Time) {
+			return 1
+		}
+
+		return 0
+	})
+	return sorted
 }
 
+// ApplyTx applies the transaction as if it were included in the next block
 func (s *State) ApplyTx(tx SigTx) error {
-	s.mtx.Lock()
-	defer s.mtx.Unlock()
+	return s.applyTxAt(tx, s.LastBlock().Number+1, s.clock.Now())
+}
 
+func (s *State) applyTxAt(tx SigTx, height uint64, now time.Time) error {
 	valid, err := VerifyTx(tx)
 	if err != nil {
 		return err
 	}
 	if !valid {
-		return fmt.Errorf("tx: invalid transaction signature\n%v\n", tx)
+		return fmt.Errorf("%w\n%v\n", ErrTxSignature, tx)
+	}
+	err = s.applyVerifiedTx(tx, height, now)
+	if err == nil && s.pool {
+		s.receipts.pending(tx.Hash())
+	}
+	return err
+}
+
+// applyVerifiedTx applies a transaction with a verified signature
+func (s *State) applyVerifiedTx(tx SigTx, height uint64, now time.Time) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	err := tx.ValidAt(height, now)
+	// The pending pool holds transactions until their window opens
+	if err != nil && !(s.pool && errors.Is(err, ErrTxNotYetValid)) {
+		return fmt.Errorf("%w\n%v\n", err, tx)
+	}
+
+	if len(tx.Data) > TxDataMaxLen {
+		return fmt.Errorf("tx: data length %d exceeds %d bytes\n%v\n", len(tx.Data), TxDataMaxLen, tx)
+	}
+
+	// The blocks of a chain without a max block size may hold larger
+	// transactions than the pool accepts
+	size := tx.Size()
+	if (s.pool || s.params.MaxBlockSize > 0) && size > s.params.SizeLimit()-blockOverhead {
+		return fmt.Errorf("%w: %d bytes\n%v\n", ErrTxSize, size, tx)
 	}
 
 	if tx.Nonce != s.nonces[tx.From]+1 {
-		return fmt.Errorf("tx: invalid nonce %d, expected %d\n%v\n", tx.Nonce, s.nonces[tx.From]+1, tx)
+		return fmt.Errorf("%w %d, expected %d\n%v\n", ErrTxNonce, tx.Nonce, s.nonces[tx.From]+1, tx)
 	}
 
-	if s.balances[tx.From] < tx.Value {
-		return fmt.Errorf("tx: insufficient account funds\n%v\n", tx)
+	if tx.HTLC != nil {
+		err = s.applyHTLC(tx, height, now)
+	} else {
+		err = s.applyTransfers(tx)
+	}
+	if err != nil {
+		return err
 	}
 
-	s.balances[tx.From] -= tx.Value
-	s.balances[tx.To] += tx.Value
 	s.nonces[tx.From]++
 	s.txs[tx.Hash()] = tx
 	return nil
 }
 
-func (s *State) CreateBlock(authority Account) (SigBlock, error) {
-	pndTxs := make([]SigTx, 0, len(s.Pending.txs))
-	for _, tx := range s.Pending.txs {
-		pndTxs = append(pndTxs, tx)
+func (s *State) applyTransfers(tx SigTx) error {
+	if len(tx.Batch) > 0 && (len(tx.To) > 0 || tx.Value != 0) {
+		return fmt.Errorf("tx: batch transaction must not set to and value\n%v\n", tx)
 	}
-	slices.SortFunc(pndTxs, func(a, b SigTx) int {
-		if a.Time.Before(b.Time) {
-			return -1
-		}
-		if b.Time.Before(a.Time) {
-			return 1
-		}
 
-		return 0
-	})
+	if len(tx.Batch) > TxBatchMaxLen {
+		return fmt.Errorf("tx: batch of %d transfers exceeds %d\n%v\n", len(tx.Batch), TxBatchMaxLen, tx)
+	}
 
-	txs := make([]SigTx, 0, len(pndTxs))
+	total, err := tx.Total()
+	if err != nil {
+		return fmt.Errorf("%w\n%v\n", err, tx)
+	}
+
+	// All transfers of a batch are checked before any balance changes
+	if s.balances[tx.From] < total {
+		return fmt.Errorf("%w\n%v\n", ErrTxFunds, tx)
+	}
+
+	s.balances[tx.From] -= total
+	for _, tr := range tx.Transfers() {
+		s.balances[tr.To] += tr.Value
+	}
+	return nil
+}
+
+// CreateBlock creates a block of the pending transactions. The failing
+// transactions are left out, and rejected once the block is applied. An empty
+// block is only created when allowed, as a heartbeat of the chain
+func (s *State) CreateBlock(authority Account, empty bool) (SigBlock, error) {
+	pndTxs := sortedTxs(s.Pending.txs)
+	txs := make([]SigTx, 0, min(len(pndTxs), s.params.TxLimit()))
+	number, now := s.lastBlock.Number+1, s.clock.Now()
+	if !now.After(s.parentTime()) {
+		return SigBlock{}, fmt.Errorf(
+			"%w: clock %v, parent %v", ErrBlockTime,
+			now.Format(time.RFC3339Nano), s.parentTime().Format(time.RFC3339Nano),
+		)
+	}
+	size := blockOverhead
+
+	// The later transactions of a sender wait for a transaction whose window
+	// has not opened yet
+	waiting := make(map[Address]bool)
 
 	for _, tx := range pndTxs {
-		err := s.ApplyTx(tx)
+		if waiting[tx.From] {
+			continue
+		}
+		// The transactions beyond the block limits stay pending
+		txSize := tx.Size() + 1
+		if len(txs) == s.params.TxLimit() || size+txSize > s.params.SizeLimit() {
+			s.log.Debug(
+				"Block full", "block", number, "txs", len(txs), "size", size,
+				"pending", len(pndTxs)-len(txs),
+			)
+			break
+		}
+		err := s.applyTxAt(tx, number, now)
+		if errors.Is(err, ErrTxNotYetValid) {
+			waiting[tx.From] = true
+			continue
+		}
 		if err != nil {
-			fmt.Printf("tx error : rejected : %v\n", err)
+			s.log.Warn(
+				"Tx skipped", "block", number, "tx", tx.Hash().String(), "err", err,
+			)
 			continue
 		}
 		txs = append(txs, tx)
+		size += txSize
 	}
 
-	if len(txs) == 0 {
+	if len(txs) == 0 && !empty {
 		return SigBlock{}, fmt.Errorf("no transactions to create a block")
 	}
 
@@ -131,25 +325,97 @@ func (s *State) CreateBlock(authority Account) (SigBlock, error) {
 		parent = s.lastBlock.Hash()
 	}
 
-	blk, err := NewBlock(s.lastBlock.Number+1, parent, txs)
+	blk, err := NewBlock(s.clock, number, parent, txs)
 
 	if err != nil {
 		return SigBlock{}, err
 	}
+	// Validity windows were checked against this time
+	blk.Time = now
 
 	return authority.SignBlock(blk)
 }
 
+// ApplyBlock verifies that the block is final, then applies the block
 func (s *State) ApplyBlock(blk SigBlock) error {
-	valid, err := VerifyBlock(blk, s.authority)
+	err := s.nextBlock(blk)
 	if err != nil {
 		return err
 	}
-	if !valid {
-		return fmt.Errorf("block: invalid block signature\n%v\n", blk)
+	err = s.VerifyBlock(blk)
+	if err != nil {
+		return err
 	}
+	return s.applyBlock(blk)
+}
+
+// ApplyProposal applies a block proposed by a validator before the
+// validators commit the block. The transactions of the proposal are not
+// reported as included until the block is committed
+func (s *State) ApplyProposal(blk SigBlock) error {
+	err := s.nextBlock(blk)
+	if err != nil {
+		return err
+	}
+	err = s.verifyProposer(blk)
+	if err != nil {
+		return err
+	}
+	s.receipts = NewReceipts()
+	return s.applyBlock(blk)
+}
+
+// VerifyBlock verifies the authority signature of the block or, on a chain of
+// validators, the commit of a quorum of the validators
+func (s *State) VerifyBlock(blk SigBlock) error {
+	if !s.bft {
+		valid, err := VerifyBlock(blk, s.authority)
+		if err != nil {
+			return err
+		}
+		if !valid {
+			return fmt.Errorf("block: invalid block signature\n%v\n", blk)
+		}
+		return nil
+	}
+	err := s.verifyProposer(blk)
+	if err != nil {
+		return err
+	}
+	err = VerifyCommit(blk, s.validators)
+	if err != nil {
+		return fmt.Errorf("%w\n%v\n", err, blk)
+	}
+	return nil
+}
+
+func (s *State) verifyProposer(blk SigBlock) error {
+	acc, err := signer(blk.Block.Hash(), blk.Sig)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(s.validators, acc) {
+		return fmt.Errorf("block: proposer %v is not a validator\n%v\n", acc, blk)
+	}
+	return nil
+}
+
+// nextBlock checks the number of the block before the costly signatures
+func (s *State) nextBlock(blk SigBlock) error {
 	if blk.Number != s.lastBlock.Number+1 {
-		return fmt.Errorf("block: invalid block number %d, expected %d\n%v\n", blk.Number, s.lastBlock.Number+1, blk)
+		return fmt.Errorf("%w %d, expected %d\n%v\n", ErrBlockNumber, blk.Number, s.lastBlock.Number+1, blk)
+	}
+	return nil
+}
+
+func (s *State) applyBlock(blk SigBlock) error {
+	// The default limits only apply to the created blocks, so that the blocks
+	// of chains created before the limits still apply
+	if s.params.MaxBlockTxs > 0 && len(blk.Txs) > s.params.MaxBlockTxs {
+		return fmt.Errorf("%w: %d txs, limit %d\n%v\n", ErrBlockTxs, len(blk.Txs), s.params.MaxBlockTxs, blk)
+	}
+	if size := blk.Size(); s.params.MaxBlockSize > 0 && size > s.params.MaxBlockSize {
+		return fmt.Errorf("%w: %d bytes, limit %d\n%v\n", ErrBlockSize, size, s.params.MaxBlockSize, blk)
 	}
 
 	var parent Hash
@@ -164,33 +430,73 @@ func (s *State) ApplyBlock(blk SigBlock) error {
 		return fmt.Errorf("block: invalid parent hash %s, expected %s\n%v\n", blk.Parent, parent, blk)
 	}
 
-	merkleTree, err := MerkleHash(blk.Txs, TxHash, TxPairHash)
+	if !blk.Time.After(s.parentTime()) {
+		return fmt.Errorf(
+			"%w: %v, parent %v\n%v\n", ErrBlockTime,
+			blk.Time.Format(time.RFC3339Nano), s.parentTime().Format(time.RFC3339Nano), blk,
+		)
+	}
+	if limit := s.clock.Now().Add(MaxBlockTimeDrift); blk.Time.After(limit) {
+		return fmt.Errorf(
+			"%w: %v, local clock allows up to %v\n%v\n", ErrBlockFuture,
+			blk.Time.Format(time.RFC3339Nano), limit.Format(time.RFC3339Nano), blk,
+		)
+	}
+
+	_, merkleTreeRoot, err := blockMerkle(blk.Txs)
 
 	if err != nil {
 		return err
 	}
 
-	merkleTreeRoot := merkleTree[0]
-
 	if merkleTreeRoot != blk.MerkleRoot {
 		return fmt.Errorf("block: invalid merkle root %s, expected %s\n%v\n", blk.MerkleRoot, merkleTreeRoot, blk)
 	}
 
 	for _, tx := range blk.Txs {
-		if err := s.ApplyTx(tx); err != nil {
+		if err := s.applyTxAt(tx, blk.Number, blk.Time); err != nil {
 			return err
 		}
 	}
 
 	s.lastBlock = blk
+	blkHash := blk.Hash()
+	for i, tx := range blk.Txs {
+		s.receipts.include(tx.Hash(), blk.Number, blkHash, i)
+	}
 	return nil
 
 }
 
+// parentTime returns the time of the last block, or of the genesis before
+// the first block
+func (s *State) parentTime() time.Time {
+	if s.lastBlock.Number == 0 {
+		return s.genesisTime
+	}
+	return s.lastBlock.Time
+}
+
 func (s *State) Authority() Address {
 	return s.authority
 }
 
+// Validators returns the validators of the genesis
+func (s *State) Validators() []Address {
+	return slices.Clone(s.validators)
+}
+
+// BFT reports whether the validators of the genesis commit the blocks
+// instead of the authority
+func (s *State) BFT() bool {
+	return s.bft
+}
+
+// Params returns the chain parameters of the genesis
+func (s *State) Params() Params {
+	return s.params
+}
+
 func (s *State) LastBlock() SigBlock {
 	s.mtx.RLock()
 	defer s.mtx.RUnlock()
@@ -212,7 +518,78 @@ func (s *State) Nonce(acc Address) uint64 {
 	return s.nonces[acc]
 }
 
-// ApplyBlockToState applies a block to the state
+// Tx returns the transaction applied to the state since the last block
+func (s *State) Tx(hash Hash) (SigTx, bool) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	tx, exist := s.txs[hash]
+	return tx, exist
+}
+
+// PendingSince returns when the longest waiting transaction of the pending
+// pool entered the pool. The time set by the client is not trusted, only the
+// receipts of the pending transactions count
+func (s *State) PendingSince() (time.Time, bool) {
+	s.Pending.mtx.RLock()
+	defer s.Pending.mtx.RUnlock()
+	var since time.Time
+	for hash := range s.Pending.txs {
+		rcp, exist := s.receipts.Receipt(hash)
+		if !exist || rcp.Status != TxPending {
+			continue
+		}
+		if since.IsZero() || rcp.Time.Before(since) {
+			since = rcp.Time
+		}
+	}
+	return since, !since.IsZero()
+}
+
+// TxCount returns the number of transactions applied to the state since the
+// last block
+func (s *State) TxCount() int {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	return len(s.txs)
+}
+
+// Receipt returns the latest receipt of the given transaction
+func (s *State) Receipt(hash Hash) (Receipt, bool) {
+	return s.receipts.Receipt(hash)
+}
+
+// SubscribeReceipt notifies about status changes of the given transaction
+func (s *State) SubscribeReceipt(hash Hash) (chan Receipt, func()) {
+	return s.receipts.Subscribe(hash)
+}
+
+// ApplyBlockToState applies a block to a clone of the state and, if the block
+// is valid, persists the block, then commits the clone and prunes the
+// pending pool. Blocks received concurrently are applied and persisted in
+// order
 func (s *State) ApplyBlockToState(blk SigBlock) error {
-	return s.ApplyBlock(blk)
+	s.blkMtx.Lock()
+	defer s.blkMtx.Unlock()
+	clone := s.Clone()
+	err := clone.ApplyBlock(blk)
+	if err != nil {
+		return err
+	}
+	if len(s.storeDir) > 0 {
+		err = blk.Write(s.storeDir)
+		if err != nil {
+			return err
+		}
+	}
+	s.Apply(clone)
+	return nil
+}
+
+// PurgeExpired drops pending transactions whose validity window has passed
+// and marks their receipts as expired. The pending state is rebuilt without
+// them, so the later transactions of their senders are rejected
+func (s *State) PurgeExpired(now time.Time) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.resetPending(now)
 }
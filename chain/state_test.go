@@ -0,0 +1,372 @@
+package chain
+
+import (
+	"encoding/hex"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testTime is the time of the clock of a test chain, a minute after its
+// genesis
+var testTime = time.Date(2026, 1, 1, 0, 1, 0, 0, time.UTC)
+
+type fixedClock struct {
+	now time.Time
+}
+
+func (c *fixedClock) Now() time.Time {
+	return c.now
+}
+
+// testChain is a chain of an authority with a funded owner account and a
+// fixed clock
+type testChain struct {
+	state *State
+	auth  Account
+	owner Account
+	clock *fixedClock
+}
+
+func newTestChain(t *testing.T, params Params) *testChain {
+	t.Helper()
+	auth, err := NewAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+	owner, err := NewAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+	gen := NewGenesis("test", auth.Address(), owner.Address(), 1_000_000)
+	gen.Params = params
+	gen.Time = testTime.Add(-time.Minute)
+	clock := &fixedClock{now: testTime}
+	state := NewState(&SigGenesis{Genesis: *gen})
+	state.SetClock(clock)
+	return &testChain{state: state, auth: auth, owner: owner, clock: clock}
+}
+
+// txs signs n transfers of the owner following the nonce of the state. The
+// clock advances a millisecond per transaction to keep the pool in order
+func (c *testChain) txs(t *testing.T, n int) []SigTx {
+	t.Helper()
+	nonce := c.state.Nonce(c.owner.Address())
+	txs := make([]SigTx, n)
+	for i := range txs {
+		c.clock.now = c.clock.now.Add(time.Millisecond)
+		tx := NewTx(c.clock, c.owner.Address(), c.auth.Address(), 1, nonce+uint64(i)+1, nil)
+		txs[i] = c.sign(t, tx)
+	}
+	return txs
+}
+
+// sign signs a transaction of the owner
+func (c *testChain) sign(t *testing.T, tx Tx) SigTx {
+	t.Helper()
+	stx, err := c.owner.SignTx(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return stx
+}
+
+// block signs a block of the authority on top of the state
+func (c *testChain) block(t *testing.T, txs []SigTx) SigBlock {
+	t.Helper()
+	return c.blockAt(t, c.clock.now, txs)
+}
+
+// blockAt signs a block of the authority on top of the state at the given
+// time
+func (c *testChain) blockAt(t *testing.T, at time.Time, txs []SigTx) SigBlock {
+	t.Helper()
+	parent := c.state.genesisHash
+	if last := c.state.LastBlock(); last.Number > 0 {
+		parent = last.Hash()
+	}
+	blk, err := NewBlock(&fixedClock{now: at}, c.state.LastBlock().Number+1, parent, txs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sblk, err := c.auth.SignBlock(blk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sblk
+}
+
+// pool applies the transactions to the pending pool
+func (c *testChain) pool(t *testing.T, txs []SigTx) {
+	t.Helper()
+	for _, tx := range txs {
+		err := c.state.Pending.ApplyTx(tx)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestTxValidAt(t *testing.T) {
+	now := time.Unix(TxHeightLimit+1000, 0)
+	cases := []struct {
+		name                  string
+		notBefore, validUntil uint64
+		height                uint64
+		err                   error
+	}{
+		{"no window", 0, 0, 1, nil},
+		{"height window open", 2, 4, 3, nil},
+		{"height window bounds", 3, 3, 3, nil},
+		{"height not yet valid", 4, 0, 3, ErrTxNotYetValid},
+		{"height expired", 0, 2, 3, ErrTxExpired},
+		{"time window open", TxHeightLimit + 999, TxHeightLimit + 1001, 1, nil},
+		{"time not yet valid", TxHeightLimit + 1001, 0, 1, ErrTxNotYetValid},
+		{"time expired", 0, TxHeightLimit + 999, 1, ErrTxExpired},
+		{"height and time", 1, TxHeightLimit + 1000, 1, nil},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			tx := Tx{NotBefore: c.notBefore, ValidUntil: c.validUntil}
+			err := tx.ValidAt(c.height, now)
+			if !errors.Is(err, c.err) || (c.err == nil && err != nil) {
+				t.Fatalf("expected error %v, got %v", c.err, err)
+			}
+		})
+	}
+}
+
+func TestPendingValidityWindows(t *testing.T) {
+	now := testTime
+	// The first transaction of the owner has a window, the second follows it
+	cases := []struct {
+		name       string
+		window     func(tx *Tx)
+		run        func(t *testing.T, c *testChain)
+		status     [2]TxStatus
+		pending    int
+		nonce      uint64
+		blockTxs   int
+		blockError bool
+		dropped    int
+	}{
+		{"open", func(tx *Tx) {}, nil,
+			[2]TxStatus{TxPending, TxPending}, 2, 2, 2, false, 0},
+		{"not yet valid waits with its sender", func(tx *Tx) { tx.NotBefore = 5 }, nil,
+			[2]TxStatus{TxPending, TxPending}, 2, 2, 0, true, 0},
+		{"expired purged with its sender", func(tx *Tx) {
+			tx.ValidUntil = uint64(now.Add(time.Minute).Unix())
+		}, func(t *testing.T, c *testChain) {
+			c.state.PurgeExpired(now.Add(2 * time.Minute))
+		}, [2]TxStatus{TxExpired, TxRejected}, 0, 0, 0, true, 2},
+		{"expired after a block", func(tx *Tx) { tx.ValidUntil = 1 },
+			func(t *testing.T, c *testChain) {
+				// A block of the authority moves the chain past the window
+				tx := NewTx(c.clock, c.auth.Address(), c.owner.Address(), 0, 1, nil)
+				stx, err := c.auth.SignTx(tx)
+				if err != nil {
+					t.Fatal(err)
+				}
+				err = c.state.ApplyBlockToState(c.block(t, []SigTx{stx}))
+				if err != nil {
+					t.Fatal(err)
+				}
+			}, [2]TxStatus{TxExpired, TxRejected}, 0, 0, 0, true, 2},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			chain := newTestChain(t, Params{})
+			dropped := 0
+			chain.state.OnPoolDrop(func(tx SigTx, err error) { dropped++ })
+			first := NewTx(chain.clock, chain.owner.Address(), chain.auth.Address(), 1, 1, nil)
+			c.window(&first)
+			second := NewTx(chain.clock, chain.owner.Address(), chain.auth.Address(), 1, 2, nil)
+			second.Time = first.Time.Add(time.Millisecond)
+			txs := []SigTx{chain.sign(t, first), chain.sign(t, second)}
+			chain.pool(t, txs)
+			if c.run != nil {
+				c.run(t, chain)
+			}
+			for i, tx := range txs {
+				rcp, _ := chain.state.Receipt(tx.Hash())
+				if rcp.Status != c.status[i] {
+					t.Fatalf("expected tx %d %v, got %v", i, c.status[i], rcp.Status)
+				}
+			}
+			if len(chain.state.Pending.txs) != c.pending {
+				t.Fatalf("expected %d pending txs, got %d", c.pending, len(chain.state.Pending.txs))
+			}
+			nonce := chain.state.Pending.Nonce(chain.owner.Address())
+			if nonce != c.nonce {
+				t.Fatalf("expected pending nonce %d, got %d", c.nonce, nonce)
+			}
+			if dropped != c.dropped {
+				t.Fatalf("expected %d dropped txs, got %d", c.dropped, dropped)
+			}
+			blk, err := chain.state.Clone().CreateBlock(chain.auth, false)
+			if (err != nil) != c.blockError {
+				t.Fatalf("expected block error %v, got %v", c.blockError, err)
+			}
+			if err == nil && len(blk.Txs) != c.blockTxs {
+				t.Fatalf("expected %d block txs, got %d", c.blockTxs, len(blk.Txs))
+			}
+			// Creating a block leaves the receipts of the pool untouched
+			for i, tx := range txs {
+				rcp, _ := chain.state.Receipt(tx.Hash())
+				if rcp.Status != c.status[i] {
+					t.Fatalf("expected tx %d still %v, got %v", i, c.status[i], rcp.Status)
+				}
+			}
+		})
+	}
+}
+
+func TestBatchTx(t *testing.T) {
+	bob, carol := NewHash("bob").Bytes(), NewHash("carol").Bytes()
+	to := func(b []byte) Address { return Address(hex.EncodeToString(b)) }
+	many := make([]Transfer, TxBatchMaxLen+1)
+	for i := range many {
+		many[i] = Transfer{To: to(bob), Value: 1}
+	}
+	cases := []struct {
+		name  string
+		tx    func(tx *Tx)
+		err   string
+		bob   uint64
+		carol uint64
+	}{
+		{"batch", func(tx *Tx) {
+			tx.Batch = []Transfer{{To: to(bob), Value: 10}, {To: to(carol), Value: 20}}
+		}, "", 10, 20},
+		{"batch to one recipient", func(tx *Tx) {
+			tx.Batch = []Transfer{{To: to(bob), Value: 10}, {To: to(bob), Value: 20}}
+		}, "", 30, 0},
+		{"insufficient funds", func(tx *Tx) {
+			tx.Batch = []Transfer{{To: to(bob), Value: 10}, {To: to(carol), Value: 1_000_000}}
+		}, "insufficient account funds", 0, 0},
+		{"overflow", func(tx *Tx) {
+			tx.Batch = []Transfer{{To: to(bob), Value: math.MaxUint64}, {To: to(carol), Value: 1}}
+		}, "batch total overflows", 0, 0},
+		{"too many transfers", func(tx *Tx) { tx.Batch = many }, "exceeds", 0, 0},
+		{"batch with a recipient", func(tx *Tx) {
+			tx.To, tx.Value = to(carol), 1
+			tx.Batch = []Transfer{{To: to(bob), Value: 10}}
+		}, "must not set to and value", 0, 0},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			chain := newTestChain(t, Params{})
+			tx := NewTx(chain.clock, chain.owner.Address(), "", 0, 1, nil)
+			c.tx(&tx)
+			err := chain.state.Pending.ApplyTx(chain.sign(t, tx))
+			if c.err == "" && err != nil {
+				t.Fatal(err)
+			}
+			if c.err != "" && (err == nil || !strings.Contains(err.Error(), c.err)) {
+				t.Fatalf("expected error %q, got %v", c.err, err)
+			}
+			// A failing batch leaves every balance unchanged
+			owner, _ := chain.state.Pending.Balance(chain.owner.Address())
+			if owner != 1_000_000-c.bob-c.carol {
+				t.Fatalf("expected owner balance %d, got %d", 1_000_000-c.bob-c.carol, owner)
+			}
+			for acc, exp := range map[Address]uint64{to(bob): c.bob, to(carol): c.carol} {
+				bal, _ := chain.state.Pending.Balance(acc)
+				if bal != exp {
+					t.Fatalf("expected %.7s balance %d, got %d", acc, exp, bal)
+				}
+			}
+		})
+	}
+}
+
+func TestCreateBlockLeavesExcessTxsPending(t *testing.T) {
+	size := newTestChain(t, Params{}).txs(t, 1)[0].Size() + 1
+	cases := []struct {
+		name   string
+		params Params
+	}{
+		{"tx limit", Params{MaxBlockTxs: 2}},
+		{"size limit", Params{MaxBlockSize: blockOverhead + 2*size + size/2}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			chain := newTestChain(t, c.params)
+			txs := chain.txs(t, 3)
+			chain.pool(t, txs)
+			blk, err := chain.state.Clone().CreateBlock(chain.auth, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(blk.Txs) != 2 {
+				t.Fatalf("expected 2 txs in the block, got %d", len(blk.Txs))
+			}
+			err = chain.state.ApplyBlockToState(blk)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, pending := chain.state.Pending.Tx(txs[2].Hash())
+			if chain.state.Pending.TxCount() != 1 || !pending {
+				t.Fatalf("expected the last tx pending, got %d pending txs", chain.state.Pending.TxCount())
+			}
+			rcp, _ := chain.state.Receipt(txs[2].Hash())
+			if rcp.Status != TxPending {
+				t.Fatalf("expected a pending receipt, got %v", rcp.Status)
+			}
+		})
+	}
+}
+
+func TestApplyBlockLimits(t *testing.T) {
+	probe := newTestChain(t, Params{})
+	size := probe.block(t, probe.txs(t, 2)).Size()
+	cases := []struct {
+		name   string
+		params Params
+		txs    int
+		err    error
+	}{
+		{"at tx limit", Params{MaxBlockTxs: 2}, 2, nil},
+		{"over tx limit", Params{MaxBlockTxs: 2}, 3, ErrBlockTxs},
+		{"at size limit", Params{MaxBlockSize: size}, 2, nil},
+		{"over size limit", Params{MaxBlockSize: size}, 3, ErrBlockSize},
+		{"default limits", Params{}, DefaultMaxBlockTxs + 1, nil},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if testing.Short() && c.txs > DefaultMaxBlockTxs {
+				t.Skip("signs and verifies more than the default max block txs")
+			}
+			chain := newTestChain(t, c.params)
+			blk := chain.block(t, chain.txs(t, c.txs))
+			err := chain.state.ApplyBlockToState(blk)
+			if c.err == nil && err != nil {
+				t.Fatal(err)
+			}
+			if c.err != nil && !errors.Is(err, c.err) {
+				t.Fatalf("expected %v, got %v", c.err, err)
+			}
+		})
+	}
+}
+
+func TestPendingSince(t *testing.T) {
+	chain := newTestChain(t, Params{})
+	txs := chain.txs(t, 2)
+	before := time.Now()
+	chain.pool(t, txs)
+	// The client-set tx time predates the pool entry
+	since, exist := chain.state.PendingSince()
+	if !exist || since.Before(before) {
+		t.Fatalf("expected a pool entry after %v, got %v", before, since)
+	}
+	for _, tx := range txs {
+		chain.state.receipts.reject(tx.Hash(), ErrTxNonce)
+	}
+	since, exist = chain.state.PendingSince()
+	if exist {
+		t.Fatalf("expected no pending receipts, got %v", since)
+	}
+}
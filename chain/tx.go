@@ -3,6 +3,7 @@ package chain
 import (
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -12,12 +13,48 @@ import (
 
 type Hash [32]byte
 
+// TxDataMaxLen limits the size of the optional memo or payload carried by a
+// transaction
+const TxDataMaxLen = 256
+
+// TxBatchMaxLen limits the number of transfers in a batch transaction
+const TxBatchMaxLen = 1000
+
+// TxHeightLimit separates validity bounds given as a block height (below the
+// limit) from bounds given as a Unix timestamp in seconds (at or above)
+const TxHeightLimit = 500_000_000
+
+var (
+	ErrTxNotYetValid = errors.New("tx: not yet valid")
+	ErrTxExpired     = errors.New("tx: expired")
+	ErrTxSignature   = errors.New("tx: invalid transaction signature")
+	ErrTxNonce       = errors.New("tx: invalid nonce")
+	ErrTxFunds       = errors.New("tx: insufficient account funds")
+	ErrTxSize        = errors.New("tx: size exceeds the block size limit")
+	ErrBlockNumber   = errors.New("block: invalid block number")
+	ErrBlockTxs      = errors.New("block: tx count exceeds limit")
+	ErrBlockSize     = errors.New("block: size exceeds limit")
+	ErrBlockTime     = errors.New("block: time not after the parent time")
+	ErrBlockFuture   = errors.New("block: time too far in the future")
+	ErrBlockCommit   = errors.New("block: invalid commit")
+)
+
+type Transfer struct {
+	To    Address `json:"to"`
+	Value uint64  `json:"value"`
+}
+
 type Tx struct {
-	From  Address   `json:"from"`
-	To    Address   `json:"to"`
-	Value uint64    `json:"value"`
-	Nonce uint64    `json:"nonce"`
-	Time  time.Time `json:"time"`
+	From       Address    `json:"from"`
+	To         Address    `json:"to"`
+	Value      uint64     `json:"value"`
+	Nonce      uint64     `json:"nonce"`
+	Batch      []Transfer `json:"batch,omitempty"`
+	HTLC       *HTLC      `json:"htlc,omitempty"`
+	Data       []byte     `json:"data,omitempty"`
+	NotBefore  uint64     `json:"notBefore,omitempty"`
+	ValidUntil uint64     `json:"validUntil,omitempty"`
+	Time       time.Time  `json:"time"`
 }
 
 type SigTx struct {
@@ -58,13 +95,26 @@ func DecodeHash(str string) (Hash, error) {
 	return hash, err
 }
 
-func NewTx(from, to Address, value, nonce uint64) Tx {
+func NewTx(clock Clock, from, to Address, value, nonce uint64, data []byte) Tx {
 	return Tx{
 		From:  from,
 		To:    to,
 		Value: value,
 		Nonce: nonce,
-		Time:  time.Now(),
+		Data:  data,
+		Time:  clock.Now(),
+	}
+}
+
+func NewBatchTx(
+	clock Clock, from Address, batch []Transfer, nonce uint64, data []byte,
+) Tx {
+	return Tx{
+		From:  from,
+		Nonce: nonce,
+		Batch: batch,
+		Data:  data,
+		Time:  clock.Now(),
 	}
 }
 
@@ -72,6 +122,46 @@ func (t Tx) Hash() Hash {
 	return NewHash(t)
 }
 
+// Transfers returns the transfers of a batch transaction or the single
+// transfer of a regular transaction
+func (t Tx) Transfers() []Transfer {
+	if len(t.Batch) > 0 {
+		return t.Batch
+	}
+	return []Transfer{{To: t.To, Value: t.Value}}
+}
+
+// Total returns the sum of all transferred values
+func (t Tx) Total() (uint64, error) {
+	var total uint64
+	for _, tr := range t.Transfers() {
+		if total+tr.Value < total {
+			return 0, fmt.Errorf("tx: batch total overflows")
+		}
+		total += tr.Value
+	}
+	return total, nil
+}
+
+func boundValue(bound, height uint64, now time.Time) uint64 {
+	if bound < TxHeightLimit {
+		return height
+	}
+	return uint64(now.Unix())
+}
+
+// ValidAt checks the transaction validity window against the height and the
+// time of the block that would include the transaction
+func (t Tx) ValidAt(height uint64, now time.Time) error {
+	if t.NotBefore != 0 && boundValue(t.NotBefore, height, now) < t.NotBefore {
+		return fmt.Errorf("%w before %d", ErrTxNotYetValid, t.NotBefore)
+	}
+	if t.ValidUntil != 0 && boundValue(t.ValidUntil, height, now) > t.ValidUntil {
+		return fmt.Errorf("%w after %d", ErrTxExpired, t.ValidUntil)
+	}
+	return nil
+}
+
 func NewSigTx(tx Tx, sig []byte) SigTx {
 	return SigTx{
 		Tx:  tx,
@@ -83,14 +173,40 @@ func (t SigTx) Hash() Hash {
 	return NewHash(t)
 }
 
+// Size returns the encoded size of the transaction in bytes
+func (t SigTx) Size() int {
+	jtx, _ := json.Marshal(t)
+	return len(jtx)
+}
+
 func TxHash(tx SigTx) Hash {
 	return NewHash(tx)
 }
 
 func (t SigTx) String() string {
-	return fmt.Sprintf(
+	str := fmt.Sprintf(
 		"tx %.7s: %.7s -> %.7s %8d %8d", t.Hash(), t.From, t.To, t.Value, t.Nonce,
 	)
+	if len(t.Batch) > 0 {
+		total, _ := t.Total()
+		str = fmt.Sprintf(
+			"tx %.7s: %.7s -> batch %8d %8d   %d transfers",
+			t.Hash(), t.From, total, t.Nonce, len(t.Batch),
+		)
+	}
+	if t.HTLC != nil {
+		str += fmt.Sprintf("   htlc %v", t.HTLC.Op)
+		if t.HTLC.Op != HTLCLock {
+			str += fmt.Sprintf(" %.7s", t.HTLC.Lock)
+		}
+	}
+	if t.NotBefore != 0 || t.ValidUntil != 0 {
+		str += fmt.Sprintf("   valid %d..%d", t.NotBefore, t.ValidUntil)
+	}
+	if len(t.Data) > 0 {
+		str += fmt.Sprintf("   memo %q", t.Data)
+	}
+	return str
 }
 
 func TxPairHash(l, r Hash) Hash {
@@ -114,7 +230,7 @@ func (a Account) SignTx(tx Tx) (SigTx, error) {
 }
 
 func VerifyTx(tx SigTx) (bool, error) {
-	hash := tx.Hash().Bytes()
+	hash := tx.Tx.Hash().Bytes()
 	pub, err := ecc.RecoverPubkey("P-256k1", hash, tx.Sig)
 	if err != nil {
 		return false, err
@@ -130,11 +246,18 @@ func pairHashStr(l, r string) string {
 	return l + r
 }
 
+// BatchEntry identifies a single transfer of a batch transaction
+type BatchEntry struct {
+	Index int `json:"index"`
+	Transfer
+}
+
 type SearchTx struct {
 	SigTx
-	BlockNumber uint64 `json:"blockNumber"`
-	BlockHash   Hash   `json:"blockHash"`
-	MerkleRoot  Hash   `json:"merkleRoot"`
+	BlockNumber uint64      `json:"blockNumber"`
+	BlockHash   Hash        `json:"blockHash"`
+	MerkleRoot  Hash        `json:"merkleRoot"`
+	Entry       *BatchEntry `json:"entry,omitempty"`
 }
 
 func NewSearchTx(tx SigTx, blockNumber uint64, blockHash, merkleRoot Hash) SearchTx {
@@ -147,8 +270,14 @@ func NewSearchTx(tx SigTx, blockNumber uint64, blockHash, merkleRoot Hash) Searc
 }
 
 func (t SearchTx) String() string {
-	return fmt.Sprintf(
+	str := fmt.Sprintf(
 		"%v    blk %4d   %.7s   mrk %.7s",
 		t.SigTx, t.BlockNumber, t.BlockHash, t.MerkleRoot,
 	)
+	if t.Entry != nil {
+		str += fmt.Sprintf(
+			"\n  #%-4d -> %.7s %8d", t.Entry.Index, t.Entry.To, t.Entry.Value,
+		)
+	}
+	return str
 }
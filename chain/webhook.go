@@ -0,0 +1,150 @@
+package chain
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+const (
+	webhooksFile    = "webhooks.json"
+	deadLettersFile = "deadletters.json"
+)
+
+// ErrWebhookTarget rejects webhook URLs of hosts that the node must not call
+var ErrWebhookTarget = errors.New("webhook: invalid target")
+
+// Webhook delivers the events selected by the filter to the URL. The cursor
+// is the sequence number of the last delivered or dead lettered event
+type Webhook struct {
+	ID           string      `json:"id"`
+	URL          string      `json:"url"`
+	Secret       string      `json:"secret,omitempty"`
+	Filter       EventFilter `json:"filter"`
+	Cursor       uint64      `json:"cursor"`
+	Delivered    uint64      `json:"delivered"`
+	Failed       uint64      `json:"failed"`
+	Attempts     uint64      `json:"attempts"`
+	LastError    string      `json:"lastError,omitempty"`
+	LastDelivery time.Time   `json:"lastDelivery,omitzero"`
+}
+
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	_, err := rand.Read(buf)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// NewWebhook creates a webhook with a random id. A random secret is generated
+// when the secret is empty
+func NewWebhook(
+	url, secret string, filter EventFilter, cursor uint64,
+) (Webhook, error) {
+	id, err := randomHex(8)
+	if err != nil {
+		return Webhook{}, err
+	}
+	if len(secret) == 0 {
+		secret, err = randomHex(32)
+		if err != nil {
+			return Webhook{}, err
+		}
+	}
+	hook := Webhook{
+		ID: id, URL: url, Secret: secret, Filter: filter, Cursor: cursor,
+	}
+	return hook, nil
+}
+
+func (h Webhook) String() string {
+	status := "ok"
+	if len(h.LastError) > 0 {
+		status = h.LastError
+	}
+	return fmt.Sprintf(
+		"hook %v   %v\n  cursor %d   delivered %d   failed %d   attempts %d   %v",
+		h.ID, h.URL, h.Cursor, h.Delivered, h.Failed, h.Attempts, status,
+	)
+}
+
+// WebhookSignature signs the timestamp and the body of a webhook payload
+// with HMAC-SHA256 of the webhook secret
+func WebhookSignature(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	_, _ = mac.Write([]byte("."))
+	_, _ = mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhook verifies the signature of a webhook payload in constant time
+func VerifyWebhook(secret string, timestamp int64, body []byte, sig string) bool {
+	expected := WebhookSignature(secret, timestamp, body)
+	return hmac.Equal([]byte(expected), []byte(sig))
+}
+
+func WriteWebhooks(dir string, hooks []Webhook) error {
+	jhooks, err := json.MarshalIndent(hooks, "", "  ")
+	if err != nil {
+		return err
+	}
+	// Replace the file atomically to not lose the webhooks on a crash
+	path := filepath.Join(dir, webhooksFile)
+	tmpPath := path + ".tmp"
+	err = os.WriteFile(tmpPath, jhooks, 0600)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// ReadWebhooks reads the registered webhooks. No webhooks are returned when
+// none have been registered yet
+func ReadWebhooks(dir string) ([]Webhook, error) {
+	path := filepath.Join(dir, webhooksFile)
+	jhooks, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var hooks []Webhook
+	err = json.Unmarshal(jhooks, &hooks)
+	if err != nil {
+		return nil, err
+	}
+	return hooks, nil
+}
+
+// DeadLetter records an event that could not be delivered to a webhook
+type DeadLetter struct {
+	Webhook  string    `json:"webhook"`
+	URL      string    `json:"url"`
+	Event    Event     `json:"event"`
+	Attempts uint64    `json:"attempts"`
+	Error    string    `json:"error"`
+	Time     time.Time `json:"time"`
+}
+
+func (d DeadLetter) Write(dir string) error {
+	path := filepath.Join(dir, deadLettersFile)
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return json.NewEncoder(file).Encode(d)
+}
@@ -0,0 +1,74 @@
+package chain
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWebhookSignature(t *testing.T) {
+	const secret, timestamp = "secret", int64(1700000000)
+	body := []byte(`{"webhook":"hook","seq":1}`)
+	sig := WebhookSignature(secret, timestamp, body)
+	if !strings.HasPrefix(sig, "sha256=") || len(sig) != len("sha256=")+64 {
+		t.Fatalf("expected sha256= and 64 hex digits, got %v", sig)
+	}
+	if WebhookSignature(secret, timestamp, body) != sig {
+		t.Fatal("expected a deterministic signature")
+	}
+	cases := []struct {
+		name      string
+		secret    string
+		timestamp int64
+		body      []byte
+		sig       string
+		valid     bool
+	}{
+		{"valid", secret, timestamp, body, sig, true},
+		{"other secret", "other", timestamp, body, sig, false},
+		{"other timestamp", secret, timestamp + 1, body, sig, false},
+		{"tampered body", secret, timestamp, []byte(`{"webhook":"hook","seq":2}`),
+			sig, false},
+		{"without prefix", secret, timestamp, body,
+			strings.TrimPrefix(sig, "sha256="), false},
+		{"upper case", secret, timestamp, body, strings.ToUpper(sig), false},
+		{"empty", secret, timestamp, body, "", false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			valid := VerifyWebhook(c.secret, c.timestamp, c.body, c.sig)
+			if valid != c.valid {
+				t.Errorf("expected valid %v, got %v", c.valid, valid)
+			}
+		})
+	}
+}
+
+func TestWebhooksReadWrite(t *testing.T) {
+	dir := t.TempDir()
+	hooks, err := ReadWebhooks(dir)
+	if err != nil || len(hooks) != 0 {
+		t.Fatalf("expected no webhooks, got %v %v", hooks, err)
+	}
+	hook, err := NewWebhook(
+		"https://example.com/hook", "", EventFilter{Types: []EventType{EvTx}}, 5,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hook.ID) != 16 || len(hook.Secret) != 64 {
+		t.Fatalf("expected a random id and secret, got %v %v", hook.ID, hook.Secret)
+	}
+	err = WriteWebhooks(dir, []Webhook{hook})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hooks, err = ReadWebhooks(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hooks) != 1 || hooks[0].ID != hook.ID ||
+		hooks[0].Secret != hook.Secret || hooks[0].Cursor != 5 ||
+		len(hooks[0].Filter.Types) != 1 {
+		t.Errorf("expected %v, got %v", hook, hooks)
+	}
+}
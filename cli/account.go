@@ -2,8 +2,15 @@ package cli
 
 import (
 	"context"
+	"encoding/csv"
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
 
+	"github.com/Ansh1902396/chain"
 	"github.com/Ansh1902396/node/rpc"
 	"github.com/spf13/cobra"
 	"google.golang.org/grpc"
@@ -15,7 +22,10 @@ func accountCmd(ctx context.Context) *cobra.Command {
 		Use:   "account",
 		Short: "Manage accounts on the blockchain",
 	}
-	cmd.AddCommand(AccountCreateCmd(ctx), accountBalanceCmd(ctx))
+	addNodeFlag(cmd)
+	cmd.AddCommand(
+		AccountCreateCmd(ctx), accountBalanceCmd(ctx), accountHistoryCmd(ctx),
+	)
 	return cmd
 }
 
@@ -56,7 +66,7 @@ func AccountCreateCmd(ctx context.Context) *cobra.Command {
 				return err
 			}
 
-			fmt.Println("acc %v\n", acc)
+			fmt.Printf("acc %v\n", acc)
 			return nil
 		},
 	}
@@ -102,3 +112,132 @@ func accountBalanceCmd(ctx context.Context) *cobra.Command {
 	_ = cmd.MarkFlagRequired("account")
 	return cmd
 }
+
+func grpcAccountHistory(
+	ctx context.Context, addr string, req *rpc.AccountHistoryReq,
+) (func(yield func(err error, entry chain.HistoryEntry) bool), func(), error) {
+	conn, err := grpc.NewClient(
+		addr, grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	close := func() {
+		conn.Close()
+	}
+	cln := rpc.NewAccountClient(conn)
+	stream, err := cln.AccountHistory(ctx, req)
+	if err != nil {
+		return nil, nil, err
+	}
+	more := true
+	entries := func(yield func(err error, entry chain.HistoryEntry) bool) {
+		for more {
+			res, err := stream.Recv()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				yield(err, chain.HistoryEntry{})
+				return
+			}
+			var entry chain.HistoryEntry
+			err = json.Unmarshal(res.Entry, &entry)
+			if err != nil {
+				yield(err, chain.HistoryEntry{})
+				return
+			}
+			more = yield(nil, entry)
+		}
+	}
+	return entries, close, nil
+}
+
+var historyCSVHeader = []string{
+	"block", "time", "tx", "index", "kind", "direction", "from", "to", "value",
+	"balance", "memo",
+}
+
+func historyCSVRow(entry chain.HistoryEntry) []string {
+	direction := "debit"
+	if entry.Credit {
+		direction = "credit"
+	}
+	return []string{
+		strconv.FormatUint(entry.BlockNumber, 10),
+		entry.Time.UTC().Format(time.RFC3339),
+		entry.Hash.String(),
+		strconv.Itoa(entry.Index),
+		entry.Kind,
+		direction,
+		string(entry.From),
+		string(entry.To),
+		strconv.FormatUint(entry.Value, 10),
+		strconv.FormatUint(entry.Balance, 10),
+		entry.Memo,
+	}
+}
+
+func accountHistoryCmd(ctx context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "Lists the transfers of an account with the running balance",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			addr, _ := cmd.Flags().GetString("node")
+			acc, _ := cmd.Flags().GetString("account")
+			csvPath, _ := cmd.Flags().GetString("csv")
+			var opts searchOpts
+			err := readRangeFlags(cmd, &opts)
+			if err != nil {
+				return err
+			}
+			req := &rpc.AccountHistoryReq{
+				Address: acc, FromNumber: opts.fromNumber, ToNumber: opts.toNumber,
+				Since: opts.since, Until: opts.until,
+			}
+			entries, closeEntries, err := grpcAccountHistory(ctx, addr, req)
+			if err != nil {
+				return err
+			}
+			defer closeEntries()
+			if len(csvPath) == 0 {
+				for err, entry := range entries {
+					if err != nil {
+						return err
+					}
+					fmt.Printf("%v\n", entry)
+				}
+				return nil
+			}
+			out := os.Stdout
+			if csvPath != "-" {
+				out, err = os.Create(csvPath)
+				if err != nil {
+					return err
+				}
+				defer out.Close()
+			}
+			wr := csv.NewWriter(out)
+			err = wr.Write(historyCSVHeader)
+			if err != nil {
+				return err
+			}
+			for err, entry := range entries {
+				if err != nil {
+					return err
+				}
+				err = wr.Write(historyCSVRow(entry))
+				if err != nil {
+					return err
+				}
+			}
+			wr.Flush()
+			return wr.Error()
+		},
+	}
+	cmd.Flags().String("account", "", "account address")
+	_ = cmd.MarkFlagRequired("account")
+	addRangeFlags(cmd)
+	cmd.Flags().String("csv", "", "export the history as CSV to the file or - for stdout")
+	return cmd
+}
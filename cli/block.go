@@ -18,20 +18,27 @@ func blockCmd(ctx context.Context) *cobra.Command {
 		Use:   "block",
 		Short: "Manage blocks on the blockchain",
 	}
+	addNodeFlag(cmd)
 	cmd.AddCommand(blockSearchCmd(ctx))
 	return cmd
 }
 
+// searchBlockRes is a found block with the total of matches and the cursor
+// of the block
+type searchBlockRes struct {
+	chain.SigBlock
+	total  uint64
+	cursor string
+}
+
 func grpcBlockSearch(
-	ctx context.Context,
-	addr string,
-	number uint64,
-	hash, parent string,
+	ctx context.Context, addr string, req *rpc.BlockSearchReq,
 ) (
-	func(yield func(err error, blk chain.SigBlock) bool), func(), error,
+	func(yield func(err error, blk searchBlockRes) bool), func(), error,
 ) {
 	conn, err := grpc.NewClient(
 		addr, grpc.WithTransportCredentials(insecure.NewCredentials()),
+		rpc.WithMaxMsgSize(),
 	)
 
 	if err != nil {
@@ -43,33 +50,27 @@ func grpcBlockSearch(
 	}
 
 	cln := rpc.NewBlockClient(conn)
-	req := &rpc.BlockSearchReq{
-		Number: number,
-		Hash:   hash,
-		Parent: parent,
-	}
-
 	stream, err := cln.BlockSearch(ctx, req)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	more := true
-	blocks := func(yield func(err error, blk chain.SigBlock) bool) {
+	blocks := func(yield func(err error, blk searchBlockRes) bool) {
 		for more {
 			res, err := stream.Recv()
-			if err != io.EOF {
+			if err == io.EOF {
 				return
 			}
 			if err != nil {
-				yield(err, chain.SigBlock{})
+				yield(err, searchBlockRes{})
 				return
 			}
-			var blk chain.SigBlock
+			blk := searchBlockRes{total: res.Total, cursor: res.Cursor}
 
-			err = json.Unmarshal(res.Block, &blk)
+			err = json.Unmarshal(res.Block, &blk.SigBlock)
 			if err != nil {
-				yield(err, chain.SigBlock{})
+				yield(err, searchBlockRes{})
 				return
 			}
 			more = yield(nil, blk)
@@ -83,30 +84,42 @@ func grpcBlockSearch(
 func blockSearchCmd(ctx context.Context) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "search",
-		Short: "Searches blocks by the block number, block hash, and parent hash",
+		Short: "Searches blocks by the block number, block hash, parent hash, block range, and time range",
 		RunE: func(cmd *cobra.Command, _ []string) error {
 			addr, _ := cmd.Flags().GetString("node")
 			number, _ := cmd.Flags().GetUint64("number")
 			hash, _ := cmd.Flags().GetString("hash")
 			parent, _ := cmd.Flags().GetString("parent")
-			blocks, closeBlocks, err := grpcBlockSearch(ctx, addr, number, hash, parent)
+			opts, err := readSearchFlags(cmd)
+			if err != nil {
+				return err
+			}
+			req := &rpc.BlockSearchReq{
+				Number: number, Hash: hash, Parent: parent,
+				FromNumber: opts.fromNumber, ToNumber: opts.toNumber,
+				Since: opts.since, Until: opts.until, Limit: opts.limit,
+				Offset: opts.offset, Cursor: opts.cursor, Desc: opts.desc,
+			}
+			blocks, closeBlocks, err := grpcBlockSearch(ctx, addr, req)
 			if err != nil {
 				return err
 			}
 			defer closeBlocks()
-			found := false
+			var last *searchBlockRes
 			for err, blk := range blocks {
 				if err != nil {
 					return err
 				}
-				found = true
+				last = &blk
 				fmt.Printf("blk %s\n", blk.Hash())
 				fmt.Printf("mrk %s\n", blk.MerkleRoot)
-				fmt.Printf("%v", blk)
+				fmt.Printf("%v", blk.SigBlock)
 			}
-			if !found {
+			if last == nil {
 				fmt.Println("no blocks found")
+				return nil
 			}
+			fmt.Printf("total %d   cursor %s\n", last.total, last.cursor)
 			return nil
 		},
 	}
@@ -114,6 +127,6 @@ func blockSearchCmd(ctx context.Context) *cobra.Command {
 	cmd.Flags().String("hash", "", "block hash prefix")
 	cmd.Flags().String("parent", "", "parent hash prefix")
 	cmd.MarkFlagsMutuallyExclusive("number", "hash", "parent")
-	cmd.MarkFlagsOneRequired("number", "hash", "parent")
+	addSearchFlags(cmd)
 	return cmd
 }
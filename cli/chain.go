@@ -2,7 +2,14 @@ package cli
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
 
+	"github.com/Ansh1902396/chain"
+	"github.com/Ansh1902396/node"
 	"github.com/spf13/cobra"
 )
 
@@ -10,12 +17,95 @@ func ChainCmd(ctx context.Context) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:           "RuChain",
 		Short:         "RuChain - Manages the blockchain node, accounts, transactions, and blocks",
-		Version:       "0.1.0",
+		Version:       node.Version,
 		SilenceUsage:  true,
 		SilenceErrors: true,
 	}
+	cmd.AddCommand(
+		nodeCmd(ctx), accountCmd(ctx), txCmd(ctx), blockCmd(ctx), htlcCmd(ctx),
+		webhookCmd(ctx), chainCmd(ctx),
+	)
+	return cmd
+}
+
+// addNodeFlag adds the required address of the target node to the commands
+// of a group. The node start listens on the address
+func addNodeFlag(cmd *cobra.Command) {
 	cmd.PersistentFlags().String("node", "", "target node address host:port")
-	_ = cmd.MarkFlagRequired("node")
-	cmd.AddCommand(nodeCmd(ctx), accountCmd(ctx), txCmd(ctx), blockCmd(ctx))
+	_ = cmd.MarkPersistentFlagRequired("node")
+}
+
+// chainCmd manages the chain locally without a target node
+func chainCmd(_ context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "chain",
+		Short: "Manages the blockchain genesis",
+	}
+	cmd.AddCommand(chainInitCmd())
+	return cmd
+}
+
+// readGenesisSpec reads the unsigned genesis of the chain init. The time of
+// the genesis defaults to now
+func readGenesisSpec(path string) (chain.Genesis, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return chain.Genesis{}, err
+	}
+	var gen chain.Genesis
+	err = json.Unmarshal(data, &gen)
+	if err != nil {
+		return chain.Genesis{}, fmt.Errorf("genesis %v: %w", path, err)
+	}
+	if gen.Time.IsZero() {
+		gen.Time = time.Now()
+	}
+	return gen, gen.Validate()
+}
+
+func chainInitCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "init",
+		Short: "Signs a genesis file with the authority account of the key store",
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return readPassword(cmd, "authpass", "Authority account password: ", true)
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			spec, _ := cmd.Flags().GetString("spec")
+			keyStoreDir, _ := cmd.Flags().GetString("keystore")
+			authPass, _ := cmd.Flags().GetString("authpass")
+			out, _ := cmd.Flags().GetString("out")
+			gen, err := readGenesisSpec(spec)
+			if err != nil {
+				return err
+			}
+			path := filepath.Join(keyStoreDir, string(gen.Authority))
+			auth, err := chain.ReadAccount(path, []byte(authPass))
+			if err != nil {
+				return fmt.Errorf("authority %v: %w", gen.Authority, err)
+			}
+			sgen, err := auth.SignGen(gen)
+			if err != nil {
+				return err
+			}
+			err = sgen.WriteFile(out)
+			if err != nil {
+				return err
+			}
+			fmt.Printf(
+				"genesis %v of %v: %v accounts, %v validators\n",
+				sgen.Hash(), sgen.Chain, len(sgen.Balances), len(sgen.ValidatorSet()),
+			)
+			fmt.Printf("written to %v\n", out)
+			return nil
+		},
+	}
+	cmd.Flags().String("spec", "", "unsigned genesis JSON file")
+	_ = cmd.MarkFlagRequired("spec")
+	cmd.Flags().String("keystore", "", "key store directory of the authority account")
+	_ = cmd.MarkFlagRequired("keystore")
+	cmd.Flags().String("authpass", "", "authority account password")
+	cmd.Flags().String("authpass-file", "", "file with the authority account password")
+	cmd.Flags().String("out", "genesis.json", "signed genesis file")
 	return cmd
 }
@@ -0,0 +1,166 @@
+package cli
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"golang.org/x/term"
+	"gopkg.in/yaml.v3"
+)
+
+// configEnvPrefix prefixes the environment variables that override the
+// config file, e.g. RUCHAIN_BLOCK_PERIOD for the block-period key
+const configEnvPrefix = "RUCHAIN_"
+
+// configSecrets are the flags that are not read from the config file, the
+// passwords are read from the password files instead
+var configSecrets = []string{"authpass", "ownerpass", "validatorpass"}
+
+func configEnv(name string) string {
+	return configEnvPrefix + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// readConfig reads the TOML or YAML config file by its extension. The keys
+// are the flag names. The keys of the selected profile override the top
+// level keys
+func readConfig(path, profile string) (map[string]any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]any)
+	switch filepath.Ext(path) {
+	case ".toml":
+		err = toml.Unmarshal(data, &values)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &values)
+	default:
+		return nil, fmt.Errorf("config %v: expected .toml, .yaml, or .yml file", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("config %v: %w", path, err)
+	}
+	profiles, _ := values["profiles"].(map[string]any)
+	delete(values, "profiles")
+	if len(profile) == 0 {
+		return values, nil
+	}
+	prof, ok := profiles[profile].(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("config %v: profile %v not found", path, profile)
+	}
+	maps.Copy(values, prof)
+	return values, nil
+}
+
+// configValue formats a config value as a flag value
+func configValue(value any) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int, int64, uint64:
+		return fmt.Sprint(v), nil
+	case []any:
+		strs := make([]string, len(v))
+		for i, item := range v {
+			str, err := configValue(item)
+			if err != nil {
+				return "", err
+			}
+			strs[i] = str
+		}
+		return strings.Join(strs, ","), nil
+	default:
+		return "", fmt.Errorf("unsupported value %v", value)
+	}
+}
+
+// applyConfig sets the flags missing on the command line from the
+// environment, then from the config file and its profile
+func applyConfig(cmd *cobra.Command) error {
+	flags := cmd.Flags()
+	var err error
+	flags.VisitAll(func(f *pflag.Flag) {
+		value, exist := os.LookupEnv(configEnv(f.Name))
+		if err != nil || f.Changed || !exist {
+			return
+		}
+		err = flags.Set(f.Name, value)
+		if err != nil {
+			err = fmt.Errorf("%v: %w", configEnv(f.Name), err)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	path, _ := flags.GetString("config")
+	profile, _ := flags.GetString("profile")
+	if len(path) == 0 {
+		if len(profile) > 0 {
+			return fmt.Errorf("--profile requires --config")
+		}
+		return nil
+	}
+	values, err := readConfig(path, profile)
+	if err != nil {
+		return err
+	}
+	for _, key := range slices.Sorted(maps.Keys(values)) {
+		if slices.Contains(configSecrets, key) {
+			return fmt.Errorf("config %v: use %v-file instead of %v", path, key, key)
+		}
+		f := flags.Lookup(key)
+		if f == nil || key == "config" || key == "profile" || key == "help" {
+			return fmt.Errorf("config %v: unknown key %v", path, key)
+		}
+		if f.Changed {
+			continue
+		}
+		str, err := configValue(values[key])
+		if err == nil {
+			err = flags.Set(key, str)
+		}
+		if err != nil {
+			return fmt.Errorf("config %v: %v: %w", path, key, err)
+		}
+	}
+	return nil
+}
+
+// readPassword sets a missing password flag from its password file or,
+// when the password is required, from a terminal prompt
+func readPassword(cmd *cobra.Command, name, prompt string, required bool) error {
+	flags := cmd.Flags()
+	if flags.Changed(name) {
+		return nil
+	}
+	path, _ := flags.GetString(name + "-file")
+	if len(path) > 0 {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return flags.Set(name, strings.TrimRight(string(data), "\r\n"))
+	}
+	fd := int(os.Stdin.Fd())
+	if !required || !term.IsTerminal(fd) {
+		return nil
+	}
+	fmt.Fprint(os.Stderr, prompt)
+	pass, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return err
+	}
+	return flags.Set(name, string(pass))
+}
@@ -0,0 +1,210 @@
+package cli
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Ansh1902396/chain"
+	"github.com/Ansh1902396/node/rpc"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func htlcCmd(ctx context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "htlc",
+		Short: "Manages hash time-locked contracts for cross-chain atomic swaps",
+	}
+	addNodeFlag(cmd)
+	cmd.AddCommand(
+		htlcLockCmd(ctx), htlcClaimCmd(ctx), htlcRefundCmd(ctx), htlcShowCmd(ctx),
+	)
+	return cmd
+}
+
+// signSendHTLC signs the HTLC transaction with the owner account, sends it
+// to the node, and optionally waits for the transaction receipt
+func signSendHTLC(
+	ctx context.Context, cmd *cobra.Command, req *rpc.TxSignReq,
+) (string, error) {
+	addr, _ := cmd.Flags().GetString("node")
+	jtx, err := grpcTxSign(ctx, addr, req)
+	if err != nil {
+		return "", err
+	}
+	hash, err := grpcTxSend(ctx, addr, string(jtx))
+	if err != nil {
+		return "", err
+	}
+	fmt.Printf("tx %s\n", hash)
+	wait, _ := cmd.Flags().GetBool("wait")
+	if !wait {
+		return hash, nil
+	}
+	rcps, closeRcps, err := grpcTxWait(ctx, addr, hash)
+	if err != nil {
+		return "", err
+	}
+	defer closeRcps()
+	for err, rcp := range rcps {
+		if err != nil {
+			return "", err
+		}
+		fmt.Printf("%v\n", rcp)
+	}
+	return hash, nil
+}
+
+func htlcLockCmd(ctx context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "lock",
+		Short: "Locks value for the recipient under a hash lock and a time lock",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			from, _ := cmd.Flags().GetString("from")
+			to, _ := cmd.Flags().GetString("to")
+			value, _ := cmd.Flags().GetUint64("value")
+			ownerPass, _ := cmd.Flags().GetString("ownerpass")
+			hashLock, _ := cmd.Flags().GetString("hashlock")
+			timeLockStr, _ := cmd.Flags().GetString("timelock")
+			timeLock, err := parseTxBound(timeLockStr)
+			if err != nil {
+				return err
+			}
+			// The swap initiator generates the secret, the participant reuses
+			// the hash lock of the initiator
+			if len(hashLock) == 0 {
+				preimage, hash, err := chain.NewSecret()
+				if err != nil {
+					return err
+				}
+				hashLock = hash.String()
+				fmt.Printf("pre %x\n", preimage)
+			}
+			fmt.Printf("hlk %s\n", hashLock)
+			req := &rpc.TxSignReq{
+				From: from, To: to, Value: value, Password: ownerPass,
+				HTLC: &rpc.HTLC{
+					Op: chain.HTLCLock, HashLock: hashLock, TimeLock: timeLock,
+				},
+			}
+			_, err = signSendHTLC(ctx, cmd, req)
+			return err
+		},
+	}
+	cmd.Flags().String("from", "", "sender address")
+	_ = cmd.MarkFlagRequired("from")
+	cmd.Flags().String("to", "", "recipient address")
+	_ = cmd.MarkFlagRequired("to")
+	cmd.Flags().Uint64("value", 0, "locked amount")
+	_ = cmd.MarkFlagRequired("value")
+	cmd.Flags().String("ownerpass", "", "owner account password")
+	_ = cmd.MarkFlagRequired("ownerpass")
+	cmd.Flags().String("hashlock", "", "hash lock of the counterparty (default new secret)")
+	cmd.Flags().String(
+		"timelock", "", "refund after block height, RFC 3339 time, or duration from now",
+	)
+	_ = cmd.MarkFlagRequired("timelock")
+	cmd.Flags().Bool("wait", false, "wait until the transaction is included in a block")
+	return cmd
+}
+
+func htlcClaimCmd(ctx context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "claim",
+		Short: "Claims the locked value by revealing the preimage",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			from, _ := cmd.Flags().GetString("from")
+			lock, _ := cmd.Flags().GetString("lock")
+			ownerPass, _ := cmd.Flags().GetString("ownerpass")
+			preimageStr, _ := cmd.Flags().GetString("preimage")
+			preimage, err := hex.DecodeString(preimageStr)
+			if err != nil {
+				return fmt.Errorf("expected hex preimage: %v", err)
+			}
+			req := &rpc.TxSignReq{
+				From: from, Password: ownerPass,
+				HTLC: &rpc.HTLC{Op: chain.HTLCClaim, Lock: lock, Preimage: preimage},
+			}
+			_, err = signSendHTLC(ctx, cmd, req)
+			return err
+		},
+	}
+	cmd.Flags().String("from", "", "recipient address of the lock")
+	_ = cmd.MarkFlagRequired("from")
+	cmd.Flags().String("lock", "", "lock transaction hash")
+	_ = cmd.MarkFlagRequired("lock")
+	cmd.Flags().String("preimage", "", "hex encoded secret preimage")
+	_ = cmd.MarkFlagRequired("preimage")
+	cmd.Flags().String("ownerpass", "", "owner account password")
+	_ = cmd.MarkFlagRequired("ownerpass")
+	cmd.Flags().Bool("wait", false, "wait until the transaction is included in a block")
+	return cmd
+}
+
+func htlcRefundCmd(ctx context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "refund",
+		Short: "Refunds the locked value to the sender after the time lock",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			from, _ := cmd.Flags().GetString("from")
+			lock, _ := cmd.Flags().GetString("lock")
+			ownerPass, _ := cmd.Flags().GetString("ownerpass")
+			req := &rpc.TxSignReq{
+				From: from, Password: ownerPass,
+				HTLC: &rpc.HTLC{Op: chain.HTLCRefund, Lock: lock},
+			}
+			_, err := signSendHTLC(ctx, cmd, req)
+			return err
+		},
+	}
+	cmd.Flags().String("from", "", "sender address of the lock")
+	_ = cmd.MarkFlagRequired("from")
+	cmd.Flags().String("lock", "", "lock transaction hash")
+	_ = cmd.MarkFlagRequired("lock")
+	cmd.Flags().String("ownerpass", "", "owner account password")
+	_ = cmd.MarkFlagRequired("ownerpass")
+	cmd.Flags().Bool("wait", false, "wait until the transaction is included in a block")
+	return cmd
+}
+
+func grpcTxLock(ctx context.Context, addr, hash string) (chain.Lock, error) {
+	conn, err := grpc.NewClient(
+		addr, grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		return chain.Lock{}, err
+	}
+	defer conn.Close()
+	cln := rpc.NewTxClient(conn)
+	req := &rpc.TxLockReq{Hash: hash}
+	res, err := cln.TxLock(ctx, req)
+	if err != nil {
+		return chain.Lock{}, err
+	}
+	var lock chain.Lock
+	err = json.Unmarshal(res.Lock, &lock)
+	return lock, err
+}
+
+func htlcShowCmd(ctx context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "show",
+		Short: "Shows the lock status and the revealed preimage of a claimed lock",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			addr, _ := cmd.Flags().GetString("node")
+			hash, _ := cmd.Flags().GetString("lock")
+			lock, err := grpcTxLock(ctx, addr, hash)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("%v\n", lock)
+			return nil
+		},
+	}
+	cmd.Flags().String("lock", "", "lock transaction hash")
+	_ = cmd.MarkFlagRequired("lock")
+	return cmd
+}
@@ -5,13 +5,18 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"os"
 	"regexp"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/Ansh1902396/chain"
 	"github.com/Ansh1902396/node"
 	"github.com/Ansh1902396/node/rpc"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 )
@@ -21,74 +26,318 @@ func nodeCmd(ctx context.Context) *cobra.Command {
 		Use:   "node",
 		Short: "Manages the blockchain node",
 	}
-	cmd.AddCommand(nodeStartCmd(ctx), nodeSubscribeCmd(ctx))
+	addNodeFlag(cmd)
+	cmd.AddCommand(
+		nodeStartCmd(ctx), nodeSubscribeCmd(ctx), nodeStatusCmd(ctx),
+		nodeConfigCmd(ctx),
+	)
 	return cmd
 }
 
-func nodeStartCmd(_ context.Context) *cobra.Command {
+func nodeConfigCmd(ctx context.Context) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "start",
-		Short: "Starts the blockchain node",
-		RunE: func(cmd *cobra.Command, _ []string) error {
-			nodeAddr, _ := cmd.Flags().GetString("node")
-			reAddr := regexp.MustCompile(`[-\.\w]+:\d+`)
-			if !reAddr.MatchString(nodeAddr) {
-				return fmt.Errorf("expected --node host:port, got %v", nodeAddr)
+		Use:   "config",
+		Short: "Manages the node configuration",
+	}
+	cmd.AddCommand(nodeConfigValidateCmd(ctx))
+	return cmd
+}
+
+func nodeConfigValidateCmd(_ context.Context) *cobra.Command {
+	prompted := make([]string, 0)
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Validates the node configuration and prints the effective settings",
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			err := applyConfig(cmd)
+			if err != nil {
+				return err
 			}
+			err = readNodePasswords(cmd, false)
+			if err != nil {
+				return err
+			}
+			// The missing required passwords are prompted for on start
 			bootstrap, _ := cmd.Flags().GetBool("bootstrap")
-			seedAddr, _ := cmd.Flags().GetString("seed")
-			if !bootstrap && len(seedAddr) == 0 {
-				return fmt.Errorf(
-					"either --bootstrap or --seed host:port must be provided",
-				)
+			required := map[string]bool{
+				"authpass": bootstrap, "ownerpass": cmd.Flags().Changed("balance"),
+				"validatorpass": cmd.Flags().Changed("validator"),
 			}
-			if !bootstrap && !reAddr.MatchString(seedAddr) {
-				return fmt.Errorf("expected --seed host:port, got %v", seedAddr)
+			for _, name := range configSecrets {
+				if required[name] && !cmd.Flags().Changed(name) {
+					prompted = append(prompted, name)
+					_ = cmd.Flags().Set(name, "")
+				}
 			}
-			rePort := regexp.MustCompile(`\d+$`)
-			port := rePort.FindString(nodeAddr)
-			keyStoreDir, _ := cmd.Flags().GetString("keystore")
-			if len(keyStoreDir) == 0 {
-				keyStoreDir = ".keystore" + port
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			_, err := readNodeCfg(cmd)
+			if err != nil {
+				return err
 			}
-			blockStoreDir, _ := cmd.Flags().GetString("blockstore")
-			if len(blockStoreDir) == 0 {
-				blockStoreDir = ".blockstore" + port
+			cmd.Flags().VisitAll(func(f *pflag.Flag) {
+				switch {
+				case f.Name == "config" || f.Name == "profile" || f.Name == "help":
+					return
+				case slices.Contains(prompted, f.Name):
+					fmt.Printf("%-16s <prompt>\n", f.Name)
+				case slices.Contains(configSecrets, f.Name) && f.Changed:
+					fmt.Printf("%-16s ********\n", f.Name)
+				default:
+					fmt.Printf("%-16s %v\n", f.Name, f.Value)
+				}
+			})
+			fmt.Println("Config valid")
+			return nil
+		},
+	}
+	addNodeStartFlags(cmd)
+	return cmd
+}
+
+func nodeStartCmd(_ context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "start",
+		Short: "Starts the blockchain node",
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			err := applyConfig(cmd)
+			if err != nil {
+				return err
 			}
-			name, _ := cmd.Flags().GetString("chain")
-			authPass, _ := cmd.Flags().GetString("authpass")
-			ownerPass, _ := cmd.Flags().GetString("ownerpass")
-			balance, _ := cmd.Flags().GetUint64("balance")
-			cfg := node.NodeCfg{
-				NodeAddr: nodeAddr, Bootstrap: bootstrap, SeedAddr: seedAddr,
-				KeyStoreDir: keyStoreDir, BlockStoreDir: blockStoreDir,
-				Chain: name, AuthorityPass: authPass, OwnerPass: ownerPass, Balance: balance,
-				Period: 5 * time.Second,
+			return readNodePasswords(cmd, true)
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cfg, err := readNodeCfg(cmd)
+			if err != nil {
+				return err
 			}
 			nd := node.NewNode(cfg)
 			return nd.Start()
 		},
 	}
+	addNodeStartFlags(cmd)
+	return cmd
+}
+
+// addNodeStartFlags adds the flags of the node start shared by the config
+// validation. The flags are also the keys of the config file
+func addNodeStartFlags(cmd *cobra.Command) {
+	cmd.Flags().String("config", "", "TOML or YAML config file")
+	cmd.Flags().String("profile", "", "profile of the config file")
+	cmd.Flags().String("http", "", "HTTP/JSON gateway address host:port")
+	cmd.Flags().StringSlice(
+		"cors-origins", nil, "origins of browser pages allowed to call the HTTP/JSON gateway",
+	)
+	cmd.Flags().Bool(
+		"http-private", false,
+		"serve the HTTP/JSON routes that take keystore passwords or change the node",
+	)
+	cmd.Flags().String("jsonrpc", "", "Ethereum JSON-RPC address host:port")
+	cmd.Flags().Bool(
+		"webhook-private", false,
+		"allow webhooks to loopback, private, and link-local addresses",
+	)
+	cmd.Flags().String("explorer", "", "read-only block explorer address host:port")
+	cmd.Flags().String("metrics", "", "Prometheus metrics address host:port")
+	cmd.Flags().String("otlp", "", "OTLP gRPC trace collector address host:port")
+	cmd.Flags().String("log-format", "text", "log format text or json")
+	cmd.Flags().String(
+		"log-level", "info",
+		"log level debug, info, warn, or error with component=level overrides",
+	)
 	cmd.Flags().Bool("bootstrap", false, "bootstrap node and authority node")
 	cmd.Flags().String("seed", "", "seed address host:port")
 	cmd.MarkFlagsMutuallyExclusive("bootstrap", "seed")
 	cmd.MarkFlagsOneRequired("bootstrap", "seed")
 	cmd.Flags().String("keystore", "", "key store directory")
 	cmd.Flags().String("blockstore", "", "block store directory")
+	cmd.Flags().String("genesis", "", "signed genesis file instead of the created or synced genesis")
 	cmd.Flags().String("chain", "blockchain", "blockchain name")
 	cmd.Flags().String("authpass", "", "authority account password")
+	cmd.Flags().String("authpass-file", "", "file with the authority account password")
 	cmd.Flags().String("ownerpass", "", "owner account password")
+	cmd.Flags().String("ownerpass-file", "", "file with the owner account password")
 	cmd.Flags().Uint64("balance", 0, "owner account balance")
 	cmd.MarkFlagsRequiredTogether("bootstrap", "authpass")
 	cmd.MarkFlagsRequiredTogether("ownerpass", "balance")
-	return cmd
+	cmd.Flags().String("validator", "", "validator account of a chain of validators")
+	cmd.Flags().String("validatorpass", "", "validator account password")
+	cmd.Flags().String("validatorpass-file", "", "file with the validator account password")
+	cmd.MarkFlagsRequiredTogether("validator", "validatorpass")
+	cmd.Flags().Duration("period", 5*time.Second, "period of the peer discovery and the relays")
+	cmd.Flags().Duration("block-period", 10*time.Second, "interval of the proposed blocks")
+	cmd.Flags().Bool("empty-blocks", false, "propose empty heartbeat blocks without transactions")
+	cmd.Flags().Duration(
+		"max-block-wait", 0,
+		"force a block once a pending transaction has waited this long, 0 to wait for the block period",
+	)
+	cmd.Flags().Int("tx-relay-cap", 100, "transactions waiting to be relayed")
+	cmd.Flags().Int("block-relay-cap", 100, "blocks waiting to be relayed")
+	cmd.Flags().Int("max-peers", 0, "maximum known peers, 0 for no limit")
+}
+
+// readNodePasswords reads the missing passwords from the password files or,
+// if enabled, prompts for the required ones
+func readNodePasswords(cmd *cobra.Command, prompt bool) error {
+	bootstrap, _ := cmd.Flags().GetBool("bootstrap")
+	err := readPassword(
+		cmd, "authpass", "Authority account password: ", prompt && bootstrap,
+	)
+	if err != nil {
+		return err
+	}
+	err = readPassword(
+		cmd, "ownerpass", "Owner account password: ",
+		prompt && cmd.Flags().Changed("balance"),
+	)
+	if err != nil {
+		return err
+	}
+	return readPassword(
+		cmd, "validatorpass", "Validator account password: ",
+		prompt && cmd.Flags().Changed("validator"),
+	)
+}
+
+func readNodeCfg(cmd *cobra.Command) (node.NodeCfg, error) {
+	nodeAddr, _ := cmd.Flags().GetString("node")
+	reAddr := regexp.MustCompile(`[-\.\w]+:\d+`)
+	if !reAddr.MatchString(nodeAddr) {
+		return node.NodeCfg{}, fmt.Errorf("expected --node host:port, got %v", nodeAddr)
+	}
+	addrs := make(map[string]string)
+	for _, name := range []string{"http", "jsonrpc", "explorer", "metrics", "otlp"} {
+		addr, _ := cmd.Flags().GetString(name)
+		if len(addr) > 0 && !reAddr.MatchString(addr) {
+			return node.NodeCfg{}, fmt.Errorf("expected --%v host:port, got %v", name, addr)
+		}
+		addrs[name] = addr
+	}
+	corsOrigins, _ := cmd.Flags().GetStringSlice("cors-origins")
+	httpPrivate, _ := cmd.Flags().GetBool("http-private")
+	webhookPrivate, _ := cmd.Flags().GetBool("webhook-private")
+	bootstrap, _ := cmd.Flags().GetBool("bootstrap")
+	seedAddr, _ := cmd.Flags().GetString("seed")
+	if !bootstrap && len(seedAddr) == 0 {
+		return node.NodeCfg{}, fmt.Errorf(
+			"either --bootstrap or --seed host:port must be provided",
+		)
+	}
+	if !bootstrap && !reAddr.MatchString(seedAddr) {
+		return node.NodeCfg{}, fmt.Errorf("expected --seed host:port, got %v", seedAddr)
+	}
+	rePort := regexp.MustCompile(`\d+$`)
+	port := rePort.FindString(nodeAddr)
+	keyStoreDir, _ := cmd.Flags().GetString("keystore")
+	if len(keyStoreDir) == 0 {
+		keyStoreDir = ".keystore" + port
+	}
+	blockStoreDir, _ := cmd.Flags().GetString("blockstore")
+	if len(blockStoreDir) == 0 {
+		blockStoreDir = ".blockstore" + port
+	}
+	genesisFile, _ := cmd.Flags().GetString("genesis")
+	name, _ := cmd.Flags().GetString("chain")
+	authPass, _ := cmd.Flags().GetString("authpass")
+	ownerPass, _ := cmd.Flags().GetString("ownerpass")
+	validator, _ := cmd.Flags().GetString("validator")
+	validatorPass, _ := cmd.Flags().GetString("validatorpass")
+	balance, _ := cmd.Flags().GetUint64("balance")
+	period, _ := cmd.Flags().GetDuration("period")
+	blockPeriod, _ := cmd.Flags().GetDuration("block-period")
+	if period <= 0 || blockPeriod <= 0 {
+		return node.NodeCfg{}, fmt.Errorf(
+			"expected positive periods, got --period %v --block-period %v",
+			period, blockPeriod,
+		)
+	}
+	emptyBlocks, _ := cmd.Flags().GetBool("empty-blocks")
+	maxBlockWait, _ := cmd.Flags().GetDuration("max-block-wait")
+	if maxBlockWait < 0 {
+		return node.NodeCfg{}, fmt.Errorf(
+			"expected non-negative --max-block-wait, got %v", maxBlockWait,
+		)
+	}
+	txRelayCap, _ := cmd.Flags().GetInt("tx-relay-cap")
+	blockRelayCap, _ := cmd.Flags().GetInt("block-relay-cap")
+	maxPeers, _ := cmd.Flags().GetInt("max-peers")
+	if txRelayCap < 1 || blockRelayCap < 1 || maxPeers < 0 {
+		return node.NodeCfg{}, fmt.Errorf(
+			"expected positive relay capacities and non-negative max peers",
+		)
+	}
+	logFormat, _ := cmd.Flags().GetString("log-format")
+	logLevel, _ := cmd.Flags().GetString("log-level")
+	levels, err := node.ParseLogLevels(logLevel)
+	if err != nil {
+		return node.NodeCfg{}, err
+	}
+	logger, err := node.NewLogger(os.Stdout, logFormat, levels)
+	if err != nil {
+		return node.NodeCfg{}, err
+	}
+	cfg := node.NodeCfg{
+		NodeAddr: nodeAddr, HTTPAddr: addrs["http"], EthRPCAddr: addrs["jsonrpc"],
+		ExplorerAddr: addrs["explorer"], MetricsAddr: addrs["metrics"],
+		OTLPAddr: addrs["otlp"], CORSOrigins: corsOrigins, HTTPPrivate: httpPrivate,
+		WebhookPrivate: webhookPrivate,
+		Bootstrap:      bootstrap, SeedAddr: seedAddr,
+		KeyStoreDir: keyStoreDir, BlockStoreDir: blockStoreDir, GenesisFile: genesisFile,
+		Chain: name, AuthorityPass: authPass, OwnerPass: ownerPass, Balance: balance,
+		Validator: validator, ValidatorPass: validatorPass,
+		Period: period, BlockPeriod: blockPeriod,
+		EmptyBlocks: emptyBlocks, MaxBlockWait: maxBlockWait,
+		TxRelayCap: txRelayCap, BlockRelayCap: blockRelayCap, MaxPeers: maxPeers,
+		Logger: logger,
+	}
+	return cfg, nil
+}
+
+// addEventFilterFlags adds the event filter flags shared by subscriptions
+// and webhooks
+func addEventFilterFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSlice("events", []string{"all"}, "selected event types e.g. blk,tx")
+	cmd.Flags().Uint64("cursor", 0, "replay events after the sequence number")
+	cmd.Flags().Uint64("height", 0, "replay events from the block height")
+	cmd.Flags().String("account", "", "sender or recipient address prefix of transfers")
+	cmd.Flags().Uint64("min-value", 0, "minimum value of a transfer")
+	cmd.Flags().StringSlice("actions", nil, "selected event actions e.g. validated,claim")
+	cmd.Flags().Uint64("min-txs", 0, "minimum number of transactions in a block")
+	cmd.Flags().Uint64("max-height", 0, "maximum block height of events")
+}
+
+func readEventFilter(cmd *cobra.Command) (*rpc.StreamSubscribeReq, error) {
+	evTypesStr, _ := cmd.Flags().GetStringSlice("events")
+	cursor, _ := cmd.Flags().GetUint64("cursor")
+	height, _ := cmd.Flags().GetUint64("height")
+	account, _ := cmd.Flags().GetString("account")
+	minValue, _ := cmd.Flags().GetUint64("min-value")
+	actions, _ := cmd.Flags().GetStringSlice("actions")
+	minTxs, _ := cmd.Flags().GetUint64("min-txs")
+	maxHeight, _ := cmd.Flags().GetUint64("max-height")
+	evTypes := make([]uint64, len(evTypesStr))
+	for i, evTypeStr := range evTypesStr {
+		evType, err := chain.ParseEventType(evTypeStr)
+		if err != nil {
+			return nil, err
+		}
+		evTypes[i] = uint64(evType)
+	}
+	req := &rpc.StreamSubscribeReq{
+		EventTypes: evTypes, Cursor: cursor, Height: height, Account: account,
+		MinValue: minValue, Actions: actions, MinTxs: minTxs,
+		MaxHeight: maxHeight,
+	}
+	return req, nil
 }
 
 func grpcStreamSubscribe(
-	ctx context.Context, addr string, evTypesStr []string,
+	ctx context.Context, addr string, req *rpc.StreamSubscribeReq,
 ) (func(yield func(err error, event chain.Event) bool), func(), error) {
 	conn, err := grpc.NewClient(
 		addr, grpc.WithTransportCredentials(insecure.NewCredentials()),
+		rpc.WithMaxMsgSize(),
 	)
 	if err != nil {
 		return nil, nil, err
@@ -97,11 +346,6 @@ func grpcStreamSubscribe(
 		conn.Close()
 	}
 	cln := rpc.NewNodeClient(conn)
-	evTypes := make([]uint64, len(evTypesStr))
-	for i, evTypeStr := range evTypesStr {
-		evTypes[i] = uint64(chain.NewEventType(evTypeStr))
-	}
-	req := &rpc.StreamSubscribeReq{EventTypes: evTypes}
 	stream, err := cln.StreamSubscribe(ctx, req)
 	if err != nil {
 		return nil, nil, err
@@ -129,14 +373,42 @@ func grpcStreamSubscribe(
 	return events, close, nil
 }
 
+// readCursor reads the sequence number of the last received event from the
+// resume file. Zero is returned when the file does not exist yet
+func readCursor(path string) (uint64, error) {
+	jcur, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(strings.TrimSpace(string(jcur)), 10, 64)
+}
+
+func writeCursor(path string, cursor uint64) error {
+	return os.WriteFile(path, []byte(strconv.FormatUint(cursor, 10)+"\n"), 0600)
+}
+
 func nodeSubscribeCmd(ctx context.Context) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "subscribe",
 		Short: "Subscribes to the selected set of event types from the node",
 		RunE: func(cmd *cobra.Command, _ []string) error {
 			addr, _ := cmd.Flags().GetString("node")
-			evTypesStr, _ := cmd.Flags().GetStringSlice("events")
-			events, closeEvents, err := grpcStreamSubscribe(ctx, addr, evTypesStr)
+			resume, _ := cmd.Flags().GetString("resume")
+			req, err := readEventFilter(cmd)
+			if err != nil {
+				return err
+			}
+			if len(resume) > 0 {
+				last, err := readCursor(resume)
+				if err != nil {
+					return err
+				}
+				req.Cursor = max(req.Cursor, last)
+			}
+			events, closeEvents, err := grpcStreamSubscribe(ctx, addr, req)
 			if err != nil {
 				return err
 			}
@@ -146,10 +418,55 @@ func nodeSubscribeCmd(ctx context.Context) *cobra.Command {
 					return err
 				}
 				fmt.Printf("<~> %v\n", event)
+				if len(resume) > 0 {
+					err = writeCursor(resume, event.Seq)
+					if err != nil {
+						return err
+					}
+				}
 			}
 			return nil
 		},
 	}
-	cmd.Flags().StringSlice("events", []string{"all"}, "selected event types e.g. blk,tx")
+	addEventFilterFlags(cmd)
+	cmd.Flags().String("resume", "", "file of the last received sequence number to resume from")
+	return cmd
+}
+
+func grpcNodeStatus(ctx context.Context, addr string) (*rpc.NodeStatusRes, error) {
+	conn, err := grpc.NewClient(
+		addr, grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	cln := rpc.NewNodeClient(conn)
+	return cln.NodeStatus(ctx, &rpc.NodeStatusReq{})
+}
+
+func nodeStatusCmd(ctx context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Reports the chain head, sync state, peers, and pending transactions of the node",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			addr, _ := cmd.Flags().GetString("node")
+			res, err := grpcNodeStatus(ctx, addr)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("chain     %v\n", res.Chain)
+			fmt.Printf("version   %v\n", res.Version)
+			fmt.Printf("height    %v\n", res.Height)
+			fmt.Printf("head      %v\n", res.HeadHash)
+			fmt.Printf("genesis   %v\n", res.GenesisHash)
+			fmt.Printf("sync      %v\n", res.Sync)
+			fmt.Printf("peers     %v\n", res.Peers)
+			fmt.Printf("pending   %v\n", res.Pending)
+			fmt.Printf("uptime    %v\n", time.Duration(res.Uptime)*time.Second)
+			fmt.Printf("authority %v (this node: %v)\n", res.Authority, res.IsAuthority)
+			return nil
+		},
+	}
 	return cmd
 }
@@ -2,9 +2,14 @@ package cli
 
 import (
 	"context"
+	"encoding/csv"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
+	"os"
+	"strconv"
+	"time"
 
 	"github.com/Ansh1902396/chain"
 	"github.com/Ansh1902396/node/rpc"
@@ -18,15 +23,17 @@ func txCmd(ctx context.Context) *cobra.Command {
 		Use:   "tx",
 		Short: "Manages transactions on the blockchain",
 	}
+	addNodeFlag(cmd)
 	cmd.AddCommand(
-		txSignCmd(ctx), txSendCmd(ctx), txSearchCmd(ctx),
+		txSignCmd(ctx), txBatchCmd(ctx), txSendCmd(ctx), txStatusCmd(ctx),
+		txSearchCmd(ctx),
 		txProveCmd(ctx), txVerifyCmd(ctx),
 	)
 	return cmd
 }
 
 func grpcTxSign(
-	ctx context.Context, addr, from, to string, value uint64, ownerPass string,
+	ctx context.Context, addr string, req *rpc.TxSignReq,
 ) ([]byte, error) {
 	conn, err := grpc.NewClient(
 		addr, grpc.WithTransportCredentials(insecure.NewCredentials()),
@@ -36,7 +43,6 @@ func grpcTxSign(
 	}
 	defer conn.Close()
 	cln := rpc.NewTxClient(conn)
-	req := &rpc.TxSignReq{From: from, To: to, Value: value, Password: ownerPass}
 	res, err := cln.TxSign(ctx, req)
 	if err != nil {
 		return nil, err
@@ -54,7 +60,22 @@ func txSignCmd(ctx context.Context) *cobra.Command {
 			to, _ := cmd.Flags().GetString("to")
 			value, _ := cmd.Flags().GetUint64("value")
 			ownerPass, _ := cmd.Flags().GetString("ownerpass")
-			jtx, err := grpcTxSign(ctx, addr, from, to, value, ownerPass)
+			memo, _ := cmd.Flags().GetString("memo")
+			notBeforeStr, _ := cmd.Flags().GetString("notbefore")
+			notBefore, err := parseTxBound(notBeforeStr)
+			if err != nil {
+				return err
+			}
+			validUntilStr, _ := cmd.Flags().GetString("validuntil")
+			validUntil, err := parseTxBound(validUntilStr)
+			if err != nil {
+				return err
+			}
+			req := &rpc.TxSignReq{
+				From: from, To: to, Value: value, Password: ownerPass,
+				Data: []byte(memo), NotBefore: notBefore, ValidUntil: validUntil,
+			}
+			jtx, err := grpcTxSign(ctx, addr, req)
 			if err != nil {
 				return err
 			}
@@ -70,6 +91,182 @@ func txSignCmd(ctx context.Context) *cobra.Command {
 	_ = cmd.MarkFlagRequired("value")
 	cmd.Flags().String("ownerpass", "", "owner account password")
 	_ = cmd.MarkFlagRequired("ownerpass")
+	cmd.Flags().String("memo", "", "transaction memo or reference")
+	cmd.Flags().String(
+		"notbefore", "", "block height, RFC 3339 time, or duration from now",
+	)
+	cmd.Flags().String(
+		"validuntil", "", "block height, RFC 3339 time, or duration from now",
+	)
+	return cmd
+}
+
+// parseTxBound converts a block height, an RFC 3339 time, or a duration
+// relative to now into a transaction validity bound
+func parseTxBound(bound string) (uint64, error) {
+	if len(bound) == 0 {
+		return 0, nil
+	}
+	height, err := strconv.ParseUint(bound, 10, 64)
+	if err == nil {
+		return height, nil
+	}
+	tm, err := time.Parse(time.RFC3339, bound)
+	if err == nil {
+		return uint64(tm.Unix()), nil
+	}
+	dur, err := time.ParseDuration(bound)
+	if err == nil {
+		return uint64(time.Now().Add(dur).Unix()), nil
+	}
+	return 0, fmt.Errorf(
+		"expected block height, RFC 3339 time, or duration, got %v", bound,
+	)
+}
+
+// parseSearchTime converts an RFC 3339 time or a duration before now into
+// Unix seconds
+func parseSearchTime(str string) (uint64, error) {
+	if len(str) == 0 {
+		return 0, nil
+	}
+	tm, err := time.Parse(time.RFC3339, str)
+	if err == nil {
+		return uint64(tm.Unix()), nil
+	}
+	dur, err := time.ParseDuration(str)
+	if err == nil {
+		return uint64(time.Now().Add(-dur).Unix()), nil
+	}
+	return 0, fmt.Errorf("expected RFC 3339 time or duration, got %v", str)
+}
+
+// searchOpts are the block range, time range, and paging options of the
+// block and transaction searches
+type searchOpts struct {
+	fromNumber, toNumber uint64
+	since, until         uint64
+	limit, offset        uint64
+	cursor               string
+	desc                 bool
+}
+
+func addRangeFlags(cmd *cobra.Command) {
+	cmd.Flags().Uint64("from-block", 0, "first block number of the range")
+	cmd.Flags().Uint64("to-block", 0, "last block number of the range")
+	cmd.Flags().String("since", "", "blocks from RFC 3339 time or duration ago")
+	cmd.Flags().String("until", "", "blocks before RFC 3339 time or duration ago")
+}
+
+func readRangeFlags(cmd *cobra.Command, opts *searchOpts) error {
+	opts.fromNumber, _ = cmd.Flags().GetUint64("from-block")
+	opts.toNumber, _ = cmd.Flags().GetUint64("to-block")
+	since, _ := cmd.Flags().GetString("since")
+	until, _ := cmd.Flags().GetString("until")
+	var err error
+	opts.since, err = parseSearchTime(since)
+	if err != nil {
+		return err
+	}
+	opts.until, err = parseSearchTime(until)
+	return err
+}
+
+func addSearchFlags(cmd *cobra.Command) {
+	addRangeFlags(cmd)
+	cmd.Flags().Uint64("limit", 100, "maximum number of results up to 1000")
+	cmd.Flags().Uint64("offset", 0, "number of results to skip")
+	cmd.Flags().String("cursor", "", "continue after the cursor of a previous search")
+	cmd.Flags().Bool("desc", false, "newest results first")
+}
+
+func readSearchFlags(cmd *cobra.Command) (searchOpts, error) {
+	var opts searchOpts
+	err := readRangeFlags(cmd, &opts)
+	if err != nil {
+		return searchOpts{}, err
+	}
+	opts.limit, _ = cmd.Flags().GetUint64("limit")
+	opts.offset, _ = cmd.Flags().GetUint64("offset")
+	opts.cursor, _ = cmd.Flags().GetString("cursor")
+	opts.desc, _ = cmd.Flags().GetBool("desc")
+	return opts, nil
+}
+
+// readBatchCSV reads recipient,amount rows. A leading header row is skipped
+func readBatchCSV(path string) ([]*rpc.Transfer, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	rd := csv.NewReader(file)
+	rd.FieldsPerRecord = 2
+	rd.TrimLeadingSpace = true
+	rows, err := rd.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	batch := make([]*rpc.Transfer, 0, len(rows))
+	for i, row := range rows {
+		if i == 0 && csvHeader(row) {
+			continue
+		}
+		value, err := strconv.ParseUint(row[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("csv line %d: invalid amount %v", i+1, row[1])
+		}
+		batch = append(batch, &rpc.Transfer{To: row[0], Value: value})
+	}
+	if len(batch) == 0 {
+		return nil, fmt.Errorf("csv %v: no transfers", path)
+	}
+	return batch, nil
+}
+
+// csvHeader reports whether a row holds neither a recipient address nor an
+// amount, as the optional header of a batch CSV does
+func csvHeader(row []string) bool {
+	_, err := strconv.ParseUint(row[1], 10, 64)
+	if err == nil {
+		return false
+	}
+	_, err = hex.DecodeString(row[0])
+	return err != nil || len(row[0]) != 64
+}
+
+func txBatchCmd(ctx context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "batch",
+		Short: "Signs a batch of transfers from a CSV file of recipient,amount rows",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			addr, _ := cmd.Flags().GetString("node")
+			from, _ := cmd.Flags().GetString("from")
+			path, _ := cmd.Flags().GetString("csv")
+			ownerPass, _ := cmd.Flags().GetString("ownerpass")
+			memo, _ := cmd.Flags().GetString("memo")
+			batch, err := readBatchCSV(path)
+			if err != nil {
+				return err
+			}
+			req := &rpc.TxSignReq{
+				From: from, Password: ownerPass, Data: []byte(memo), Batch: batch,
+			}
+			jtx, err := grpcTxSign(ctx, addr, req)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("%s\n", jtx)
+			return nil
+		},
+	}
+	cmd.Flags().String("from", "", "sender address")
+	_ = cmd.MarkFlagRequired("from")
+	cmd.Flags().String("csv", "", "CSV file of recipient,amount rows")
+	_ = cmd.MarkFlagRequired("csv")
+	cmd.Flags().String("ownerpass", "", "owner account password")
+	_ = cmd.MarkFlagRequired("ownerpass")
+	cmd.Flags().String("memo", "", "transaction memo or reference")
 	return cmd
 }
 
@@ -102,17 +299,125 @@ func txSendCmd(ctx context.Context) *cobra.Command {
 				return err
 			}
 			fmt.Printf("tx %s\n", hash)
+			wait, _ := cmd.Flags().GetBool("wait")
+			if !wait {
+				return nil
+			}
+			timeout, _ := cmd.Flags().GetDuration("timeout")
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			rcps, closeRcps, err := grpcTxWait(ctx, addr, hash)
+			if err != nil {
+				return err
+			}
+			defer closeRcps()
+			for err, rcp := range rcps {
+				if err != nil {
+					return err
+				}
+				fmt.Printf("%v\n", rcp)
+			}
 			return nil
 		},
 	}
 	cmd.Flags().String("sigtx", "", "signed encoded transaction")
 	_ = cmd.MarkFlagRequired("sigtx")
+	cmd.Flags().Bool("wait", false, "wait until the transaction is included in a block")
+	cmd.Flags().Duration("timeout", time.Minute, "maximum time to wait for inclusion")
+	return cmd
+}
+
+func grpcTxStatus(ctx context.Context, addr, hash string) (chain.Receipt, error) {
+	conn, err := grpc.NewClient(
+		addr, grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		return chain.Receipt{}, err
+	}
+	defer conn.Close()
+	cln := rpc.NewTxClient(conn)
+	req := &rpc.TxStatusReq{Hash: hash}
+	res, err := cln.TxStatus(ctx, req)
+	if err != nil {
+		return chain.Receipt{}, err
+	}
+	var rcp chain.Receipt
+	err = json.Unmarshal(res.Receipt, &rcp)
+	return rcp, err
+}
+
+func grpcTxWait(
+	ctx context.Context, addr, hash string,
+) (func(yield func(err error, rcp chain.Receipt) bool), func(), error) {
+	conn, err := grpc.NewClient(
+		addr, grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	close := func() {
+		conn.Close()
+	}
+	cln := rpc.NewTxClient(conn)
+	req := &rpc.TxWaitReq{Hash: hash}
+	stream, err := cln.TxWait(ctx, req)
+	if err != nil {
+		return nil, nil, err
+	}
+	more := true
+	rcps := func(yield func(err error, rcp chain.Receipt) bool) {
+		for more {
+			res, err := stream.Recv()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				yield(err, chain.Receipt{})
+				return
+			}
+			var rcp chain.Receipt
+			err = json.Unmarshal(res.Receipt, &rcp)
+			if err != nil {
+				yield(err, chain.Receipt{})
+				return
+			}
+			more = yield(nil, rcp)
+		}
+	}
+	return rcps, close, nil
+}
+
+func txStatusCmd(ctx context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Returns the receipt of a transaction: pending, included, rejected, or expired",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			addr, _ := cmd.Flags().GetString("node")
+			hash, _ := cmd.Flags().GetString("hash")
+			rcp, err := grpcTxStatus(ctx, addr, hash)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("%v\n", rcp)
+			return nil
+		},
+	}
+	cmd.Flags().String("hash", "", "transaction hash")
+	_ = cmd.MarkFlagRequired("hash")
 	return cmd
 }
 
+// searchTxRes is a found transaction with the total of matches and the
+// cursor of the transaction
+type searchTxRes struct {
+	chain.SearchTx
+	total  uint64
+	cursor string
+}
+
 func grpcTxSearch(
-	ctx context.Context, addr, hash, from, to, account string,
-) (func(yeild func(err error, tx chain.SearchTx) bool), func(), error) {
+	ctx context.Context, addr string, req *rpc.TxSearchReq,
+) (func(yeild func(err error, tx searchTxRes) bool), func(), error) {
 	conn, err := grpc.NewClient(
 		addr, grpc.WithTransportCredentials(insecure.NewCredentials()),
 	)
@@ -123,26 +428,25 @@ func grpcTxSearch(
 		conn.Close()
 	}
 	cln := rpc.NewTxClient(conn)
-	req := &rpc.TxSearchReq{Hash: hash, From: from, To: to, Account: account}
 	stream, err := cln.TxSearch(ctx, req)
 	if err != nil {
 		return nil, nil, err
 	}
 	more := true
-	txs := func(yield func(err error, tx chain.SearchTx) bool) {
+	txs := func(yield func(err error, tx searchTxRes) bool) {
 		for more {
 			res, err := stream.Recv()
 			if err == io.EOF {
 				return
 			}
 			if err != nil {
-				yield(err, chain.SearchTx{})
+				yield(err, searchTxRes{})
 				return
 			}
-			var tx chain.SearchTx
-			err = json.Unmarshal(res.Tx, &tx)
+			tx := searchTxRes{total: res.Total, cursor: res.Cursor}
+			err = json.Unmarshal(res.Tx, &tx.SearchTx)
 			if err != nil {
-				yield(err, chain.SearchTx{})
+				yield(err, searchTxRes{})
 				return
 			}
 			more = yield(nil, tx)
@@ -154,32 +458,45 @@ func grpcTxSearch(
 func txSearchCmd(ctx context.Context) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "search",
-		Short: "Searches transactions by the transaction hash, from, to, and account address",
+		Short: "Searches transactions by the transaction hash, from, to, account address, memo, block range, and time range",
 		RunE: func(cmd *cobra.Command, _ []string) error {
 			addr, _ := cmd.Flags().GetString("node")
 			hash, _ := cmd.Flags().GetString("hash")
 			from, _ := cmd.Flags().GetString("from")
 			to, _ := cmd.Flags().GetString("to")
 			account, _ := cmd.Flags().GetString("account")
-			txs, closeTxs, err := grpcTxSearch(ctx, addr, hash, from, to, account)
+			memo, _ := cmd.Flags().GetString("memo")
+			opts, err := readSearchFlags(cmd)
+			if err != nil {
+				return err
+			}
+			req := &rpc.TxSearchReq{
+				Hash: hash, From: from, To: to, Account: account, Memo: memo,
+				FromNumber: opts.fromNumber, ToNumber: opts.toNumber,
+				Since: opts.since, Until: opts.until, Limit: opts.limit,
+				Offset: opts.offset, Cursor: opts.cursor, Desc: opts.desc,
+			}
+			txs, closeTxs, err := grpcTxSearch(ctx, addr, req)
 			if err != nil {
 				return err
 			}
 			defer closeTxs()
-			found := false
+			var last *searchTxRes
 			for err, tx := range txs {
 				if err != nil {
 					return err
 				}
-				found = true
+				last = &tx
 				fmt.Printf("blk %s\n", tx.BlockHash)
 				fmt.Printf("mrk %s\n", tx.MerkleRoot)
 				fmt.Printf("tx  %s\n", tx.Hash())
-				fmt.Printf("%v\n", tx)
+				fmt.Printf("%v\n", tx.SearchTx)
 			}
-			if !found {
+			if last == nil {
 				fmt.Println("no transactions found")
+				return nil
 			}
+			fmt.Printf("total %d   cursor %s\n", last.total, last.cursor)
 			return nil
 		},
 	}
@@ -187,7 +504,8 @@ func txSearchCmd(ctx context.Context) *cobra.Command {
 	cmd.Flags().String("from", "", "sender address")
 	cmd.Flags().String("to", "", "recipient address")
 	cmd.Flags().String("account", "", "involved account address")
-	cmd.MarkFlagsOneRequired("hash", "from", "to", "account")
+	cmd.Flags().String("memo", "", "transaction memo prefix")
+	addSearchFlags(cmd)
 	return cmd
 }
 
@@ -0,0 +1,170 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/Ansh1902396/chain"
+	"github.com/Ansh1902396/node/rpc"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func webhookCmd(ctx context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "webhook",
+		Short: "Manages webhooks that receive the selected events from the node",
+	}
+	addNodeFlag(cmd)
+	cmd.AddCommand(webhookAddCmd(ctx), webhookListCmd(ctx), webhookRemoveCmd(ctx))
+	return cmd
+}
+
+func grpcWebhookCreate(
+	ctx context.Context, addr string, req *rpc.WebhookCreateReq,
+) (chain.Webhook, error) {
+	conn, err := grpc.NewClient(
+		addr, grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		return chain.Webhook{}, err
+	}
+	defer conn.Close()
+	cln := rpc.NewNodeClient(conn)
+	res, err := cln.WebhookCreate(ctx, req)
+	if err != nil {
+		return chain.Webhook{}, err
+	}
+	var hook chain.Webhook
+	err = json.Unmarshal(res.Webhook, &hook)
+	return hook, err
+}
+
+func webhookAddCmd(ctx context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add",
+		Short: "Registers a webhook that receives signed POST requests of matching events",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			addr, _ := cmd.Flags().GetString("node")
+			url, _ := cmd.Flags().GetString("url")
+			secret, _ := cmd.Flags().GetString("secret")
+			filter, err := readEventFilter(cmd)
+			if err != nil {
+				return err
+			}
+			req := &rpc.WebhookCreateReq{URL: url, Secret: secret, Filter: filter}
+			hook, err := grpcWebhookCreate(ctx, addr, req)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("hook %v\n", hook.ID)
+			fmt.Printf("sec %v\n", hook.Secret)
+			return nil
+		},
+	}
+	cmd.Flags().String("url", "", "HTTP or HTTPS endpoint of the webhook")
+	_ = cmd.MarkFlagRequired("url")
+	cmd.Flags().String("secret", "", "HMAC secret of the signatures (default random)")
+	addEventFilterFlags(cmd)
+	return cmd
+}
+
+func grpcWebhookList(ctx context.Context, addr string) (
+	func(yield func(err error, hook chain.Webhook) bool), func(), error,
+) {
+	conn, err := grpc.NewClient(
+		addr, grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	close := func() {
+		conn.Close()
+	}
+	cln := rpc.NewNodeClient(conn)
+	stream, err := cln.WebhookList(ctx, &rpc.WebhookListReq{})
+	if err != nil {
+		return nil, nil, err
+	}
+	more := true
+	hooks := func(yield func(err error, hook chain.Webhook) bool) {
+		for more {
+			res, err := stream.Recv()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				yield(err, chain.Webhook{})
+				return
+			}
+			var hook chain.Webhook
+			err = json.Unmarshal(res.Webhook, &hook)
+			if err != nil {
+				yield(err, chain.Webhook{})
+				return
+			}
+			more = yield(nil, hook)
+		}
+	}
+	return hooks, close, nil
+}
+
+func webhookListCmd(ctx context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "Lists the webhooks with their delivery status",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			addr, _ := cmd.Flags().GetString("node")
+			hooks, closeHooks, err := grpcWebhookList(ctx, addr)
+			if err != nil {
+				return err
+			}
+			defer closeHooks()
+			for err, hook := range hooks {
+				if err != nil {
+					return err
+				}
+				fmt.Printf("%v\n", hook)
+			}
+			return nil
+		},
+	}
+	return cmd
+}
+
+func grpcWebhookDelete(ctx context.Context, addr, id string) error {
+	conn, err := grpc.NewClient(
+		addr, grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	cln := rpc.NewNodeClient(conn)
+	req := &rpc.WebhookDeleteReq{ID: id}
+	_, err = cln.WebhookDelete(ctx, req)
+	return err
+}
+
+func webhookRemoveCmd(ctx context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "remove",
+		Short: "Removes a webhook",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			addr, _ := cmd.Flags().GetString("node")
+			id, _ := cmd.Flags().GetString("id")
+			err := grpcWebhookDelete(ctx, addr, id)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("hook %v removed\n", id)
+			return nil
+		},
+	}
+	cmd.Flags().String("id", "", "webhook id")
+	_ = cmd.MarkFlagRequired("id")
+	return cmd
+}
@@ -0,0 +1,121 @@
+#!/bin/bash
+
+# Cross-chain atomic swap between two independent networks using HTLCs
+#
+# Starts two bootstrap nodes running separate chains. Alice owns funds on
+# chain A, Bob owns funds on chain B. Alice locks value for Bob on chain A
+# under a fresh secret, Bob locks value for Alice on chain B under the same
+# hash lock with a shorter time lock, Alice claims on chain B revealing the
+# secret, and Bob claims on chain A with the revealed secret.
+
+SCRIPT_DIR="$(cd "$(dirname "${BASH_SOURCE[0]}")" && pwd)"
+BCN_BINARY="$SCRIPT_DIR/bcn"
+
+NODE_A="localhost:1122"
+NODE_B="localhost:2122"
+PASS="password123"
+SWAP_DIR="${SWAP_DIR:-$(mktemp -d)}"
+
+GREEN='\033[0;32m'
+BLUE='\033[0;34m'
+CYAN='\033[0;36m'
+NC='\033[0m'
+
+print_step() {
+    echo -e "${BLUE}[STEP]${NC} $1"
+}
+
+print_result() {
+    echo -e "${CYAN}[RESULT]${NC} $1"
+}
+
+print_status() {
+    echo -e "${GREEN}[INFO]${NC} $1"
+}
+
+if [ ! -f "$BCN_BINARY" ]; then
+    echo "❌ Blockchain binary not found at $BCN_BINARY"
+    echo "Run 'go build -o bcn .' first"
+    exit 1
+fi
+
+PIDS=()
+cleanup() {
+    for pid in "${PIDS[@]}"; do
+        kill "$pid" 2>/dev/null
+    done
+}
+trap cleanup EXIT
+
+# start_chain name addr starts a bootstrap node of a new chain
+start_chain() {
+    local name="$1"
+    local addr="$2"
+    cd "$SWAP_DIR" || exit 1
+    "$BCN_BINARY" node start --node "$addr" --bootstrap --chain "$name" \
+        --authpass "$PASS" --ownerpass "$PASS" --balance 1000 \
+        > "$name.log" 2>&1 < /dev/null &
+    PIDS+=($!)
+    cd - > /dev/null || exit 1
+}
+
+# chain_owner addr prints the funded owner, the only keystore account with a
+# balance
+chain_owner() {
+    local addr="$1"
+    local port="${addr##*:}"
+    for acc in $(ls "$SWAP_DIR/.keystore$port"); do
+        if "$BCN_BINARY" account balance --node "$addr" --account "$acc" \
+            > /dev/null 2>&1; then
+            echo "$acc"
+        fi
+    done
+}
+
+# field key output extracts the value printed after the key
+field() {
+    echo "$2" | awk -v key="$1" '$1 == key { print $2; exit }'
+}
+
+print_step "1. Starting chain A at $NODE_A and chain B at $NODE_B in $SWAP_DIR"
+start_chain chainA "$NODE_A"
+start_chain chainB "$NODE_B"
+sleep 3
+ALICE_A=$(chain_owner "$NODE_A")
+BOB_B=$(chain_owner "$NODE_B")
+BOB_A=$("$BCN_BINARY" account create --node "$NODE_A" --ownerpass "$PASS" | awk '{print $2}')
+ALICE_B=$("$BCN_BINARY" account create --node "$NODE_B" --ownerpass "$PASS" | awk '{print $2}')
+print_status "Alice: $ALICE_A on A, $ALICE_B on B"
+print_status "Bob:   $BOB_A on A, $BOB_B on B"
+
+print_step "2. Alice locks 100 for Bob on chain A with a new secret"
+OUT=$("$BCN_BINARY" htlc lock --node "$NODE_A" --from "$ALICE_A" --to "$BOB_A" \
+    --value 100 --timelock 10m --ownerpass "$PASS" --wait) || exit 1
+echo "$OUT"
+PREIMAGE=$(field pre "$OUT")
+HASHLOCK=$(field hlk "$OUT")
+LOCK_A=$(field tx "$OUT")
+
+print_step "3. Bob verifies the lock on chain A and locks 50 for Alice on chain B"
+"$BCN_BINARY" htlc show --node "$NODE_A" --lock "$LOCK_A" || exit 1
+OUT=$("$BCN_BINARY" htlc lock --node "$NODE_B" --from "$BOB_B" --to "$ALICE_B" \
+    --value 50 --hashlock "$HASHLOCK" --timelock 5m --ownerpass "$PASS" --wait) || exit 1
+echo "$OUT"
+LOCK_B=$(field tx "$OUT")
+
+print_step "4. Alice claims on chain B revealing the secret"
+"$BCN_BINARY" htlc claim --node "$NODE_B" --from "$ALICE_B" --lock "$LOCK_B" \
+    --preimage "$PREIMAGE" --ownerpass "$PASS" --wait || exit 1
+
+print_step "5. Bob learns the secret from chain B and claims on chain A"
+OUT=$("$BCN_BINARY" htlc show --node "$NODE_B" --lock "$LOCK_B") || exit 1
+echo "$OUT"
+REVEALED=$(echo "$OUT" | awk '{ for (i = 1; i < NF; i++) if ($i == "pre") print $(i + 1) }')
+"$BCN_BINARY" htlc claim --node "$NODE_A" --from "$BOB_A" --lock "$LOCK_A" \
+    --preimage "$REVEALED" --ownerpass "$PASS" --wait || exit 1
+
+print_step "6. Final balances"
+print_result "$("$BCN_BINARY" account balance --node "$NODE_A" --account "$ALICE_A")"
+print_result "$("$BCN_BINARY" account balance --node "$NODE_A" --account "$BOB_A")"
+print_result "$("$BCN_BINARY" account balance --node "$NODE_B" --account "$BOB_B")"
+print_result "$("$BCN_BINARY" account balance --node "$NODE_B" --account "$ALICE_B")"
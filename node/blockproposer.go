@@ -2,36 +2,52 @@ package node
 
 import (
 	"context"
-	"crypto/rand"
 	"encoding/json"
-	"fmt"
-	"math/big"
+	"errors"
+	"log/slog"
 	"sync"
 	"time"
 
 	"github.com/Ansh1902396/chain"
 	"github.com/Ansh1902396/node/rpc"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
 )
 
+// minWaitCheck bounds how often the proposer checks the waiting time of the
+// pending transactions
+const minWaitCheck = 100 * time.Millisecond
+
+// BlockSchedule paces the block proposer. Blocks are proposed every Interval
+// when transactions are pending, or empty as a heartbeat when Empty is set. A
+// non-zero MaxWait forces a block before the interval once a pending
+// transaction has waited that long
+type BlockSchedule struct {
+	Interval time.Duration
+	Empty    bool
+	MaxWait  time.Duration
+}
+
 type BlockProposer struct {
 	ctx        context.Context
 	wg         *sync.WaitGroup
 	authority  chain.Account
 	state      *chain.State
 	blkRelayer rpc.BlockRelayer
+	clock      chain.Clock
+	traces     *Traces
+	log        *slog.Logger
+	proposed   uint64
 }
 
 func NewBlockProposer(
 	ctx context.Context, wg *sync.WaitGroup, blkRelayer rpc.BlockRelayer,
+	clock chain.Clock, traces *Traces, log *slog.Logger,
 ) *BlockProposer {
-	return &BlockProposer{ctx: ctx, wg: wg, blkRelayer: blkRelayer}
-}
-
-func randPeriod(maxPeriod time.Duration) time.Duration {
-	minPeriod := maxPeriod / 2
-	randSpan, _ := rand.Int(rand.Reader, big.NewInt(int64(maxPeriod)))
-	return time.Duration(randSpan.Int64()) + minPeriod
+	return &BlockProposer{
+		ctx: ctx, wg: wg, blkRelayer: blkRelayer, clock: clock, traces: traces,
+		log: log,
+	}
 }
 
 func (p *BlockProposer) SetAuthority(authority chain.Account) {
@@ -42,43 +58,103 @@ func (p *BlockProposer) SetState(state *chain.State) {
 	p.state = state
 }
 
-func (p *BlockProposer) ProposeBlocks(maxPeriod time.Duration) {
+// ProposeBlocks proposes a block every interval of the schedule, and as
+// soon as a pending transaction has waited the max wait of the schedule
+func (p *BlockProposer) ProposeBlocks(schedule BlockSchedule) {
 	defer p.wg.Done()
 
-	randPropose := time.NewTimer(randPeriod(maxPeriod))
+	tick := time.NewTimer(schedule.Interval)
+	defer tick.Stop()
+	var chWait <-chan time.Time
+	if schedule.MaxWait > 0 {
+		wait := time.NewTicker(max(schedule.MaxWait/4, minWaitCheck))
+		defer wait.Stop()
+		chWait = wait.C
+	}
 	for {
 		select {
 		case <-p.ctx.Done():
-			randPropose.Stop()
 			return
-		case <-randPropose.C:
-			randPropose.Reset(randPeriod(maxPeriod))
-			clone := p.state.Clone()
-			blk, err := clone.CreateBlock(p.authority)
-			if err != nil {
+		case <-tick.C:
+			tick.Reset(schedule.Interval)
+			p.proposeBlock(schedule.Empty)
+		case <-chWait:
+			since, exist := p.state.PendingSince()
+			if !exist || p.clock.Now().Sub(since) < schedule.MaxWait {
 				continue
 			}
-
-			if len(blk.Txs) == 0 {
-				continue
-			}
-			clone = p.state.Clone()
-			err = clone.ApplyBlock(blk)
-			if err != nil {
-				fmt.Println(err)
-				continue
-			}
-			if p.blkRelayer != nil {
-				p.blkRelayer.RelayBlock(blk)
+			if p.proposeBlock(false) {
+				tick.Reset(schedule.Interval)
 			}
+		}
+	}
+}
 
-			fmt.Printf("==> Block Propose \n%v\n", blk)
+// proposeBlock creates a block of the pending transactions, or an empty
+// block when allowed, and relays it. It reports whether a block was proposed.
+// No block is proposed until the previous one is applied, so that the next
+// block does not repeat its transactions
+func (p *BlockProposer) proposeBlock(empty bool) bool {
+	if p.state.LastBlock().Number < p.proposed {
+		return false
+	}
+	start := p.clock.Now()
+	p.state.PurgeExpired(start)
+	clone := p.state.Clone()
+	blk, err := clone.CreateBlock(p.authority, empty)
+	if errors.Is(err, chain.ErrBlockTime) {
+		p.log.Warn("Block propose", "err", err)
+		return false
+	}
+	if err != nil {
+		return false
+	}
+	span := p.traceBlock(start, blk)
+	clone = p.state.Clone()
+	err = clone.ApplyBlock(blk)
+	if err != nil {
+		span.end(err)
+		p.log.Error("Block propose", "block", blk.Number, "err", err)
+		return false
+	}
+	span.end(nil)
+	p.proposed = blk.Number
+	if p.blkRelayer != nil {
+		p.blkRelayer.RelayBlock(blk)
+	}
+
+	p.log.Info(
+		"Block proposed", "block", blk.Number, "txs", len(blk.Txs),
+		"hash", blk.Hash().String(),
+	)
+	return true
+}
+
+// traceBlock starts the trace of a block from the time its creation started
+// and adds the inclusion of the block to the traces of its transactions
+func (p *BlockProposer) traceBlock(start time.Time, blk chain.SigBlock) msgSpan {
+	ctx, span := tracer.Start(
+		p.ctx, "block.propose", rpc.BlockSpanAttrs(blk), trace.WithTimestamp(start),
+	)
+	p.traces.SaveTrace(ctx, blk.Hash())
+	for _, tx := range blk.Txs {
+		txCtx, exist := p.traces.TraceContext(p.ctx, tx.Hash())
+		if !exist {
+			continue
 		}
+		_, txSpan := tracer.Start(
+			txCtx, "tx.include", rpc.TxSpanAttrs(tx), rpc.BlockSpanAttrs(blk),
+			trace.WithLinks(trace.LinkFromContext(ctx)),
+		)
+		txSpan.End()
+		span.AddLink(trace.LinkFromContext(txCtx))
 	}
+	return msgSpan{ctx: ctx, span: span}
 }
 
 var GRPCBlockRelay GRPCMsgRelay[chain.SigBlock] = func(
 	ctx context.Context, conn *grpc.ClientConn, chRelay chan chain.SigBlock,
+	traces *Traces, log *slog.Logger,
 ) error {
 	cln := rpc.NewBlockClient(conn)
 	stream, err := cln.BlockReceive(ctx)
@@ -101,14 +177,21 @@ var GRPCBlockRelay GRPCMsgRelay[chain.SigBlock] = func(
 			jblk, err := json.Marshal(blk)
 
 			if err != nil {
-				fmt.Println(err)
+				log.Error("Block encode", "block", blk.Number, "err", err)
 				continue
 			}
-			req := &rpc.BlockReceiveReq{Block: jblk}
+			span := relaySpan(
+				ctx, traces, "block.relay", blk.Hash(), conn.Target(),
+				rpc.BlockSpanAttrs(blk),
+			)
+			req := &rpc.BlockReceiveReq{
+				Block: jblk, Trace: rpc.InjectTrace(span.ctx),
+			}
 
 			err = stream.Send(req)
+			span.end(err)
 			if err != nil {
-				fmt.Println(err)
+				log.Warn("Block relay", "block", blk.Number, "err", err)
 				continue
 			}
 		}
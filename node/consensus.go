@@ -0,0 +1,550 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Ansh1902396/chain"
+	"github.com/Ansh1902396/node/rpc"
+)
+
+// The timeouts of the consensus steps grow with the round, so that the
+// validators eventually spend enough time in the same round to agree
+const (
+	timeoutPropose      = 3 * time.Second
+	timeoutProposeDelta = time.Second
+	timeoutVote         = time.Second
+	timeoutVoteDelta    = 500 * time.Millisecond
+	timeoutCommit       = 3 * time.Second
+	maxFutureMsgs       = 1000
+	maxRoundsAhead      = 100
+)
+
+type consensusStep int
+
+const (
+	stepPropose consensusStep = iota
+	stepPrevote
+	stepPrecommit
+	stepCommit
+)
+
+type voteKey struct {
+	round uint32
+	typ   chain.VoteType
+}
+
+// Consensus finalizes the blocks with the validators of the genesis. In each
+// round of a height the proposer of the round proposes a block, then the
+// validators prevote and precommit the block or no block. A block is
+// committed once more than two thirds of the validators precommit the block.
+// A validator that precommits a block is locked on the block and prevotes no
+// other block, unless more than two thirds of the validators prevote another
+// block in a later round
+type Consensus struct {
+	ctx        context.Context
+	wg         *sync.WaitGroup
+	validator  chain.Account
+	state      *chain.State
+	blkRelayer rpc.BlockRelayer
+	msgRelayer rpc.ConsensusRelayer
+	clock      chain.Clock
+	log        *slog.Logger
+	chMsg      chan chain.ConsensusMsg
+
+	schedule    BlockSchedule
+	height      uint64
+	round       uint32
+	step        consensusStep
+	heightStart time.Time
+	roundStart  time.Time
+	quorumSince time.Time
+	proposed    bool
+	sent        []chain.ConsensusMsg
+	resent      time.Time
+	proposals   map[uint32]chain.SigProposal
+	votes       map[voteKey]map[chain.Address]chain.SigVote
+	lockedRound int32
+	lockedBlock chain.SigBlock
+	validRound  int32
+	validBlock  chain.SigBlock
+	committed   chain.SigBlock
+	commitStart time.Time
+	future      []chain.ConsensusMsg
+}
+
+func NewConsensus(
+	ctx context.Context, wg *sync.WaitGroup, blkRelayer rpc.BlockRelayer,
+	msgRelayer rpc.ConsensusRelayer, clock chain.Clock, log *slog.Logger,
+) *Consensus {
+	return &Consensus{
+		ctx: ctx, wg: wg, blkRelayer: blkRelayer, msgRelayer: msgRelayer,
+		clock: clock, log: log, chMsg: make(chan chain.ConsensusMsg, 1000),
+	}
+}
+
+func (c *Consensus) SetValidator(validator chain.Account) {
+	c.validator = validator
+}
+
+func (c *Consensus) SetState(state *chain.State) {
+	c.state = state
+}
+
+// HandleConsensus queues a proposal or a vote received from a validator
+func (c *Consensus) HandleConsensus(msg chain.ConsensusMsg) {
+	select {
+	case c.chMsg <- msg:
+	case <-c.ctx.Done():
+	}
+}
+
+// FinalizeBlocks runs the rounds of the consensus until the node stops.
+// Blocks are due on the schedule as for the block proposer
+func (c *Consensus) FinalizeBlocks(schedule BlockSchedule) {
+	defer c.wg.Done()
+	c.schedule = schedule
+	c.newHeight()
+	tick := time.NewTicker(minWaitCheck)
+	defer tick.Stop()
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case msg := <-c.chMsg:
+			c.receive(msg)
+		case <-tick.C:
+		}
+		c.advance()
+	}
+}
+
+// advance starts the next height once the committed block is applied, then
+// takes the steps of the consensus
+func (c *Consensus) advance() {
+	// The committed block is applied by the block service
+	if c.state.LastBlock().Number >= c.height {
+		c.newHeight()
+	}
+	c.update()
+}
+
+func (c *Consensus) newHeight() {
+	c.height = c.state.LastBlock().Number + 1
+	c.heightStart = c.clock.Now()
+	c.proposals = make(map[uint32]chain.SigProposal)
+	c.votes = make(map[voteKey]map[chain.Address]chain.SigVote)
+	c.lockedRound, c.lockedBlock = -1, chain.SigBlock{}
+	c.validRound, c.validBlock = -1, chain.SigBlock{}
+	c.startRound(0)
+	future := c.future
+	c.future = nil
+	for _, msg := range future {
+		c.receive(msg)
+	}
+}
+
+// startRound starts a round. The first round of a height starts once a block
+// is due
+func (c *Consensus) startRound(round uint32) {
+	if round > 0 {
+		c.log.Debug("Consensus round", "block", c.height, "round", round)
+	}
+	c.round = round
+	c.step = stepPropose
+	c.roundStart = time.Time{}
+	if round > 0 {
+		c.roundStart = c.clock.Now()
+	}
+	c.quorumSince = time.Time{}
+	c.proposed = false
+	c.sent = nil
+}
+
+// blockDue reports whether a block is due on the schedule
+func (c *Consensus) blockDue(now time.Time) bool {
+	if now.Sub(c.heightStart) >= c.schedule.Interval &&
+		(c.schedule.Empty || c.state.Pending.TxCount() > 0) {
+		return true
+	}
+	since, exist := c.state.PendingSince()
+	return c.schedule.MaxWait > 0 && exist &&
+		now.Sub(since) >= c.schedule.MaxWait
+}
+
+func (c *Consensus) proposer(height uint64, round uint32) chain.Address {
+	validators := c.state.Validators()
+	return validators[(height+uint64(round))%uint64(len(validators))]
+}
+
+func (c *Consensus) quorum() int {
+	return chain.Quorum(len(c.state.Validators()))
+}
+
+// receive records a proposal or a vote of the current height signed by the
+// expected validator. Messages of the next height wait for the height
+func (c *Consensus) receive(msg chain.ConsensusMsg) {
+	switch {
+	case msg.Proposal != nil:
+		prop := *msg.Proposal
+		if !c.relevant(prop.Height, prop.Round) {
+			return
+		}
+		valid, err := chain.VerifyProposal(prop)
+		if err != nil || !valid || prop.Proposer != c.proposer(prop.Height, prop.Round) {
+			c.log.Warn(
+				"Proposal rejected", "block", prop.Height, "round", prop.Round,
+				"proposer", prop.Proposer, "err", err,
+			)
+			return
+		}
+		if prop.Height > c.height {
+			c.wait(msg)
+			return
+		}
+		_, exist := c.proposals[prop.Round]
+		if !exist {
+			c.proposals[prop.Round] = prop
+		}
+	case msg.Vote != nil:
+		vote := *msg.Vote
+		if !c.relevant(vote.Height, vote.Round) {
+			return
+		}
+		valid, err := chain.VerifyVote(vote)
+		if err != nil || !valid ||
+			!slices.Contains(c.state.Validators(), vote.Validator) {
+			c.log.Warn(
+				"Vote rejected", "block", vote.Height, "round", vote.Round,
+				"validator", vote.Validator, "err", err,
+			)
+			return
+		}
+		if vote.Height > c.height {
+			c.wait(msg)
+			return
+		}
+		c.addVote(vote)
+	}
+}
+
+// relevant reports whether a message is for a round of the current height
+// that is at most maxRoundsAhead rounds ahead, or for one of the first rounds
+// of the next height
+func (c *Consensus) relevant(height uint64, round uint32) bool {
+	switch height {
+	case c.height:
+		return uint64(round) <= uint64(c.round)+maxRoundsAhead
+	case c.height + 1:
+		return round <= maxRoundsAhead
+	default:
+		return false
+	}
+}
+
+// wait keeps a verified message of the next height until the height starts
+func (c *Consensus) wait(msg chain.ConsensusMsg) {
+	if len(c.future) < maxFutureMsgs {
+		c.future = append(c.future, msg)
+	}
+}
+
+// addVote records the first vote of a validator of each type in each round
+func (c *Consensus) addVote(vote chain.SigVote) {
+	key := voteKey{round: vote.Round, typ: vote.Type}
+	votes, exist := c.votes[key]
+	if !exist {
+		votes = make(map[chain.Address]chain.SigVote)
+		c.votes[key] = votes
+	}
+	_, exist = votes[vote.Validator]
+	if !exist {
+		votes[vote.Validator] = vote
+	}
+}
+
+// count returns the number of votes of a type for the block in the round
+func (c *Consensus) count(
+	round uint32, typ chain.VoteType, blkHash chain.Hash,
+) int {
+	count := 0
+	for _, vote := range c.votes[voteKey{round: round, typ: typ}] {
+		if vote.BlockHash == blkHash {
+			count++
+		}
+	}
+	return count
+}
+
+// update takes the steps of the consensus that the received messages and
+// the elapsed time allow
+func (c *Consensus) update() {
+	if c.step == stepCommit {
+		c.recommit()
+		return
+	}
+	for round, prop := range c.proposals {
+		if c.count(round, chain.Precommit, prop.Block.Hash()) >= c.quorum() {
+			c.commit(prop, round)
+			return
+		}
+	}
+	// The messages relayed before the peers are known, or to a restarted
+	// peer, are lost
+	now := c.clock.Now()
+	if len(c.sent) > 0 && now.Sub(c.resent) >= timeoutVote {
+		c.resent = now
+		for _, msg := range c.sent {
+			c.msgRelayer.RelayConsensus(msg)
+		}
+	}
+	// Skip to a later round that more than a third of the validators joined
+	for _, round := range c.laterRounds() {
+		if c.voters(round) > len(c.state.Validators())/3 {
+			c.startRound(round)
+			break
+		}
+	}
+	if c.count(c.round, chain.Precommit, chain.Hash{}) >= c.quorum() {
+		c.startRound(c.round + 1)
+	}
+
+	switch c.step {
+	case stepPropose:
+		// The round also starts when the other validators started it
+		if c.roundStart.IsZero() {
+			_, exist := c.proposals[c.round]
+			if !exist && c.voters(c.round) == 0 && !c.blockDue(now) {
+				return
+			}
+			c.roundStart = now
+		}
+		if !c.proposed && c.proposer(c.height, c.round) == c.validator.Address() {
+			c.propose()
+		}
+		prop, exist := c.proposals[c.round]
+		if exist {
+			blkHash, decided := c.prevoteFor(prop)
+			if decided {
+				c.vote(chain.Prevote, blkHash)
+				return
+			}
+		}
+		if now.Sub(c.roundStart) >= c.timeout(timeoutPropose, timeoutProposeDelta) {
+			c.vote(chain.Prevote, chain.Hash{})
+		}
+	case stepPrevote:
+		prop, exist := c.proposals[c.round]
+		if exist {
+			blkHash := prop.Block.Hash()
+			if c.count(c.round, chain.Prevote, blkHash) >= c.quorum() {
+				c.lockedRound, c.lockedBlock = int32(c.round), prop.Block
+				c.validRound, c.validBlock = int32(c.round), prop.Block
+				c.vote(chain.Precommit, blkHash)
+				return
+			}
+		}
+		if c.count(c.round, chain.Prevote, chain.Hash{}) >= c.quorum() ||
+			c.quorumTimeout(chain.Prevote, now) {
+			c.vote(chain.Precommit, chain.Hash{})
+		}
+	case stepPrecommit:
+		prop, exist := c.proposals[c.round]
+		if exist && c.count(c.round, chain.Prevote, prop.Block.Hash()) >= c.quorum() {
+			c.validRound, c.validBlock = int32(c.round), prop.Block
+		}
+		if c.quorumTimeout(chain.Precommit, now) {
+			c.startRound(c.round + 1)
+		}
+	}
+}
+
+// timeout returns the timeout of a step in the current round
+func (c *Consensus) timeout(base, delta time.Duration) time.Duration {
+	return base + time.Duration(c.round)*delta
+}
+
+// quorumTimeout reports whether the votes of a quorum of the validators in
+// the current round have not agreed within the vote timeout
+func (c *Consensus) quorumTimeout(typ chain.VoteType, now time.Time) bool {
+	if len(c.votes[voteKey{round: c.round, typ: typ}]) < c.quorum() {
+		return false
+	}
+	if c.quorumSince.IsZero() {
+		c.quorumSince = now
+	}
+	return now.Sub(c.quorumSince) >= c.timeout(timeoutVote, timeoutVoteDelta)
+}
+
+// laterRounds returns the rounds after the current round with votes, latest
+// first
+func (c *Consensus) laterRounds() []uint32 {
+	var rounds []uint32
+	for key := range c.votes {
+		if key.round > c.round && !slices.Contains(rounds, key.round) {
+			rounds = append(rounds, key.round)
+		}
+	}
+	slices.Sort(rounds)
+	slices.Reverse(rounds)
+	return rounds
+}
+
+// voters returns the number of validators that voted in the round
+func (c *Consensus) voters(round uint32) int {
+	voters := make(map[chain.Address]bool)
+	for _, typ := range []chain.VoteType{chain.Prevote, chain.Precommit} {
+		for acc := range c.votes[voteKey{round: round, typ: typ}] {
+			voters[acc] = true
+		}
+	}
+	return len(voters)
+}
+
+// propose proposes the valid block of an earlier round, or a new block of
+// the pending transactions
+func (c *Consensus) propose() {
+	c.proposed = true
+	prop := chain.Proposal{
+		Height: c.height, Round: c.round, POLRound: c.validRound,
+		Block: c.validBlock,
+	}
+	if c.validRound < 0 {
+		c.state.PurgeExpired(c.clock.Now())
+		// A later round proposes an empty block rather than no block
+		empty := c.schedule.Empty || c.round > 0
+		blk, err := c.state.Clone().CreateBlock(c.validator, empty)
+		if errors.Is(err, chain.ErrBlockTime) {
+			c.log.Warn("Block propose", "err", err)
+			return
+		}
+		if err != nil {
+			return
+		}
+		prop.Block = blk
+	}
+	sigProp, err := c.validator.SignProposal(prop)
+	if err != nil {
+		c.log.Error("Block propose", "block", c.height, "err", err)
+		return
+	}
+	c.proposals[c.round] = sigProp
+	c.relay(chain.ConsensusMsg{Proposal: &sigProp})
+	c.log.Info(
+		"Block proposed", "block", c.height, "round", c.round,
+		"txs", len(prop.Block.Txs), "hash", prop.Block.Hash().String(),
+	)
+}
+
+// prevoteFor decides the prevote for the proposal of the round. A proposal
+// of a block prevoted in an earlier round waits for the prevotes of the round
+func (c *Consensus) prevoteFor(prop chain.SigProposal) (chain.Hash, bool) {
+	blk := prop.Block
+	blkHash := blk.Hash()
+	if blk.Number != c.height || blk.Commit != nil {
+		return chain.Hash{}, true
+	}
+	err := c.state.Clone().ApplyProposal(blk)
+	if err != nil {
+		c.log.Warn("Proposal rejected", "block", c.height, "round", c.round, "err", err)
+		return chain.Hash{}, true
+	}
+	locked := c.lockedRound >= 0 && c.lockedBlock.Hash() == blkHash
+	if prop.POLRound < 0 {
+		if c.lockedRound < 0 || locked {
+			return blkHash, true
+		}
+		return chain.Hash{}, true
+	}
+	if prop.POLRound >= int32(c.round) {
+		return chain.Hash{}, true
+	}
+	if c.count(uint32(prop.POLRound), chain.Prevote, blkHash) < c.quorum() {
+		return chain.Hash{}, false
+	}
+	if c.lockedRound <= prop.POLRound || locked {
+		return blkHash, true
+	}
+	return chain.Hash{}, true
+}
+
+// vote signs and relays a prevote or a precommit of the current round for
+// the block, or for no block with the zero hash
+func (c *Consensus) vote(typ chain.VoteType, blkHash chain.Hash) {
+	if typ == chain.Prevote {
+		c.step = stepPrevote
+	} else {
+		c.step = stepPrecommit
+	}
+	c.quorumSince = time.Time{}
+	vote := chain.Vote{
+		Type: typ, Height: c.height, Round: c.round, BlockHash: blkHash,
+	}
+	sigVote, err := c.validator.SignVote(vote)
+	if err != nil {
+		c.log.Error("Consensus vote", "block", c.height, "err", err)
+		return
+	}
+	c.addVote(sigVote)
+	c.relay(chain.ConsensusMsg{Vote: &sigVote})
+	c.log.Debug(
+		"Consensus vote", "block", c.height, "round", c.round, "type", typ,
+		"hash", blkHash.String(),
+	)
+}
+
+// relay relays a message of the validator and keeps it to be relayed again
+// until the round ends
+func (c *Consensus) relay(msg chain.ConsensusMsg) {
+	c.msgRelayer.RelayConsensus(msg)
+	c.sent = append(c.sent, msg)
+	c.resent = c.clock.Now()
+}
+
+// commit attaches the precommits of the validators to the block of the
+// proposal and relays the block to the node and its peers
+func (c *Consensus) commit(prop chain.SigProposal, round uint32) {
+	c.step = stepCommit
+	blk := prop.Block
+	blkHash := blk.Hash()
+	var precommits []chain.SigVote
+	for _, vote := range c.votes[voteKey{round: round, typ: chain.Precommit}] {
+		if vote.BlockHash == blkHash {
+			precommits = append(precommits, vote)
+		}
+	}
+	slices.SortFunc(precommits, func(a, b chain.SigVote) int {
+		return strings.Compare(string(a.Validator), string(b.Validator))
+	})
+	blk.Commit = chain.NewCommit(round, precommits)
+	c.committed, c.commitStart = blk, c.clock.Now()
+	c.blkRelayer.RelayBlock(blk)
+	c.log.Info(
+		"Block committed", "block", blk.Number, "round", round,
+		"sigs", len(precommits), "hash", blkHash.String(),
+	)
+}
+
+// recommit relays the committed block and the messages of the validator again
+// when the block is not applied within the commit timeout. The block may not
+// reach the node, and the peers that missed the precommits stay in the round
+func (c *Consensus) recommit() {
+	now := c.clock.Now()
+	if now.Sub(c.commitStart) < timeoutCommit {
+		return
+	}
+	c.commitStart = now
+	c.log.Warn(
+		"Block commit timeout", "block", c.committed.Number,
+		"hash", c.committed.Hash().String(),
+	)
+	c.blkRelayer.RelayBlock(c.committed)
+	for _, msg := range c.sent {
+		c.msgRelayer.RelayConsensus(msg)
+	}
+}
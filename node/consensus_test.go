@@ -0,0 +1,428 @@
+package node
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Ansh1902396/chain"
+)
+
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+// testMsg is a consensus message relayed from a validator to another
+type testMsg struct {
+	from, to int
+	msg      chain.ConsensusMsg
+}
+
+type delivery int
+
+const (
+	deliver delivery = iota
+	delay
+	drop
+)
+
+// testNet runs the consensus of in-process validators step by step on a
+// fake clock. The filter delivers, delays or drops each relayed message
+type testNet struct {
+	t      *testing.T
+	clock  *testClock
+	vals   []chain.Account
+	nodes  []*Consensus
+	down   map[int]bool
+	queue  []testMsg
+	sent   []testMsg
+	filter func(net *testNet, msg testMsg) delivery
+	// lostBlocks is the number of the next committed blocks that reach no
+	// validator
+	lostBlocks int
+}
+
+// testRelay relays the messages and the committed blocks of a validator
+type testRelay struct {
+	net  *testNet
+	from int
+}
+
+func (r testRelay) RelayConsensus(msg chain.ConsensusMsg) {
+	for to := range r.net.nodes {
+		if to == r.from {
+			continue
+		}
+		tmsg := testMsg{from: r.from, to: to, msg: msg}
+		r.net.queue = append(r.net.queue, tmsg)
+		r.net.sent = append(r.net.sent, tmsg)
+	}
+}
+
+// RelayBlock applies the committed block to the states of all the live
+// validators. The block of another validator is already applied
+func (r testRelay) RelayBlock(blk chain.SigBlock) {
+	if r.net.lostBlocks > 0 {
+		r.net.lostBlocks--
+		return
+	}
+	for i, node := range r.net.nodes {
+		if !r.net.down[i] && node.state.LastBlock().Number < blk.Number {
+			err := node.state.ApplyBlockToState(blk)
+			if err != nil {
+				r.net.t.Fatal(err)
+			}
+		}
+	}
+}
+
+func newTestNet(t *testing.T, n int, down ...int) *testNet {
+	t.Helper()
+	net := &testNet{
+		t:      t,
+		clock:  &testClock{now: time.Date(2026, 1, 1, 0, 1, 0, 0, time.UTC)},
+		down:   make(map[int]bool),
+		filter: func(*testNet, testMsg) delivery { return deliver },
+	}
+	for _, i := range down {
+		net.down[i] = true
+	}
+	owner, err := chain.NewAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+	gen := chain.NewGenesis("test", owner.Address(), owner.Address(), 1000)
+	gen.Time = net.clock.now.Add(-time.Minute)
+	for range n {
+		val, err := chain.NewAccount()
+		if err != nil {
+			t.Fatal(err)
+		}
+		net.vals = append(net.vals, val)
+		gen.Validators = append(gen.Validators, val.Address())
+	}
+	sigGen := chain.SigGenesis{Genesis: *gen}
+	log := slog.New(slog.DiscardHandler)
+	for i, val := range net.vals {
+		state := chain.NewState(&sigGen)
+		state.SetClock(net.clock)
+		state.SetLogger(log)
+		relay := testRelay{net: net, from: i}
+		node := NewConsensus(
+			context.Background(), &sync.WaitGroup{}, relay, relay, net.clock, log,
+		)
+		node.SetValidator(val)
+		node.SetState(state)
+		node.schedule = BlockSchedule{Interval: time.Second, Empty: true}
+		node.newHeight()
+		net.nodes = append(net.nodes, node)
+	}
+	return net
+}
+
+// step delivers the queued messages that the filter lets through, takes the
+// steps of the live validators, then advances the clock
+func (n *testNet) step() {
+	queue := n.queue
+	n.queue = nil
+	for _, msg := range queue {
+		switch n.filter(n, msg) {
+		case deliver:
+			if !n.down[msg.to] {
+				n.nodes[msg.to].receive(msg.msg)
+			}
+		case delay:
+			n.queue = append(n.queue, msg)
+		}
+	}
+	for i, node := range n.nodes {
+		if !n.down[i] {
+			node.advance()
+		}
+	}
+	n.clock.now = n.clock.now.Add(minWaitCheck)
+}
+
+// run steps until the live validators apply the block of the height, or for
+// at most the given steps. It returns the block of the height
+func (n *testNet) run(height uint64, steps int) (chain.SigBlock, bool) {
+	for range steps {
+		n.step()
+		var blk chain.SigBlock
+		applied := true
+		for i, node := range n.nodes {
+			if n.down[i] {
+				continue
+			}
+			last := node.state.LastBlock()
+			applied = applied && last.Number >= height
+			blk = last
+		}
+		if applied {
+			return blk, true
+		}
+	}
+	return chain.SigBlock{}, false
+}
+
+// proposal returns the first proposal of the validator in the round
+func (n *testNet) proposal(from int, round uint32) (chain.SigProposal, bool) {
+	for _, msg := range n.sent {
+		prop := msg.msg.Proposal
+		if msg.from == from && prop != nil && prop.Round == round {
+			return *prop, true
+		}
+	}
+	return chain.SigProposal{}, false
+}
+
+// vote returns the first vote of a type of the validator in the round
+func (n *testNet) vote(
+	from int, round uint32, typ chain.VoteType,
+) (chain.SigVote, bool) {
+	for _, msg := range n.sent {
+		vote := msg.msg.Vote
+		if msg.from == from && vote != nil && vote.Round == round && vote.Type == typ {
+			return *vote, true
+		}
+	}
+	return chain.SigVote{}, false
+}
+
+func TestConsensusLiveness(t *testing.T) {
+	cases := []struct {
+		name      string
+		n         int
+		down      []int
+		committed bool
+	}{
+		{"n=1", 1, nil, true},
+		{"n=3", 3, nil, true},
+		{"n=3 one down", 3, []int{2}, false},
+		{"n=4", 4, nil, true},
+		{"n=4 one down", 4, []int{3}, true},
+		{"n=4 two down", 4, []int{2, 3}, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			net := newTestNet(t, c.n, c.down...)
+			blk, committed := net.run(1, 300)
+			if committed != c.committed {
+				t.Fatalf("expected committed %v, got %v", c.committed, committed)
+			}
+			if committed {
+				err := chain.VerifyCommit(blk, net.nodes[0].state.Validators())
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestConsensusCommitTimeout(t *testing.T) {
+	cases := []struct {
+		name       string
+		n          int
+		lostBlocks int
+		retried    bool
+	}{
+		{"no block lost", 4, 0, false},
+		{"block of a validator lost", 4, 1, false},
+		{"blocks of all validators lost", 4, 4, true},
+		{"block of a single validator lost", 1, 1, true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			net := newTestNet(t, c.n)
+			net.lostBlocks = c.lostBlocks
+			var committed time.Time
+			for range 300 {
+				net.step()
+				if committed.IsZero() && net.nodes[0].step == stepCommit {
+					committed = net.clock.now
+				}
+				if net.nodes[0].state.LastBlock().Number >= 1 {
+					break
+				}
+			}
+			applied := net.clock.now
+			for i, node := range net.nodes {
+				if node.state.LastBlock().Number != 1 {
+					t.Fatalf("expected validator %d to apply block 1", i)
+				}
+			}
+			retried := applied.Sub(committed) >= timeoutCommit
+			if retried != c.retried {
+				t.Fatalf(
+					"expected the block relayed again %v, applied %v after the commit",
+					c.retried, applied.Sub(committed),
+				)
+			}
+		})
+	}
+}
+
+// polFilter drops the proposal of round 0 to validator 3, and holds back the
+// prevotes of round 0 from 1 to 0, from 0 to 1 and from 0 to 3. Only
+// validator 2 then sees a quorum of prevotes for the block of round 0 and
+// locks on the block. The held back prevotes are delivered in round 1 when
+// deliverLate is set, and dropped otherwise
+func polFilter(deliverLate bool) func(*testNet, testMsg) delivery {
+	held := map[[2]int]bool{{1, 0}: true, {0, 1}: true, {0, 3}: true}
+	return func(net *testNet, msg testMsg) delivery {
+		if prop := msg.msg.Proposal; prop != nil && prop.Round == 0 && msg.to == 3 {
+			return drop
+		}
+		vote := msg.msg.Vote
+		if vote == nil || vote.Round != 0 || vote.Type != chain.Prevote ||
+			!held[[2]int{msg.from, msg.to}] {
+			return deliver
+		}
+		if !deliverLate {
+			return drop
+		}
+		if net.nodes[msg.to].round == 0 {
+			return delay
+		}
+		return deliver
+	}
+}
+
+func TestConsensusPOL(t *testing.T) {
+	net := newTestNet(t, 4)
+	net.filter = polFilter(true)
+	blk, committed := net.run(1, 600)
+	if !committed {
+		t.Fatal("expected a committed block")
+	}
+	// Validator 1 proposes in round 0 and validator 2 in round 1
+	prop0, exist := net.proposal(1, 0)
+	if !exist {
+		t.Fatal("expected a proposal of round 0")
+	}
+	locked, exist := net.vote(2, 0, chain.Precommit)
+	if !exist || locked.BlockHash != prop0.Block.Hash() {
+		t.Fatal("expected validator 2 locked on the block of round 0")
+	}
+	prop1, exist := net.proposal(2, 1)
+	if !exist {
+		t.Fatal("expected a proposal of round 1")
+	}
+	if prop1.POLRound != 0 || prop1.Block.Hash() != prop0.Block.Hash() {
+		t.Fatalf("expected the block of round 0 proposed with POL round 0, got POL round %d", prop1.POLRound)
+	}
+	if blk.Hash() != prop0.Block.Hash() || blk.Commit.Round != 1 {
+		t.Fatalf("expected the block of round 0 committed in round 1, got round %d", blk.Commit.Round)
+	}
+}
+
+func TestConsensusLock(t *testing.T) {
+	net := newTestNet(t, 4)
+	net.filter = polFilter(false)
+	blk, committed := net.run(1, 600)
+	if !committed {
+		t.Fatal("expected a committed block")
+	}
+	prop0, exist := net.proposal(1, 0)
+	if !exist {
+		t.Fatal("expected a proposal of round 0")
+	}
+	locked, exist := net.vote(2, 0, chain.Precommit)
+	if !exist || locked.BlockHash != prop0.Block.Hash() {
+		t.Fatal("expected validator 2 locked on the block of round 0")
+	}
+	// Without a quorum of the prevotes of round 0 the other validators
+	// reject the block of validator 2, which stays locked on the block and
+	// prevotes no other block
+	if blk.Hash() == prop0.Block.Hash() || blk.Commit.Round != 2 {
+		t.Fatalf("expected another block committed in round 2, got round %d", blk.Commit.Round)
+	}
+	vote, exist := net.vote(2, 2, chain.Prevote)
+	if !exist || vote.BlockHash != (chain.Hash{}) {
+		t.Fatalf("expected a nil prevote of the locked validator in round 2, got %v", vote.BlockHash)
+	}
+}
+
+func TestConsensusDistantMsgs(t *testing.T) {
+	net := newTestNet(t, 4)
+	node := net.nodes[0]
+	outsider, err := chain.NewAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+	votes := []struct {
+		name   string
+		signer chain.Account
+		height uint64
+		round  uint32
+		// voted and waiting report whether the vote is recorded for the
+		// current height or kept for the next height
+		voted, waiting bool
+	}{
+		{"current round", net.vals[1], 1, 0, true, false},
+		{"last round ahead", net.vals[1], 1, maxRoundsAhead, true, false},
+		{"too many rounds ahead", net.vals[1], 1, maxRoundsAhead + 1, false, false},
+		{"next height", net.vals[1], 2, 0, false, true},
+		{"next height too many rounds ahead", net.vals[1], 2, maxRoundsAhead + 1, false, false},
+		{"later height", net.vals[1], 3, 0, false, false},
+		{"non-validator next height", outsider, 2, 0, false, false},
+	}
+	for _, v := range votes {
+		t.Run(v.name, func(t *testing.T) {
+			vote, err := v.signer.SignVote(chain.Vote{
+				Type: chain.Prevote, Height: v.height, Round: v.round,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			future := len(node.future)
+			node.receive(chain.ConsensusMsg{Vote: &vote})
+			_, voted := node.votes[voteKey{round: v.round, typ: chain.Prevote}][vote.Validator]
+			if v.height != 1 {
+				voted = false
+			}
+			waiting := len(node.future) > future
+			if voted != v.voted || waiting != v.waiting {
+				t.Fatalf(
+					"expected voted %v and waiting %v, got %v and %v",
+					v.voted, v.waiting, voted, waiting,
+				)
+			}
+		})
+	}
+	// Replaying the waiting messages on a new height does not keep them twice
+	node.newHeight()
+	if len(node.future) != 1 {
+		t.Fatalf("expected 1 waiting message, got %d", len(node.future))
+	}
+}
+
+func TestConsensusRoundSkip(t *testing.T) {
+	net := newTestNet(t, 4)
+	node := net.nodes[0]
+	// More than a third of the validators joined round 5
+	for _, val := range net.vals[1:3] {
+		vote, err := val.SignVote(chain.Vote{Type: chain.Prevote, Height: 1, Round: 5})
+		if err != nil {
+			t.Fatal(err)
+		}
+		node.receive(chain.ConsensusMsg{Vote: &vote})
+	}
+	// A single validator in a later round is not followed
+	vote, err := net.vals[3].SignVote(chain.Vote{Type: chain.Prevote, Height: 1, Round: 9})
+	if err != nil {
+		t.Fatal(err)
+	}
+	node.receive(chain.ConsensusMsg{Vote: &vote})
+	node.update()
+	if node.round != 5 {
+		t.Fatalf("expected round 5, got %d", node.round)
+	}
+}
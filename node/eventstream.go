@@ -3,6 +3,9 @@ package node
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
 	"sync"
 
 	"github.com/Ansh1902396/chain"
@@ -15,9 +18,12 @@ type EventPublisher interface {
 type EventStream struct {
 	ctx       context.Context
 	wg        *sync.WaitGroup
+	dir       string
 	chEvent   chan chain.Event
 	mtx       sync.Mutex
+	seq       uint64
 	chStreams map[string]chan chain.Event
+	log       *slog.Logger
 }
 
 func NewEvent(evType chain.EventType, action string, body []byte) chain.Event {
@@ -29,26 +35,52 @@ func NewEvent(evType chain.EventType, action string, body []byte) chain.Event {
 }
 
 func NewEventStream(
-	ctx context.Context, wg *sync.WaitGroup, cap int,
+	ctx context.Context, wg *sync.WaitGroup, cap int, dir string,
+	log *slog.Logger,
 ) *EventStream {
-	return &EventStream{
-		ctx: ctx, wg: wg, chEvent: make(chan chain.Event, cap),
-		chStreams: make(map[string]chan chain.Event),
+	s := &EventStream{
+		ctx: ctx, wg: wg, dir: dir, chEvent: make(chan chain.Event, cap),
+		chStreams: make(map[string]chan chain.Event), log: log,
 	}
+	return s
+}
+
+// ReadSeq restores the sequence number of the last persisted event. The
+// stream must not start after a failed read, or it would reuse sequence
+// numbers
+func (s *EventStream) ReadSeq() error {
+	seq, err := chain.LastEvent(s.dir)
+	if err != nil {
+		return fmt.Errorf("read event sequence: %w", err)
+	}
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.seq = seq
+	return nil
 }
 
 func (s *EventStream) PublishEvent(event chain.Event) {
 	s.chEvent <- event
 }
 
-func (s *EventStream) AddSubscriber(sub string) chan chain.Event {
+// AddSubscriber returns the stream of live events along with the sequence
+// number of the last persisted event. Events up to the sequence number are
+// replayed from the event store, the following ones are sent to the stream
+func (s *EventStream) AddSubscriber(sub string) (chan chain.Event, uint64) {
 	s.mtx.Lock()
 	defer s.mtx.Unlock()
 
-	chStream := make(chan chain.Event)
+	// Buffered streams keep a slow subscriber from blocking the others
+	chStream := make(chan chain.Event, cap(s.chEvent))
 	s.chStreams[sub] = chStream
-	fmt.Printf("<~> Stream : %v\n ", sub)
-	return chStream
+	s.log.Info("Subscribe", "sub", sub)
+	return chStream, s.seq
+}
+
+func (s *EventStream) Subscribers() int {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return len(s.chStreams)
 }
 
 func (s *EventStream) RemoveSubscriber(sub string) {
@@ -58,7 +90,7 @@ func (s *EventStream) RemoveSubscriber(sub string) {
 	if exist {
 		close(chStream)
 		delete(s.chStreams, sub)
-		fmt.Printf("<~> Unsubscribe : %v\n", sub)
+		s.log.Info("Unsubscribe", "sub", sub)
 	}
 }
 
@@ -67,15 +99,33 @@ func (s *EventStream) StreamEvents() {
 	for {
 		select {
 		case <-s.ctx.Done():
-			for sub := range s.chStreams {
+			s.mtx.Lock()
+			subs := slices.Collect(maps.Keys(s.chStreams))
+			s.mtx.Unlock()
+			for _, sub := range subs {
 				s.RemoveSubscriber(sub)
 			}
 			return
 
 		case event := <-s.chEvent:
-			for _, chStream := range s.chStreams {
-				chStream <- event
+			s.mtx.Lock()
+			event.Seq = s.seq + 1
+			err := event.Write(s.dir)
+			if err != nil {
+				s.mtx.Unlock()
+				s.log.Error("Event write", "err", err)
+				continue
+			}
+			s.seq = event.Seq
+			for sub, chStream := range s.chStreams {
+				select {
+				case chStream <- event:
+				default:
+					// The subscriber catches up from the event store
+					s.log.Debug("Event deferred", "sub", sub, "seq", event.Seq)
+				}
 			}
+			s.mtx.Unlock()
 		}
 	}
 }
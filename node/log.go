@@ -0,0 +1,115 @@
+package node
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// The log records carry the component that logs them and, when known, the
+// peer address, the block number, the transaction hash, and the error under
+// the peer, block, tx, and err keys
+const logComponent = "component"
+
+// LogLevels are the minimum log levels of the components. Components
+// without a level log at the default level
+type LogLevels struct {
+	Default    slog.Level
+	Components map[string]slog.Level
+}
+
+func (l LogLevels) level(component string) slog.Level {
+	level, exist := l.Components[component]
+	if !exist {
+		return l.Default
+	}
+	return level
+}
+
+func (l LogLevels) min() slog.Level {
+	level := l.Default
+	for _, compLevel := range l.Components {
+		level = min(level, compLevel)
+	}
+	return level
+}
+
+// ParseLogLevels parses a comma separated list of the default level and
+// component=level pairs, e.g. info,relay=debug,rpc=warn
+func ParseLogLevels(str string) (LogLevels, error) {
+	levels := LogLevels{
+		Default: slog.LevelInfo, Components: make(map[string]slog.Level),
+	}
+	for part := range strings.SplitSeq(str, ",") {
+		part = strings.TrimSpace(part)
+		if len(part) == 0 {
+			continue
+		}
+		component, name, found := strings.Cut(part, "=")
+		if !found {
+			name = component
+		}
+		var level slog.Level
+		err := level.UnmarshalText([]byte(name))
+		if err != nil {
+			return LogLevels{}, fmt.Errorf("log level %v: %w", part, err)
+		}
+		if found {
+			levels.Components[component] = level
+		} else {
+			levels.Default = level
+		}
+	}
+	return levels, nil
+}
+
+// NewLogger creates a text or JSON logger that filters the records by the
+// level of their component
+func NewLogger(w io.Writer, format string, levels LogLevels) (*slog.Logger, error) {
+	opts := &slog.HandlerOptions{Level: levels.min()}
+	var handler slog.Handler
+	switch format {
+	case "text":
+		handler = slog.NewTextHandler(w, opts)
+	case "json":
+		handler = slog.NewJSONHandler(w, opts)
+	default:
+		return nil, fmt.Errorf("log format: expected text or json, got %v", format)
+	}
+	compHandler := &componentHandler{
+		Handler: handler, levels: levels, level: levels.Default,
+	}
+	return slog.New(compHandler), nil
+}
+
+// componentHandler applies the level of the component set on the logger
+// with the component attribute
+type componentHandler struct {
+	slog.Handler
+	levels LogLevels
+	level  slog.Level
+}
+
+func (h *componentHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level
+}
+
+func (h *componentHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	level := h.level
+	for _, attr := range attrs {
+		if attr.Key == logComponent {
+			level = h.levels.level(attr.Value.String())
+		}
+	}
+	return &componentHandler{
+		Handler: h.Handler.WithAttrs(attrs), levels: h.levels, level: level,
+	}
+}
+
+func (h *componentHandler) WithGroup(name string) slog.Handler {
+	return &componentHandler{
+		Handler: h.Handler.WithGroup(name), levels: h.levels, level: h.level,
+	}
+}
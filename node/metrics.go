@@ -0,0 +1,212 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Ansh1902396/chain"
+	"github.com/Ansh1902396/node/rpc"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// Metrics exposes the chain, the pending pool, the relays, and the gRPC
+// servers of the node in the Prometheus format
+type Metrics struct {
+	registry    *prometheus.Registry
+	blockApply  prometheus.Histogram
+	blkInterval prometheus.Histogram
+	blkEmpty    prometheus.Counter
+	blkRejected prometheus.Counter
+	txAccepted  prometheus.Counter
+	txRejected  *prometheus.CounterVec
+	grpcLatency *prometheus.HistogramVec
+}
+
+func NewMetrics() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		blockApply: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "ruchain_block_apply_seconds",
+			Help:    "Time to verify and apply a block to the state",
+			Buckets: prometheus.ExponentialBuckets(0.0005, 2, 14),
+		}),
+		blkInterval: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "ruchain_block_interval_seconds",
+			Help:    "Time between an applied block and its parent block",
+			Buckets: prometheus.ExponentialBuckets(0.25, 2, 12),
+		}),
+		blkEmpty: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "ruchain_blocks_empty_total",
+			Help: "Applied heartbeat blocks without transactions",
+		}),
+		blkRejected: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "ruchain_blocks_rejected_total",
+			Help: "Blocks above the chain height that failed to apply to the state",
+		}),
+		txAccepted: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "ruchain_txs_accepted_total",
+			Help: "Transactions accepted into the pending pool",
+		}),
+		txRejected: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "ruchain_txs_rejected_total",
+			Help: "Transactions rejected by or dropped from the pending pool by reason",
+		}, []string{"reason"}),
+		grpcLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "ruchain_grpc_request_duration_seconds",
+			Help:    "Duration of the gRPC requests by method and status code",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "code"}),
+	}
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.blockApply, m.blkInterval, m.blkEmpty, m.blkRejected, m.txAccepted, m.txRejected, m.grpcLatency,
+	)
+	return m
+}
+
+// RegisterNode adds the gauges read from the node on each scrape
+func (m *Metrics) RegisterNode(n *Node) {
+	gauge := func(name, help string, value func() float64) prometheus.Collector {
+		opts := prometheus.GaugeOpts{Name: name, Help: help}
+		return prometheus.NewGaugeFunc(opts, value)
+	}
+	relayQueue := func(relay string, value func() float64) prometheus.Collector {
+		opts := prometheus.GaugeOpts{
+			Name:        "ruchain_relay_queue_depth",
+			Help:        "Messages waiting to be relayed to the peers",
+			ConstLabels: prometheus.Labels{"relay": relay},
+		}
+		return prometheus.NewGaugeFunc(opts, value)
+	}
+	m.registry.MustRegister(
+		gauge("ruchain_chain_height", "Number of the last applied block",
+			func() float64 { return float64(n.state.LastBlock().Number) }),
+		gauge("ruchain_head_block_timestamp_seconds",
+			"Unix time of the last applied block",
+			func() float64 {
+				head := n.state.LastBlock()
+				if head.Number == 0 {
+					return 0
+				}
+				return float64(head.Time.Unix())
+			}),
+		gauge("ruchain_pending_txs", "Transactions in the pending pool",
+			func() float64 { return float64(n.state.Pending.TxCount()) }),
+		gauge("ruchain_peers", "Known peers of the node",
+			func() float64 { return float64(len(n.peerDisc.Peers())) }),
+		gauge("ruchain_event_subscribers", "Subscribers of the event stream",
+			func() float64 { return float64(n.evStream.Subscribers()) }),
+		relayQueue("tx", func() float64 { return float64(n.txRelay.QueueLen()) }),
+		relayQueue("block", func() float64 { return float64(n.blkRelay.QueueLen()) }),
+	)
+}
+
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+func (m *Metrics) observeRPC(method string, start time.Time, err error) {
+	code := status.Code(err).String()
+	m.grpcLatency.WithLabelValues(method, code).Observe(time.Since(start).Seconds())
+}
+
+func (m *Metrics) UnaryInterceptor(
+	ctx context.Context, req any, info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (any, error) {
+	start := time.Now()
+	res, err := handler(ctx, req)
+	m.observeRPC(info.FullMethod, start, err)
+	return res, err
+}
+
+func (m *Metrics) StreamInterceptor(
+	srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	start := time.Now()
+	err := handler(srv, stream)
+	m.observeRPC(info.FullMethod, start, err)
+	return err
+}
+
+// txRejectReason classifies the error of a rejected transaction
+func txRejectReason(tx chain.SigTx, err error) string {
+	switch {
+	case errors.Is(err, chain.ErrTxSignature):
+		return "signature"
+	case errors.Is(err, chain.ErrTxNonce):
+		return "nonce"
+	case errors.Is(err, chain.ErrTxFunds):
+		return "funds"
+	case errors.Is(err, chain.ErrTxExpired):
+		return "expired"
+	case errors.Is(err, chain.ErrTxNotYetValid):
+		return "not_yet_valid"
+	case errors.Is(err, chain.ErrTxSize):
+		return "size"
+	case tx.HTLC != nil:
+		return "htlc"
+	default:
+		return "invalid"
+	}
+}
+
+// poolDrop counts the pending transactions that are rejected or expire when
+// the pending pool is rebuilt after a block or a purge
+func (m *Metrics) poolDrop(tx chain.SigTx, err error) {
+	m.txRejected.WithLabelValues(txRejectReason(tx, err)).Inc()
+}
+
+// meteredTxApplier counts the transactions accepted and rejected by the
+// pending pool. The transactions that leave the pool later are counted by
+// poolDrop
+type meteredTxApplier struct {
+	rpc.TxApplier
+	metrics *Metrics
+}
+
+func (a meteredTxApplier) ApplyTx(tx chain.SigTx) error {
+	err := a.TxApplier.ApplyTx(tx)
+	if err != nil {
+		a.metrics.txRejected.WithLabelValues(txRejectReason(tx, err)).Inc()
+		return err
+	}
+	a.metrics.txAccepted.Inc()
+	return nil
+}
+
+// meteredBlockApplier measures the latency and the interval of the applied
+// blocks and counts the empty and the rejected ones. Blocks relayed again
+// after being applied are not counted
+type meteredBlockApplier struct {
+	state   *chain.State
+	metrics *Metrics
+}
+
+func (a meteredBlockApplier) ApplyBlockToState(blk chain.SigBlock) error {
+	start, parent := time.Now(), a.state.LastBlock()
+	err := a.state.ApplyBlockToState(blk)
+	if err != nil {
+		if blk.Number > a.state.LastBlock().Number {
+			a.metrics.blkRejected.Inc()
+		}
+		return err
+	}
+	a.metrics.blockApply.Observe(time.Since(start).Seconds())
+	// The interval of the first block would include the genesis wait
+	if parent.Number > 0 {
+		a.metrics.blkInterval.Observe(blk.Time.Sub(parent.Time).Seconds())
+	}
+	if len(blk.Txs) == 0 {
+		a.metrics.blkEmpty.Inc()
+	}
+	return nil
+}
@@ -3,21 +3,25 @@ package node
 import (
 	"context"
 	"encoding/json"
-	"fmt"
+	"log/slog"
 	"sync"
 	"time"
 
 	"github.com/Ansh1902396/chain"
 	"github.com/Ansh1902396/node/rpc"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 )
 
 type GRPCMsgRelay[Msg any] func(
 	ctx context.Context, conn *grpc.ClientConn, chRelay chan Msg,
+	traces *Traces, log *slog.Logger,
 ) error
 
-var GRPCBlkRelay GRPCMsgRelay[chain.SigBlock] = func(ctx context.Context, conn *grpc.ClientConn, chRelay chan chain.SigBlock) error {
+var GRPCBlkRelay GRPCMsgRelay[chain.SigBlock] = func(ctx context.Context, conn *grpc.ClientConn, chRelay chan chain.SigBlock, traces *Traces, log *slog.Logger) error {
 	cln := rpc.NewBlockClient(conn)
 	stream, err := cln.BlockReceive(context.Background())
 
@@ -35,22 +39,24 @@ var GRPCBlkRelay GRPCMsgRelay[chain.SigBlock] = func(ctx context.Context, conn *
 			}
 			jblock, err := json.Marshal(block)
 			if err != nil {
-				fmt.Println(err)
+				log.Error("Block encode", "block", block.Number, "err", err)
 				continue
 			}
-			req := &rpc.BlockReceiveReq{Block: jblock}
+			span := relaySpan(ctx, traces, "block.relay", block.Hash(), conn.Target(), rpc.BlockSpanAttrs(block))
+			req := &rpc.BlockReceiveReq{Block: jblock, Trace: rpc.InjectTrace(span.ctx)}
 			err = stream.Send(req)
+			span.end(err)
 
+			// A restarted peer is relayed to on a new stream
 			if err != nil {
-				fmt.Println(err)
-				continue
+				return err
 			}
 		}
 	}
 
 }
 
-var GRPCTxRelay GRPCMsgRelay[chain.SigTx] = func(ctx context.Context, conn *grpc.ClientConn, chRelay chan chain.SigTx) error {
+var GRPCTxRelay GRPCMsgRelay[chain.SigTx] = func(ctx context.Context, conn *grpc.ClientConn, chRelay chan chain.SigTx, traces *Traces, log *slog.Logger) error {
 	cln := rpc.NewTxClient(conn)
 	stream, err := cln.TxReceive(context.Background())
 
@@ -69,14 +75,16 @@ var GRPCTxRelay GRPCMsgRelay[chain.SigTx] = func(ctx context.Context, conn *grpc
 			}
 			jtx, err := json.Marshal(tx)
 			if err != nil {
-				fmt.Println(err)
+				log.Error("Tx encode", "tx", tx.Hash().String(), "err", err)
 				continue
 			}
-			req := &rpc.TxReceiveReq{Tx: jtx}
+			span := relaySpan(ctx, traces, "tx.relay", tx.Hash(), conn.Target(), rpc.TxSpanAttrs(tx))
+			req := &rpc.TxReceiveReq{Tx: jtx, Trace: rpc.InjectTrace(span.ctx)}
 			err = stream.Send(req)
+			span.end(err)
 
 			if err != nil {
-				fmt.Println(err)
+				log.Warn("Tx relay", "tx", tx.Hash().String(), "err", err)
 				continue
 			}
 		}
@@ -84,6 +92,39 @@ var GRPCTxRelay GRPCMsgRelay[chain.SigTx] = func(ctx context.Context, conn *grpc
 
 }
 
+// GRPCConsensusRelay relays the proposals and the votes of the validator
+var GRPCConsensusRelay GRPCMsgRelay[chain.ConsensusMsg] = func(
+	ctx context.Context, conn *grpc.ClientConn,
+	chRelay chan chain.ConsensusMsg, traces *Traces, log *slog.Logger,
+) error {
+	cln := rpc.NewConsensusClient(conn)
+	stream, err := cln.ConsensusReceive(context.Background())
+	if err != nil {
+		return err
+	}
+	defer stream.CloseAndRecv()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg, open := <-chRelay:
+			if !open {
+				return nil
+			}
+			jmsg, err := json.Marshal(msg)
+			if err != nil {
+				log.Error("Consensus encode", "err", err)
+				continue
+			}
+			// A restarted peer is relayed to on a new stream
+			err = stream.Send(&rpc.ConsensusReceiveReq{Msg: jmsg})
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
 type MsgRelay[Msg any, Relay GRPCMsgRelay[Msg]] struct {
 	ctx                  context.Context
 	wg                   *sync.WaitGroup
@@ -93,6 +134,8 @@ type MsgRelay[Msg any, Relay GRPCMsgRelay[Msg]] struct {
 	peerReader           PeerReader
 	wgRelays             *sync.WaitGroup
 	chPeerAdd, chPeerRem chan string
+	traces               *Traces
+	log                  *slog.Logger
 }
 
 func (r *MsgRelay[Msg, Relay]) RelayTx(tx Msg) error {
@@ -104,6 +147,15 @@ func (r *MsgRelay[Msg, Relay]) RelayBlock(block Msg) {
 	r.chMsg <- block
 }
 
+func (r *MsgRelay[Msg, Relay]) RelayConsensus(msg Msg) {
+	r.chMsg <- msg
+}
+
+// QueueLen returns the number of messages waiting to be relayed
+func (r *MsgRelay[Msg, Relay]) QueueLen() int {
+	return len(r.chMsg)
+}
+
 func (r *MsgRelay[Msg, Relay]) RelayMsgs(period time.Duration) {
 	defer r.wg.Done()
 
@@ -130,21 +182,16 @@ func (r *MsgRelay[Msg, Relay]) RelayMsgs(period time.Duration) {
 			if exist {
 				continue
 			}
-			if r.selfRelay {
-				fmt.Printf("<==> Blk relay: %v\n ", peer)
-			} else {
-				fmt.Printf("<==> Tx relay: %v\n ", peer)
-			}
+			r.log.Info("Relay peer", "peer", peer)
 			chRelay := r.peerRelay(peer)
 
 			chRelays[peer] = chRelay
 
 		case peer := <-r.chPeerRem:
-			_, exist := chRelays[peer]
-			if exist {
+			chRelay, exist := chRelays[peer]
+			if !exist {
 				continue
 			}
-			chRelay := chRelays[peer]
 			close(chRelay)
 			delete(chRelays, peer)
 
@@ -189,18 +236,20 @@ func (r *MsgRelay[Msg, Relay]) peerRelay(peer string) chan Msg {
 
 		conn, err := grpc.NewClient(
 			peer, grpc.WithTransportCredentials(insecure.NewCredentials()),
+			rpc.WithMaxMsgSize(),
 		)
 
+		log := r.log.With("peer", peer)
 		if err != nil {
-			fmt.Println(err)
-			r.chPeerRem <- peer
+			log.Error("Relay connect", "err", err)
+			r.removePeer(peer, chRelay)
 			return
 		}
 		defer conn.Close()
-		err = r.grpcRelay(r.ctx, conn, chRelay)
+		err = r.grpcRelay(r.ctx, conn, chRelay, r.traces, log)
 		if err != nil {
-			fmt.Println(err)
-			r.chPeerRem <- peer
+			log.Error("Relay stream", "err", err)
+			r.removePeer(peer, chRelay)
 			return
 		}
 	}()
@@ -208,10 +257,23 @@ func (r *MsgRelay[Msg, Relay]) peerRelay(peer string) chan Msg {
 	return chRelay
 }
 
+// removePeer removes a failed peer, so that the peer is relayed to again once
+// discovered, and drops the messages for the peer until its relay is closed
+func (r *MsgRelay[Msg, Relay]) removePeer(peer string, chRelay chan Msg) {
+	go func() {
+		select {
+		case r.chPeerRem <- peer:
+		case <-r.ctx.Done():
+		}
+	}()
+	for range chRelay {
+	}
+}
+
 func NewMsgRelay[Msg any, Relay GRPCMsgRelay[Msg]](
 	ctx context.Context, wg *sync.WaitGroup, cap int,
-	grpcRelay Relay, selfRelay bool, peerReader PeerReader,
-
+	grpcRelay Relay, selfRelay bool, peerReader PeerReader, traces *Traces,
+	log *slog.Logger,
 ) *MsgRelay[Msg, Relay] {
 	return &MsgRelay[Msg, Relay]{
 		ctx:        ctx,
@@ -223,5 +285,32 @@ func NewMsgRelay[Msg any, Relay GRPCMsgRelay[Msg]](
 		wgRelays:   &sync.WaitGroup{},
 		chPeerAdd:  make(chan string),
 		chPeerRem:  make(chan string),
+		traces:     traces,
+		log:        log,
+	}
+}
+
+// msgSpan is a span in the trace of a relayed or proposed message
+type msgSpan struct {
+	ctx  context.Context
+	span trace.Span
+}
+
+func relaySpan(
+	ctx context.Context, traces *Traces, name string, hash chain.Hash,
+	peer string, attrs trace.SpanStartOption,
+) msgSpan {
+	ctx, _ = traces.TraceContext(ctx, hash)
+	ctx, span := tracer.Start(
+		ctx, name, attrs, trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(attribute.String("peer", peer)),
+	)
+	return msgSpan{ctx: ctx, span: span}
+}
+
+func (s msgSpan) end(err error) {
+	if err != nil {
+		s.span.SetStatus(codes.Error, err.Error())
 	}
+	s.span.End()
 }
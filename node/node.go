@@ -3,9 +3,12 @@ package node
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"net"
+	"net/http"
 	"os/signal"
 	"path/filepath"
+	"slices"
 	"sync"
 	"syscall"
 	"time"
@@ -13,19 +16,48 @@ import (
 	"github.com/Ansh1902396/chain"
 	"github.com/Ansh1902396/node/rpc"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
 )
 
+const Version = "0.1.0"
+
+// NodeCfg configures the node. Period paces the peer discovery and the
+// relays. Blocks are proposed every block period, unless the genesis sets the
+// block period, empty ones only with EmptyBlocks. MaxBlockWait forces a block
+// once a pending transaction has waited that long. GenesisFile replaces the
+// created or synced genesis. On a chain of validators, Validator is the
+// account the node finalizes the blocks with. Clock times the blocks and the
+// transactions. The zero tunables take their default values
 type NodeCfg struct {
-	Chain         string
-	Balance       uint64
-	Period        time.Duration
-	KeyStoreDir   string
-	NodeAddr      string
-	Bootstrap     bool
-	SeedAddr      string
-	BlockStoreDir string
-	AuthorityPass string
-	OwnerPass     string
+	Chain          string
+	Balance        uint64
+	Period         time.Duration
+	BlockPeriod    time.Duration
+	EmptyBlocks    bool
+	MaxBlockWait   time.Duration
+	TxRelayCap     int
+	BlockRelayCap  int
+	MaxPeers       int
+	KeyStoreDir    string
+	NodeAddr       string
+	HTTPAddr       string
+	CORSOrigins    []string
+	HTTPPrivate    bool
+	WebhookPrivate bool
+	EthRPCAddr     string
+	ExplorerAddr   string
+	MetricsAddr    string
+	OTLPAddr       string
+	Clock          chain.Clock
+	Logger         *slog.Logger
+	Bootstrap      bool
+	SeedAddr       string
+	BlockStoreDir  string
+	GenesisFile    string
+	AuthorityPass  string
+	OwnerPass      string
+	Validator      string
+	ValidatorPass  string
 }
 
 type Node struct {
@@ -34,8 +66,13 @@ type Node struct {
 	ctxCancel func()
 	wg        *sync.WaitGroup
 	chErr     chan error
+	started   time.Time
+	log       *slog.Logger
 
+	metrics   *Metrics
+	traces    *Traces
 	evStream  *EventStream
+	webhooks  *WebhookDispatcher
 	state     *chain.State
 	StateSync *StateSync
 	grpcSrv   *grpc.Server
@@ -43,6 +80,8 @@ type Node struct {
 	txRelay   *MsgRelay[chain.SigTx, GRPCMsgRelay[chain.SigTx]]
 	blockProp *BlockProposer
 	blkRelay  *MsgRelay[chain.SigBlock, GRPCMsgRelay[chain.SigBlock]]
+	consensus *Consensus
+	consRelay *MsgRelay[chain.ConsensusMsg, GRPCMsgRelay[chain.ConsensusMsg]]
 }
 
 func NewNode(cfg NodeCfg) *Node {
@@ -50,57 +89,152 @@ func NewNode(cfg NodeCfg) *Node {
 		context.Background(), syscall.SIGINT, syscall.SIGTERM, syscall.SIGKILL,
 	)
 
+	log := cfg.Logger
+	if log == nil {
+		log = slog.Default()
+	}
+	if cfg.BlockPeriod == 0 {
+		cfg.BlockPeriod = 2 * cfg.Period
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = chain.SystemClock
+	}
+	if cfg.TxRelayCap == 0 {
+		cfg.TxRelayCap = 100
+	}
+	if cfg.BlockRelayCap == 0 {
+		cfg.BlockRelayCap = 100
+	}
+	component := func(name string) *slog.Logger {
+		return log.With(logComponent, name)
+	}
 	wg := new(sync.WaitGroup)
-	evStream := NewEventStream(ctx, wg, 100)
+	evStream := NewEventStream(ctx, wg, 100, cfg.BlockStoreDir, component("events"))
+	webhooks := NewWebhookDispatcher(
+		ctx, wg, cfg.BlockStoreDir, evStream, cfg.WebhookPrivate,
+		component("webhooks"),
+	)
 	peerDiscCfg := PeerDiscoveryCfg{
 		NodeAddr:  cfg.NodeAddr,
 		Bootstrap: cfg.Bootstrap,
 		SeedAddr:  []string{cfg.SeedAddr},
+		MaxPeers:  cfg.MaxPeers,
 	}
 
-	peerDisc := NewPeerDiscovery(ctx, wg, peerDiscCfg)
-	stateSync := NewStateSync(ctx, cfg, peerDisc)
-	txRelay := NewMsgRelay(ctx, wg, 100, GRPCTxRelay, false, peerDisc)
-	blkRelay := NewMsgRelay(ctx, wg, 100, GRPCBlkRelay, false, peerDisc)
-	blockProp := NewBlockProposer(ctx, wg, blkRelay)
+	peerDisc := NewPeerDiscovery(ctx, wg, peerDiscCfg, component("peers"))
+	stateSync := NewStateSync(ctx, cfg, peerDisc, component("sync"))
+	traces := NewTraces(10000)
+	txRelay := NewMsgRelay(
+		ctx, wg, cfg.TxRelayCap, GRPCTxRelay, false, peerDisc, traces,
+		component("relay").With("relay", "tx"),
+	)
+	blkRelay := NewMsgRelay(
+		ctx, wg, cfg.BlockRelayCap, GRPCBlkRelay, true, peerDisc, traces,
+		component("relay").With("relay", "block"),
+	)
+	blockProp := NewBlockProposer(
+		ctx, wg, blkRelay, cfg.Clock, traces, component("proposer"),
+	)
+	consRelay := NewMsgRelay(
+		ctx, wg, cfg.BlockRelayCap, GRPCConsensusRelay, false, peerDisc, traces,
+		component("relay").With("relay", "consensus"),
+	)
+	consensus := NewConsensus(
+		ctx, wg, blkRelay, consRelay, cfg.Clock, component("consensus"),
+	)
 
 	return &Node{
 		cfg:       cfg,
 		ctx:       ctx,
 		ctxCancel: cancel,
 		wg:        wg,
-		chErr:     make(chan error),
+		chErr:     make(chan error, 1),
+		log:       log,
+		metrics:   NewMetrics(),
+		traces:    traces,
 		evStream:  evStream,
+		webhooks:  webhooks,
 		StateSync: stateSync,
 		peerDisc:  peerDisc,
 		txRelay:   txRelay,
 		blockProp: blockProp,
 		blkRelay:  blkRelay,
+		consensus: consensus,
+		consRelay: consRelay,
 	}
 
 }
 
-func (n *Node) Start() error {
-	defer n.ctxCancel()
+// Start runs the node until the context is done or a server fails. The
+// components of the node stop on every return
+func (n *Node) Start() (err error) {
+	defer func() {
+		n.ctxCancel()
+		n.wg.Wait()
+	}()
+	err = n.evStream.ReadSeq()
+	if err != nil {
+		return err
+	}
+	n.started = time.Now()
+	shutdownTracing, err := setupTracing(n.ctx, n.cfg.OTLPAddr, n.cfg.NodeAddr)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err := shutdownTracing(context.Background())
+		if err != nil {
+			n.component("node").Error("Tracing shutdown", "err", err)
+		}
+	}()
 	n.wg.Add(1)
-
 	go n.evStream.StreamEvents()
-
-	// Start gRPC server
 	n.wg.Add(1)
-	go n.servegRPC()
+	go n.webhooks.DispatchEvents()
 
-	state, err := n.StateSync.SyncState()
+	var state *chain.State
+	state, err = n.StateSync.SyncState()
 	if err != nil {
 		return err
 	}
 	n.state = state
+	n.state.SetLogger(n.component("chain"))
+
+	// Start gRPC server once the state is available to the services
+	n.wg.Add(1)
+	go n.servegRPC()
+	if len(n.cfg.HTTPAddr) > 0 {
+		n.wg.Add(1)
+		go n.serveHTTP()
+	}
+	if len(n.cfg.EthRPCAddr) > 0 {
+		n.wg.Add(1)
+		go n.serveEthRPC()
+	}
+	if len(n.cfg.ExplorerAddr) > 0 {
+		n.wg.Add(1)
+		go n.serveExplorer()
+	}
+	if len(n.cfg.MetricsAddr) > 0 {
+		n.metrics.RegisterNode(n)
+		n.state.OnPoolDrop(n.metrics.poolDrop)
+		n.wg.Add(1)
+		go n.serveMetrics()
+	}
 	n.wg.Add(1)
 	go n.peerDisc.DiscoverPeers(n.cfg.Period)
 	n.wg.Add(1)
 	go n.txRelay.RelayMsgs(n.cfg.Period)
 
-	if n.cfg.Bootstrap {
+	schedule := BlockSchedule{
+		Interval: n.cfg.BlockPeriod, Empty: n.cfg.EmptyBlocks,
+		MaxWait: n.cfg.MaxBlockWait,
+	}
+	if period := n.state.Params().BlockPeriod; period > 0 {
+		schedule.Interval = time.Duration(period)
+	}
+	// The validators finalize the blocks instead of the authority
+	if n.cfg.Bootstrap && !n.state.BFT() {
 		path := filepath.Join(n.cfg.KeyStoreDir, string(n.state.Authority()))
 		auth, err := chain.ReadAccount(path, []byte(n.cfg.AuthorityPass))
 
@@ -112,46 +246,227 @@ func (n *Node) Start() error {
 		n.blockProp.SetState(n.state)
 
 		n.wg.Add(1)
-		go n.blockProp.ProposeBlocks(n.cfg.Period * 2)
+		go n.blockProp.ProposeBlocks(schedule)
 
 	}
+	if len(n.cfg.Validator) > 0 {
+		validator := chain.Address(n.cfg.Validator)
+		if !n.state.BFT() || !slices.Contains(n.state.Validators(), validator) {
+			return fmt.Errorf("node: %v is not a validator of the genesis", validator)
+		}
+		path := filepath.Join(n.cfg.KeyStoreDir, n.cfg.Validator)
+		val, err := chain.ReadAccount(path, []byte(n.cfg.ValidatorPass))
+		if err != nil {
+			return err
+		}
+		n.consensus.SetValidator(val)
+		n.consensus.SetState(n.state)
+		n.wg.Add(1)
+		go n.consensus.FinalizeBlocks(schedule)
+		n.wg.Add(1)
+		go n.consRelay.RelayMsgs(n.cfg.Period)
+	}
 
 	n.wg.Add(1)
 	go n.blkRelay.RelayMsgs(n.cfg.Period)
 	select {
 	case <-n.ctx.Done():
 	case err = <-n.chErr:
-		fmt.Println(err)
+		n.component("node").Error("Node stop", "err", err)
 	}
-	n.ctxCancel()
-	n.grpcSrv.GracefulStop()
-	n.wg.Wait()
 	return err
 }
 
+// fail stops the node with the error of a server. Only the first error is
+// kept, so that the failing servers never block
+func (n *Node) fail(err error) {
+	select {
+	case n.chErr <- err:
+	default:
+	}
+}
+
 func (n *Node) servegRPC() {
 	defer n.wg.Done()
 	lis, err := net.Listen("tcp", n.cfg.NodeAddr)
 	if err != nil {
-		n.chErr <- err
+		n.fail(err)
 		return
 	}
 	defer lis.Close()
-	fmt.Printf("<=> gRPC %v\n", n.cfg.NodeAddr)
-	n.grpcSrv = grpc.NewServer()
-	node := rpc.NewNodeSrv(n.peerDisc, n.evStream)
+	n.component("node").Info("Serve gRPC", "addr", n.cfg.NodeAddr)
+	n.grpcSrv = grpc.NewServer(
+		grpc.MaxRecvMsgSize(rpc.MsgSize(n.state.Params())),
+		grpc.StatsHandler(grpcTraceHandler()),
+		grpc.ChainUnaryInterceptor(n.metrics.UnaryInterceptor),
+		grpc.ChainStreamInterceptor(n.metrics.StreamInterceptor),
+	)
+	node := rpc.NewNodeSrv(
+		n.cfg.BlockStoreDir, n.peerDisc, n.evStream, n.webhooks, n,
+		n.component("rpc"),
+	)
 	rpc.RegisterNodeServer(n.grpcSrv, node)
-	acc := rpc.NewAccountSrv(n.cfg.KeyStoreDir, n.state)
+	acc := rpc.NewAccountSrv(n.cfg.KeyStoreDir, n.cfg.BlockStoreDir, n.state)
 	rpc.RegisterAccountServer(n.grpcSrv, acc)
+	txApplier := meteredTxApplier{TxApplier: n.state.Pending, metrics: n.metrics}
 	tx := rpc.NewTxSrv(
-		n.cfg.KeyStoreDir, n.cfg.BlockStoreDir, n.state, n.txRelay,
+		n.cfg.KeyStoreDir, n.cfg.BlockStoreDir, txApplier, n.txRelay,
+		n.state, n.state, n.cfg.Clock, n.traces, n.component("rpc"),
 	)
 	rpc.RegisterTxServer(n.grpcSrv, tx)
-	blk := rpc.NewBlockSrv(n.cfg.BlockStoreDir, n.state, n.evStream, n.blkRelay)
+	blkApplier := meteredBlockApplier{state: n.state, metrics: n.metrics}
+	blk := rpc.NewBlockSrv(
+		n.cfg.BlockStoreDir, blkApplier, n.evStream, n.blkRelay, n.StateSync,
+		n.traces, n.component("rpc"),
+	)
 	rpc.RegisterBlockServer(n.grpcSrv, blk)
+	if n.state.BFT() {
+		// Other nodes ignore the proposals and the votes relayed to them
+		var handler rpc.ConsensusHandler = ignoreConsensus{}
+		if n.isValidator() {
+			handler = n.consensus
+		}
+		cons := rpc.NewConsensusSrv(handler, n.component("rpc"))
+		rpc.RegisterConsensusServer(n.grpcSrv, cons)
+	}
+	go func() {
+		<-n.ctx.Done()
+		n.grpcSrv.GracefulStop()
+	}()
 	err = n.grpcSrv.Serve(lis)
 	if err != nil {
-		n.chErr <- err
+		n.fail(err)
 		return
 	}
 }
+
+func (n *Node) component(name string) *slog.Logger {
+	return n.log.With(logComponent, name)
+}
+
+func (n *Node) LastBlock() chain.SigBlock {
+	return n.state.LastBlock()
+}
+
+func (n *Node) PendingCount() int {
+	return n.state.Pending.TxCount()
+}
+
+func (n *Node) SyncStatus() string {
+	return n.StateSync.SyncStatus()
+}
+
+func (n *Node) Uptime() time.Duration {
+	return time.Since(n.started)
+}
+
+func (n *Node) Version() string {
+	return Version
+}
+
+// IsAuthority reports whether the node proposes the blocks
+func (n *Node) IsAuthority() bool {
+	if n.state.BFT() {
+		return n.isValidator()
+	}
+	return n.cfg.Bootstrap
+}
+
+// isValidator reports whether the node finalizes the blocks of a chain of
+// validators
+func (n *Node) isValidator() bool {
+	return n.state.BFT() && len(n.cfg.Validator) > 0
+}
+
+type ignoreConsensus struct{}
+
+func (ignoreConsensus) HandleConsensus(chain.ConsensusMsg) {}
+
+// serveHTTP serves the HTTP/JSON gateway that forwards requests to the gRPC
+// server of the node
+func (n *Node) serveHTTP() {
+	defer n.wg.Done()
+	conn, err := grpc.NewClient(
+		n.cfg.NodeAddr, grpc.WithTransportCredentials(insecure.NewCredentials()),
+		rpc.WithMaxMsgSize(),
+	)
+	if err != nil {
+		n.fail(err)
+		return
+	}
+	defer conn.Close()
+	gateway, err := rpc.NewGateway(conn, n.component("http"))
+	if err != nil {
+		n.fail(err)
+		return
+	}
+	gateway.AllowOrigins(n.cfg.CORSOrigins)
+	if n.cfg.HTTPPrivate {
+		gateway.AllowPrivate()
+	}
+	gql, err := rpc.NewGraphQLSrv(n.cfg.BlockStoreDir, n.state)
+	if err != nil {
+		n.fail(err)
+		return
+	}
+	gateway.Handle("/v1/graphql", gql)
+	n.listenHTTP("HTTP", n.cfg.HTTPAddr, gateway)
+}
+
+// serveExplorer serves the read-only block explorer backed by the gRPC
+// server of the node
+func (n *Node) serveExplorer() {
+	defer n.wg.Done()
+	conn, err := grpc.NewClient(
+		n.cfg.NodeAddr, grpc.WithTransportCredentials(insecure.NewCredentials()),
+		rpc.WithMaxMsgSize(),
+	)
+	if err != nil {
+		n.fail(err)
+		return
+	}
+	defer conn.Close()
+	explorer, err := rpc.NewExplorer(conn, n.component("http"))
+	if err != nil {
+		n.fail(err)
+		return
+	}
+	n.listenHTTP("Explorer", n.cfg.ExplorerAddr, explorer)
+}
+
+// serveEthRPC serves the Ethereum JSON-RPC subset backed by the state and the
+// block store of the node
+func (n *Node) serveEthRPC() {
+	defer n.wg.Done()
+	txApplier := meteredTxApplier{TxApplier: n.state.Pending, metrics: n.metrics}
+	eth := rpc.NewEthSrv(
+		n.cfg.BlockStoreDir, n.state, n.state.Pending, txApplier, n.txRelay,
+	)
+	n.listenHTTP("JSON-RPC", n.cfg.EthRPCAddr, eth)
+}
+
+// serveMetrics serves the Prometheus metrics of the node
+func (n *Node) serveMetrics() {
+	defer n.wg.Done()
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", n.metrics.Handler())
+	n.listenHTTP("Metrics", n.cfg.MetricsAddr, mux)
+}
+
+// listenHTTP serves the handler until the node stops
+func (n *Node) listenHTTP(name, addr string, handler http.Handler) {
+	srv := &http.Server{
+		Addr:        addr,
+		Handler:     handler,
+		BaseContext: func(net.Listener) context.Context { return n.ctx },
+	}
+	go func() {
+		<-n.ctx.Done()
+		_ = srv.Shutdown(context.Background())
+	}()
+	n.component("node").Info("Serve "+name, "addr", addr)
+	err := srv.ListenAndServe()
+	if err != nil && err != http.ErrServerClosed {
+		n.fail(err)
+	}
+}
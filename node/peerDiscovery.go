@@ -2,7 +2,7 @@ package node
 
 import (
 	"context"
-	"fmt"
+	"log/slog"
 	"sync"
 	"time"
 
@@ -15,6 +15,8 @@ type PeerDiscoveryCfg struct {
 	NodeAddr  string
 	Bootstrap bool
 	SeedAddr  []string
+	// MaxPeers limits the known peers, zero means no limit
+	MaxPeers int
 }
 
 type PeerReader interface {
@@ -28,9 +30,10 @@ type PeerDiscovery struct {
 	wg    *sync.WaitGroup
 	mtx   sync.RWMutex
 	peers map[string]struct{}
+	log   *slog.Logger
 }
 
-func NewPeerDiscovery(ctx context.Context, wg *sync.WaitGroup, cfg PeerDiscoveryCfg) *PeerDiscovery {
+func NewPeerDiscovery(ctx context.Context, wg *sync.WaitGroup, cfg PeerDiscoveryCfg, log *slog.Logger) *PeerDiscovery {
 
 	peerDisc := &PeerDiscovery{
 		ctx:   ctx,
@@ -38,6 +41,7 @@ func NewPeerDiscovery(ctx context.Context, wg *sync.WaitGroup, cfg PeerDiscovery
 		cfg:   cfg,
 		mtx:   sync.RWMutex{},
 		peers: make(map[string]struct{}),
+		log:   log,
 	}
 	if !peerDisc.Bootstrap() {
 		peerDisc.AddPeers(peerDisc.cfg.SeedAddr...)
@@ -59,7 +63,11 @@ func (d *PeerDiscovery) AddPeers(peers ...string) {
 			_, exist := d.peers[peer]
 
 			if !exist {
-				fmt.Printf("<==> Peer %v \n ", peer)
+				if d.cfg.MaxPeers > 0 && len(d.peers) >= d.cfg.MaxPeers {
+					d.log.Debug("Peer limit", "peer", peer, "max", d.cfg.MaxPeers)
+					continue
+				}
+				d.log.Info("Peer added", "peer", peer)
 			}
 			d.peers[peer] = struct{}{}
 		}
@@ -98,7 +106,7 @@ func (d *PeerDiscovery) DiscoverPeers(period time.Duration) {
 				if peer != d.cfg.NodeAddr {
 					peers, err := d.grpcPeerDiscover(peer)
 					if err != nil {
-						fmt.Println(err)
+						d.log.Warn("Peer discover", "peer", peer, "err", err)
 						continue
 					}
 					d.AddPeers(peers...)
@@ -112,6 +120,7 @@ func (d *PeerDiscovery) DiscoverPeers(period time.Duration) {
 func (d *PeerDiscovery) grpcPeerDiscover(peer string) ([]string, error) {
 	conn, err := grpc.NewClient(
 		peer, grpc.WithTransportCredentials(insecure.NewCredentials()),
+		rpc.WithMaxMsgSize(),
 	)
 
 	if err != nil {
@@ -197,6 +197,126 @@ func (x *AccountBalanceRes) GetBalance() uint64 {
 	return 0
 }
 
+type AccountHistoryReq struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Address       string                 `protobuf:"bytes,1,opt,name=Address,proto3" json:"Address,omitempty"`
+	FromNumber    uint64                 `protobuf:"varint,2,opt,name=FromNumber,proto3" json:"FromNumber,omitempty"`
+	ToNumber      uint64                 `protobuf:"varint,3,opt,name=ToNumber,proto3" json:"ToNumber,omitempty"`
+	Since         uint64                 `protobuf:"varint,4,opt,name=Since,proto3" json:"Since,omitempty"`
+	Until         uint64                 `protobuf:"varint,5,opt,name=Until,proto3" json:"Until,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AccountHistoryReq) Reset() {
+	*x = AccountHistoryReq{}
+	mi := &file_account_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AccountHistoryReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AccountHistoryReq) ProtoMessage() {}
+
+func (x *AccountHistoryReq) ProtoReflect() protoreflect.Message {
+	mi := &file_account_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AccountHistoryReq.ProtoReflect.Descriptor instead.
+func (*AccountHistoryReq) Descriptor() ([]byte, []int) {
+	return file_account_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *AccountHistoryReq) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *AccountHistoryReq) GetFromNumber() uint64 {
+	if x != nil {
+		return x.FromNumber
+	}
+	return 0
+}
+
+func (x *AccountHistoryReq) GetToNumber() uint64 {
+	if x != nil {
+		return x.ToNumber
+	}
+	return 0
+}
+
+func (x *AccountHistoryReq) GetSince() uint64 {
+	if x != nil {
+		return x.Since
+	}
+	return 0
+}
+
+func (x *AccountHistoryReq) GetUntil() uint64 {
+	if x != nil {
+		return x.Until
+	}
+	return 0
+}
+
+type AccountHistoryRes struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Entry         []byte                 `protobuf:"bytes,1,opt,name=Entry,proto3" json:"Entry,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AccountHistoryRes) Reset() {
+	*x = AccountHistoryRes{}
+	mi := &file_account_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AccountHistoryRes) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AccountHistoryRes) ProtoMessage() {}
+
+func (x *AccountHistoryRes) ProtoReflect() protoreflect.Message {
+	mi := &file_account_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AccountHistoryRes.ProtoReflect.Descriptor instead.
+func (*AccountHistoryRes) Descriptor() ([]byte, []int) {
+	return file_account_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *AccountHistoryRes) GetEntry() []byte {
+	if x != nil {
+		return x.Entry
+	}
+	return nil
+}
+
 var File_account_proto protoreflect.FileDescriptor
 
 const file_account_proto_rawDesc = "" +
@@ -209,10 +329,21 @@ const file_account_proto_rawDesc = "" +
 	"\x11AccountBalanceReq\x12\x18\n" +
 	"\aAddress\x18\x01 \x01(\tR\aAddress\"-\n" +
 	"\x11AccountBalanceRes\x12\x18\n" +
-	"\aBalance\x18\x01 \x01(\x04R\aBalance2z\n" +
+	"\aBalance\x18\x01 \x01(\x04R\aBalance\"\x95\x01\n" +
+	"\x11AccountHistoryReq\x12\x18\n" +
+	"\aAddress\x18\x01 \x01(\tR\aAddress\x12\x1e\n" +
+	"\n" +
+	"FromNumber\x18\x02 \x01(\x04R\n" +
+	"FromNumber\x12\x1a\n" +
+	"\bToNumber\x18\x03 \x01(\x04R\bToNumber\x12\x14\n" +
+	"\x05Since\x18\x04 \x01(\x04R\x05Since\x12\x14\n" +
+	"\x05Until\x18\x05 \x01(\x04R\x05Until\")\n" +
+	"\x11AccountHistoryRes\x12\x14\n" +
+	"\x05Entry\x18\x01 \x01(\fR\x05Entry2\xb6\x01\n" +
 	"\aAccount\x125\n" +
 	"\rAccountCreate\x12\x11.AccountCreateReq\x1a\x11.AccountCreateRes\x128\n" +
-	"\x0eAccountBalance\x12\x12.AccountBalanceReq\x1a\x12.AccountBalanceResB\aZ\x05./rpcb\x06proto3"
+	"\x0eAccountBalance\x12\x12.AccountBalanceReq\x1a\x12.AccountBalanceRes\x12:\n" +
+	"\x0eAccountHistory\x12\x12.AccountHistoryReq\x1a\x12.AccountHistoryRes0\x01B\aZ\x05./rpcb\x06proto3"
 
 var (
 	file_account_proto_rawDescOnce sync.Once
@@ -226,20 +357,24 @@ func file_account_proto_rawDescGZIP() []byte {
 	return file_account_proto_rawDescData
 }
 
-var file_account_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_account_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
 var file_account_proto_goTypes = []any{
 	(*AccountCreateReq)(nil),  // 0: AccountCreateReq
 	(*AccountCreateRes)(nil),  // 1: AccountCreateRes
 	(*AccountBalanceReq)(nil), // 2: AccountBalanceReq
 	(*AccountBalanceRes)(nil), // 3: AccountBalanceRes
+	(*AccountHistoryReq)(nil), // 4: AccountHistoryReq
+	(*AccountHistoryRes)(nil), // 5: AccountHistoryRes
 }
 var file_account_proto_depIdxs = []int32{
 	0, // 0: Account.AccountCreate:input_type -> AccountCreateReq
 	2, // 1: Account.AccountBalance:input_type -> AccountBalanceReq
-	1, // 2: Account.AccountCreate:output_type -> AccountCreateRes
-	3, // 3: Account.AccountBalance:output_type -> AccountBalanceRes
-	2, // [2:4] is the sub-list for method output_type
-	0, // [0:2] is the sub-list for method input_type
+	4, // 2: Account.AccountHistory:input_type -> AccountHistoryReq
+	1, // 3: Account.AccountCreate:output_type -> AccountCreateRes
+	3, // 4: Account.AccountBalance:output_type -> AccountBalanceRes
+	5, // 5: Account.AccountHistory:output_type -> AccountHistoryRes
+	3, // [3:6] is the sub-list for method output_type
+	0, // [0:3] is the sub-list for method input_type
 	0, // [0:0] is the sub-list for extension type_name
 	0, // [0:0] is the sub-list for extension extendee
 	0, // [0:0] is the sub-list for field type_name
@@ -256,7 +391,7 @@ func file_account_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_account_proto_rawDesc), len(file_account_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   4,
+			NumMessages:   6,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
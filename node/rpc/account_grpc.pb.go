@@ -21,6 +21,7 @@ const _ = grpc.SupportPackageIsVersion9
 const (
 	Account_AccountCreate_FullMethodName  = "/Account/AccountCreate"
 	Account_AccountBalance_FullMethodName = "/Account/AccountBalance"
+	Account_AccountHistory_FullMethodName = "/Account/AccountHistory"
 )
 
 // AccountClient is the client API for Account service.
@@ -29,6 +30,7 @@ const (
 type AccountClient interface {
 	AccountCreate(ctx context.Context, in *AccountCreateReq, opts ...grpc.CallOption) (*AccountCreateRes, error)
 	AccountBalance(ctx context.Context, in *AccountBalanceReq, opts ...grpc.CallOption) (*AccountBalanceRes, error)
+	AccountHistory(ctx context.Context, in *AccountHistoryReq, opts ...grpc.CallOption) (grpc.ServerStreamingClient[AccountHistoryRes], error)
 }
 
 type accountClient struct {
@@ -59,12 +61,32 @@ func (c *accountClient) AccountBalance(ctx context.Context, in *AccountBalanceRe
 	return out, nil
 }
 
+func (c *accountClient) AccountHistory(ctx context.Context, in *AccountHistoryReq, opts ...grpc.CallOption) (grpc.ServerStreamingClient[AccountHistoryRes], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Account_ServiceDesc.Streams[0], Account_AccountHistory_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[AccountHistoryReq, AccountHistoryRes]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Account_AccountHistoryClient = grpc.ServerStreamingClient[AccountHistoryRes]
+
 // AccountServer is the server API for Account service.
 // All implementations must embed UnimplementedAccountServer
 // for forward compatibility.
 type AccountServer interface {
 	AccountCreate(context.Context, *AccountCreateReq) (*AccountCreateRes, error)
 	AccountBalance(context.Context, *AccountBalanceReq) (*AccountBalanceRes, error)
+	AccountHistory(*AccountHistoryReq, grpc.ServerStreamingServer[AccountHistoryRes]) error
 	mustEmbedUnimplementedAccountServer()
 }
 
@@ -81,6 +103,9 @@ func (UnimplementedAccountServer) AccountCreate(context.Context, *AccountCreateR
 func (UnimplementedAccountServer) AccountBalance(context.Context, *AccountBalanceReq) (*AccountBalanceRes, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method AccountBalance not implemented")
 }
+func (UnimplementedAccountServer) AccountHistory(*AccountHistoryReq, grpc.ServerStreamingServer[AccountHistoryRes]) error {
+	return status.Errorf(codes.Unimplemented, "method AccountHistory not implemented")
+}
 func (UnimplementedAccountServer) mustEmbedUnimplementedAccountServer() {}
 func (UnimplementedAccountServer) testEmbeddedByValue()                 {}
 
@@ -138,6 +163,17 @@ func _Account_AccountBalance_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Account_AccountHistory_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(AccountHistoryReq)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AccountServer).AccountHistory(m, &grpc.GenericServerStream[AccountHistoryReq, AccountHistoryRes]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Account_AccountHistoryServer = grpc.ServerStreamingServer[AccountHistoryRes]
+
 // Account_ServiceDesc is the grpc.ServiceDesc for Account service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -154,6 +190,12 @@ var Account_ServiceDesc = grpc.ServiceDesc{
 			Handler:    _Account_AccountBalance_Handler,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "AccountHistory",
+			Handler:       _Account_AccountHistory_Handler,
+			ServerStreams: true,
+		},
+	},
 	Metadata: "account.proto",
 }
@@ -2,8 +2,10 @@ package rpc
 
 import (
 	"context"
+	"encoding/json"
 
 	"github.com/Ansh1902396/chain"
+	"google.golang.org/grpc"
 	codes "google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
@@ -14,14 +16,18 @@ type BalanceChecker interface {
 
 type AccountSrv struct {
 	UnimplementedAccountServer
-	keyStoreDir string
-	balChecker  BalanceChecker
+	keyStoreDir   string
+	blockStoreDir string
+	balChecker    BalanceChecker
 }
 
-func NewAccountSrv(keyStoreDir string, balChecker BalanceChecker) *AccountSrv {
+func NewAccountSrv(
+	keyStoreDir, blockStoreDir string, balChecker BalanceChecker,
+) *AccountSrv {
 	return &AccountSrv{
-		keyStoreDir: keyStoreDir,
-		balChecker:  balChecker,
+		keyStoreDir:   keyStoreDir,
+		blockStoreDir: blockStoreDir,
+		balChecker:    balChecker,
 	}
 }
 
@@ -59,3 +65,60 @@ func (s *AccountSrv) AccountBalance(ctx context.Context, req *AccountBalanceReq)
 
 	return &AccountBalanceRes{Balance: balance}, nil
 }
+
+// AccountHistory streams the balance changes of the account in the block and
+// time ranges. The running balances account for all preceding blocks
+func (s *AccountSrv) AccountHistory(
+	req *AccountHistoryReq, stream grpc.ServerStreamingServer[AccountHistoryRes],
+) error {
+	if len(req.Address) == 0 {
+		return status.Errorf(codes.InvalidArgument, "account address is required")
+	}
+	gen, err := chain.ReadGenesis(s.blockStoreDir)
+	if err != nil {
+		return status.Error(codes.NotFound, err.Error())
+	}
+	blocks, closeBlocks, err := chain.ReadBlocks(s.blockStoreDir)
+	if err != nil {
+		return status.Error(codes.NotFound, err.Error())
+	}
+	defer closeBlocks()
+	send := func(entries []chain.HistoryEntry) error {
+		for _, entry := range entries {
+			if !inRange(
+				entry.BlockNumber, entry.Time,
+				req.FromNumber, req.ToNumber, req.Since, req.Until,
+			) {
+				continue
+			}
+			jentry, err := json.Marshal(entry)
+			if err != nil {
+				return status.Error(codes.Internal, err.Error())
+			}
+			res := &AccountHistoryRes{Entry: jentry}
+			err = stream.Send(res)
+			if err != nil {
+				return status.Error(codes.Internal, err.Error())
+			}
+		}
+		return nil
+	}
+	hist := chain.NewHistory(chain.Address(req.Address))
+	err = send(hist.ApplyGenesis(gen.Genesis))
+	if err != nil {
+		return err
+	}
+	for err, blk := range blocks {
+		if err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+		if req.ToNumber > 0 && blk.Number > req.ToNumber {
+			break
+		}
+		err = send(hist.ApplyBlock(blk))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
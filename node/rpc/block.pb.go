@@ -104,6 +104,7 @@ func (x *GenesisSyncRes) GetGenesis() []byte {
 type BlockReceiveReq struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Block         []byte                 `protobuf:"bytes,1,opt,name=Block,proto3" json:"Block,omitempty"`
+	Trace         map[string]string      `protobuf:"bytes,2,rep,name=Trace,proto3" json:"Trace,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -145,6 +146,13 @@ func (x *BlockReceiveReq) GetBlock() []byte {
 	return nil
 }
 
+func (x *BlockReceiveReq) GetTrace() map[string]string {
+	if x != nil {
+		return x.Trace
+	}
+	return nil
+}
+
 type BlockReceiveRes struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	unknownFields protoimpl.UnknownFields
@@ -186,6 +194,14 @@ type BlockSearchReq struct {
 	Number        uint64                 `protobuf:"varint,1,opt,name=Number,proto3" json:"Number,omitempty"`
 	Hash          string                 `protobuf:"bytes,2,opt,name=Hash,proto3" json:"Hash,omitempty"`
 	Parent        string                 `protobuf:"bytes,3,opt,name=Parent,proto3" json:"Parent,omitempty"`
+	FromNumber    uint64                 `protobuf:"varint,4,opt,name=FromNumber,proto3" json:"FromNumber,omitempty"`
+	ToNumber      uint64                 `protobuf:"varint,5,opt,name=ToNumber,proto3" json:"ToNumber,omitempty"`
+	Since         uint64                 `protobuf:"varint,6,opt,name=Since,proto3" json:"Since,omitempty"`
+	Until         uint64                 `protobuf:"varint,7,opt,name=Until,proto3" json:"Until,omitempty"`
+	Limit         uint64                 `protobuf:"varint,8,opt,name=Limit,proto3" json:"Limit,omitempty"`
+	Offset        uint64                 `protobuf:"varint,9,opt,name=Offset,proto3" json:"Offset,omitempty"`
+	Cursor        string                 `protobuf:"bytes,10,opt,name=Cursor,proto3" json:"Cursor,omitempty"`
+	Desc          bool                   `protobuf:"varint,11,opt,name=Desc,proto3" json:"Desc,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -241,9 +257,67 @@ func (x *BlockSearchReq) GetParent() string {
 	return ""
 }
 
+func (x *BlockSearchReq) GetFromNumber() uint64 {
+	if x != nil {
+		return x.FromNumber
+	}
+	return 0
+}
+
+func (x *BlockSearchReq) GetToNumber() uint64 {
+	if x != nil {
+		return x.ToNumber
+	}
+	return 0
+}
+
+func (x *BlockSearchReq) GetSince() uint64 {
+	if x != nil {
+		return x.Since
+	}
+	return 0
+}
+
+func (x *BlockSearchReq) GetUntil() uint64 {
+	if x != nil {
+		return x.Until
+	}
+	return 0
+}
+
+func (x *BlockSearchReq) GetLimit() uint64 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *BlockSearchReq) GetOffset() uint64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *BlockSearchReq) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+func (x *BlockSearchReq) GetDesc() bool {
+	if x != nil {
+		return x.Desc
+	}
+	return false
+}
+
 type BlockSearchRes struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Block         []byte                 `protobuf:"bytes,1,opt,name=Block,proto3" json:"Block,omitempty"`
+	Total         uint64                 `protobuf:"varint,2,opt,name=Total,proto3" json:"Total,omitempty"`
+	Cursor        string                 `protobuf:"bytes,3,opt,name=Cursor,proto3" json:"Cursor,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -285,6 +359,20 @@ func (x *BlockSearchRes) GetBlock() []byte {
 	return nil
 }
 
+func (x *BlockSearchRes) GetTotal() uint64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *BlockSearchRes) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
 type BlockSyncReq struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Number        uint64                 `protobuf:"varint,1,opt,name=Number,proto3" json:"Number,omitempty"`
@@ -380,16 +468,34 @@ const file_block_proto_rawDesc = "" +
 	"\vblock.proto\"\x10\n" +
 	"\x0eGenesisSyncReq\"*\n" +
 	"\x0eGenesisSyncRes\x12\x18\n" +
-	"\aGenesis\x18\x01 \x01(\fR\aGenesis\"'\n" +
+	"\aGenesis\x18\x01 \x01(\fR\aGenesis\"\x94\x01\n" +
 	"\x0fBlockReceiveReq\x12\x14\n" +
-	"\x05Block\x18\x01 \x01(\fR\x05Block\"\x11\n" +
-	"\x0fBlockReceiveRes\"T\n" +
+	"\x05Block\x18\x01 \x01(\fR\x05Block\x121\n" +
+	"\x05Trace\x18\x02 \x03(\v2\x1b.BlockReceiveReq.TraceEntryR\x05Trace\x1a8\n" +
+	"\n" +
+	"TraceEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x11\n" +
+	"\x0fBlockReceiveRes\"\x96\x02\n" +
 	"\x0eBlockSearchReq\x12\x16\n" +
 	"\x06Number\x18\x01 \x01(\x04R\x06Number\x12\x12\n" +
 	"\x04Hash\x18\x02 \x01(\tR\x04Hash\x12\x16\n" +
-	"\x06Parent\x18\x03 \x01(\tR\x06Parent\"&\n" +
+	"\x06Parent\x18\x03 \x01(\tR\x06Parent\x12\x1e\n" +
+	"\n" +
+	"FromNumber\x18\x04 \x01(\x04R\n" +
+	"FromNumber\x12\x1a\n" +
+	"\bToNumber\x18\x05 \x01(\x04R\bToNumber\x12\x14\n" +
+	"\x05Since\x18\x06 \x01(\x04R\x05Since\x12\x14\n" +
+	"\x05Until\x18\a \x01(\x04R\x05Until\x12\x14\n" +
+	"\x05Limit\x18\b \x01(\x04R\x05Limit\x12\x16\n" +
+	"\x06Offset\x18\t \x01(\x04R\x06Offset\x12\x16\n" +
+	"\x06Cursor\x18\n" +
+	" \x01(\tR\x06Cursor\x12\x12\n" +
+	"\x04Desc\x18\v \x01(\bR\x04Desc\"T\n" +
 	"\x0eBlockSearchRes\x12\x14\n" +
-	"\x05Block\x18\x01 \x01(\fR\x05Block\"&\n" +
+	"\x05Block\x18\x01 \x01(\fR\x05Block\x12\x14\n" +
+	"\x05Total\x18\x02 \x01(\x04R\x05Total\x12\x16\n" +
+	"\x06Cursor\x18\x03 \x01(\tR\x06Cursor\"&\n" +
 	"\fBlockSyncReq\x12\x16\n" +
 	"\x06Number\x18\x01 \x01(\x04R\x06Number\"$\n" +
 	"\fBlockSyncRes\x12\x14\n" +
@@ -412,7 +518,7 @@ func file_block_proto_rawDescGZIP() []byte {
 	return file_block_proto_rawDescData
 }
 
-var file_block_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_block_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
 var file_block_proto_goTypes = []any{
 	(*GenesisSyncReq)(nil),  // 0: GenesisSyncReq
 	(*GenesisSyncRes)(nil),  // 1: GenesisSyncRes
@@ -422,21 +528,23 @@ var file_block_proto_goTypes = []any{
 	(*BlockSearchRes)(nil),  // 5: BlockSearchRes
 	(*BlockSyncReq)(nil),    // 6: BlockSyncReq
 	(*BlockSyncRes)(nil),    // 7: BlockSyncRes
+	nil,                     // 8: BlockReceiveReq.TraceEntry
 }
 var file_block_proto_depIdxs = []int32{
-	4, // 0: Block.BlockSearch:input_type -> BlockSearchReq
-	0, // 1: Block.GenesisSync:input_type -> GenesisSyncReq
-	6, // 2: Block.BlockSync:input_type -> BlockSyncReq
-	2, // 3: Block.BlockReceive:input_type -> BlockReceiveReq
-	5, // 4: Block.BlockSearch:output_type -> BlockSearchRes
-	1, // 5: Block.GenesisSync:output_type -> GenesisSyncRes
-	7, // 6: Block.BlockSync:output_type -> BlockSyncRes
-	3, // 7: Block.BlockReceive:output_type -> BlockReceiveRes
-	4, // [4:8] is the sub-list for method output_type
-	0, // [0:4] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	8, // 0: BlockReceiveReq.Trace:type_name -> BlockReceiveReq.TraceEntry
+	4, // 1: Block.BlockSearch:input_type -> BlockSearchReq
+	0, // 2: Block.GenesisSync:input_type -> GenesisSyncReq
+	6, // 3: Block.BlockSync:input_type -> BlockSyncReq
+	2, // 4: Block.BlockReceive:input_type -> BlockReceiveReq
+	5, // 5: Block.BlockSearch:output_type -> BlockSearchRes
+	1, // 6: Block.GenesisSync:output_type -> GenesisSyncRes
+	7, // 7: Block.BlockSync:output_type -> BlockSyncRes
+	3, // 8: Block.BlockReceive:output_type -> BlockReceiveRes
+	5, // [5:9] is the sub-list for method output_type
+	1, // [1:5] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
 }
 
 func init() { file_block_proto_init() }
@@ -450,7 +558,7 @@ func file_block_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_block_proto_rawDesc), len(file_block_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   8,
+			NumMessages:   9,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
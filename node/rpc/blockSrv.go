@@ -3,16 +3,21 @@ package rpc
 import (
 	"context"
 	"encoding/json"
-	"fmt"
+	"errors"
 	"io"
+	"log/slog"
 	"strings"
 
 	"github.com/Ansh1902396/chain"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
 	codes "google.golang.org/grpc/codes"
 	status "google.golang.org/grpc/status"
 )
 
+// BlockApplier applies a block to the state and persists the block as one
+// step
 type BlockApplier interface {
 	ApplyBlockToState(blk chain.SigBlock) error
 }
@@ -20,29 +25,52 @@ type BlockApplier interface {
 type BlockRelayer interface {
 	RelayBlock(block chain.SigBlock)
 }
+
+type BlockObserver interface {
+	ObserveBlock(blk chain.SigBlock)
+}
+
 type BlockSrv struct {
 	UnimplementedBlockServer
 	blockStoreDir string
 	blockApplier  BlockApplier
 	eventPub      chain.EventPublisher
 	blkRelayer    BlockRelayer
+	blkObserver   BlockObserver
+	traceStore    TraceStore
+	log           *slog.Logger
 }
 
-func NewBlockSrv(blockStoreDir string, blockApplier BlockApplier, eventPub chain.EventPublisher, blkRelayer BlockRelayer) *BlockSrv {
+func NewBlockSrv(
+	blockStoreDir string, blockApplier BlockApplier, eventPub chain.EventPublisher,
+	blkRelayer BlockRelayer, blkObserver BlockObserver, traceStore TraceStore,
+	log *slog.Logger,
+) *BlockSrv {
 	return &BlockSrv{
 		blockStoreDir: blockStoreDir,
 		blockApplier:  blockApplier,
 		eventPub:      eventPub,
 		blkRelayer:    blkRelayer,
+		blkObserver:   blkObserver,
+		traceStore:    traceStore,
+		log:           log,
 	}
 }
 
+// BlockSearch streams a page of the blocks that match all the set criteria
+// with the total number of matches after the cursor
 func (s *BlockSrv) BlockSearch(
 	req *BlockSearchReq, stream grpc.ServerStreamingServer[BlockSearchRes],
 ) error {
+	page, err := newSearchPage[chain.SigBlock](
+		req.Limit, req.Offset, req.Cursor, req.Desc,
+	)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
 	blocks, closeBlocks, err := chain.ReadBlocks(s.blockStoreDir)
 	if err != nil {
-		return status.Errorf(codes.NotFound, err.Error())
+		return status.Error(codes.NotFound, err.Error())
 	}
 
 	defer closeBlocks()
@@ -50,24 +78,30 @@ func (s *BlockSrv) BlockSearch(
 	prefix := strings.HasPrefix
 	for err, blk := range blocks {
 		if err != nil {
-			return status.Errorf(codes.Internal, err.Error())
+			return status.Error(codes.Internal, err.Error())
 		}
 
-		if req.Number != 0 && blk.Number == req.Number || len(req.Hash) > 0 && prefix(blk.Hash().String(), req.Hash) ||
-			len(req.Parent) > 0 && prefix(blk.Parent.String(), req.Parent) {
-			jblk, err := json.Marshal(blk)
+		if (req.Number == 0 || blk.Number == req.Number) &&
+			(len(req.Hash) == 0 || prefix(blk.Hash().String(), req.Hash)) &&
+			(len(req.Parent) == 0 || prefix(blk.Parent.String(), req.Parent)) &&
+			blockInRange(blk, req.FromNumber, req.ToNumber, req.Since, req.Until) {
+			page.add(searchPos{blk.Number}, blk)
+		}
+	}
+	for _, match := range page.page() {
+		jblk, err := json.Marshal(match.item)
 
-			if err != nil {
-				return status.Errorf(codes.Internal, err.Error())
-			}
+		if err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
 
-			res := &BlockSearchRes{Block: jblk}
+		res := &BlockSearchRes{
+			Block: jblk, Total: page.total, Cursor: match.pos.String(),
+		}
 
-			err = stream.Send(res)
-			if err != nil {
-				return status.Errorf(codes.Internal, err.Error())
-			}
-			break
+		err = stream.Send(res)
+		if err != nil {
+			return status.Error(codes.Internal, err.Error())
 		}
 	}
 	return nil
@@ -78,7 +112,7 @@ func (s *BlockSrv) GenesisSync(
 ) (*GenesisSyncRes, error) {
 	jgen, err := chain.ReadGenesisBytes(s.blockStoreDir)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, err.Error())
+		return nil, status.Error(codes.Internal, err.Error())
 	}
 	res := &GenesisSyncRes{Genesis: jgen}
 	return res, nil
@@ -90,7 +124,7 @@ func (s *BlockSrv) BlockSync(
 	blocks, closeBlocks, err := chain.ReadBlocksBytes(s.blockStoreDir)
 
 	if err != nil {
-		return status.Errorf(codes.NotFound, err.Error())
+		return status.Error(codes.NotFound, err.Error())
 	}
 	defer closeBlocks()
 
@@ -98,13 +132,13 @@ func (s *BlockSrv) BlockSync(
 
 	for err, jblk := range blocks {
 		if err != nil {
-			return status.Errorf(codes.Internal, err.Error())
+			return status.Error(codes.Internal, err.Error())
 		}
 		if i >= num {
 			res := &BlockSyncRes{Block: jblk}
 			err = stream.Send(res)
 			if err != nil {
-				return status.Errorf(codes.Internal, err.Error())
+				return status.Error(codes.Internal, err.Error())
 			}
 		}
 		i++
@@ -114,17 +148,59 @@ func (s *BlockSrv) BlockSync(
 }
 
 func (s *BlockSrv) publishBlockAndTxs(blk chain.SigBlock) {
+	publish := func(evType chain.EventType, action string, body []byte) {
+		event := chain.NewEvent(evType, action, body)
+		event.Height = blk.Number
+		s.eventPub.PublishEvent(event)
+	}
 	jblk, _ := json.Marshal(blk)
-	event := chain.NewEvent(chain.EvBlock, "validated", jblk)
-	s.eventPub.PublishEvent(event)
+	publish(chain.EvBlock, "validated", jblk)
 	for _, tx := range blk.Txs {
 		jtx, _ := json.Marshal(tx)
-		event := chain.NewEvent(chain.EvTx, "validated", jtx)
-		s.eventPub.PublishEvent(event)
+		publish(chain.EvTx, "validated", jtx)
+		if tx.HTLC != nil {
+			publish(chain.EvHTLC, tx.HTLC.Op, jtx)
+		}
 	}
 }
 
-func (s *BlockSrv) BlockRecive(
+// applyBlock applies the block to the state in a span of the block trace,
+// then ends the traces of its transactions with a commit span
+func (s *BlockSrv) applyBlock(
+	ctx context.Context, carrier map[string]string, blk chain.SigBlock,
+) error {
+	ctx = ExtractTrace(ctx, carrier)
+	ctx, span := tracer.Start(
+		ctx, "block.receive", BlockSpanAttrs(blk),
+		trace.WithSpanKind(trace.SpanKindConsumer),
+	)
+	defer span.End()
+	err := s.blockApplier.ApplyBlockToState(blk)
+	if err != nil {
+		if !errors.Is(err, chain.ErrBlockNumber) {
+			span.SetStatus(otelcodes.Error, err.Error())
+		}
+		return err
+	}
+	if s.traceStore == nil {
+		return nil
+	}
+	s.traceStore.SaveTrace(ctx, blk.Hash())
+	for _, tx := range blk.Txs {
+		txCtx, exist := s.traceStore.TraceContext(ctx, tx.Hash())
+		if !exist {
+			continue
+		}
+		_, txSpan := tracer.Start(
+			txCtx, "tx.commit", TxSpanAttrs(tx), BlockSpanAttrs(blk),
+			trace.WithLinks(trace.LinkFromContext(ctx)),
+		)
+		txSpan.End()
+	}
+	return nil
+}
+
+func (s *BlockSrv) BlockReceive(
 	stream grpc.ClientStreamingServer[BlockReceiveReq, BlockReceiveRes],
 ) error {
 	for {
@@ -135,7 +211,7 @@ func (s *BlockSrv) BlockRecive(
 		}
 
 		if err != nil {
-			return status.Errorf(codes.Internal, err.Error())
+			return status.Error(codes.Internal, err.Error())
 		}
 
 		var blk chain.SigBlock
@@ -143,17 +219,28 @@ func (s *BlockSrv) BlockRecive(
 		err = json.Unmarshal(req.Block, &blk)
 
 		if err != nil {
-			fmt.Println(err)
+			s.log.Warn("Block decode", "err", err)
 			continue
 		}
-		fmt.Printf("<=== Block recive \n%v", blk)
-		err = s.blockApplier.ApplyBlockToState(blk)
+		log := s.log.With("block", blk.Number)
+		log.Debug("Block receive", "hash", blk.Hash().String())
+		if s.blkObserver != nil {
+			s.blkObserver.ObserveBlock(blk)
+		}
+		err = s.applyBlock(stream.Context(), req.Trace, blk)
 
 		if err != nil {
-			fmt.Print(err)
+			// Relayed blocks arrive again after being applied
+			if errors.Is(err, chain.ErrBlockNumber) {
+				log.Debug("Block skipped", "err", err)
+			} else {
+				log.Warn("Block rejected", "err", err)
+			}
 			continue
 		}
 
+		log.Info("Block applied", "txs", len(blk.Txs), "hash", blk.Hash().String())
+
 		if s.blkRelayer != nil {
 			s.blkRelayer.RelayBlock(blk)
 		}
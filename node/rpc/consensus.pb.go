@@ -0,0 +1,164 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.7
+// 	protoc        v5.29.3
+// source: consensus.proto
+
+package rpc
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ConsensusReceiveReq struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Msg           []byte                 `protobuf:"bytes,1,opt,name=Msg,proto3" json:"Msg,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConsensusReceiveReq) Reset() {
+	*x = ConsensusReceiveReq{}
+	mi := &file_consensus_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConsensusReceiveReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConsensusReceiveReq) ProtoMessage() {}
+
+func (x *ConsensusReceiveReq) ProtoReflect() protoreflect.Message {
+	mi := &file_consensus_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConsensusReceiveReq.ProtoReflect.Descriptor instead.
+func (*ConsensusReceiveReq) Descriptor() ([]byte, []int) {
+	return file_consensus_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ConsensusReceiveReq) GetMsg() []byte {
+	if x != nil {
+		return x.Msg
+	}
+	return nil
+}
+
+type ConsensusReceiveRes struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConsensusReceiveRes) Reset() {
+	*x = ConsensusReceiveRes{}
+	mi := &file_consensus_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConsensusReceiveRes) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConsensusReceiveRes) ProtoMessage() {}
+
+func (x *ConsensusReceiveRes) ProtoReflect() protoreflect.Message {
+	mi := &file_consensus_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConsensusReceiveRes.ProtoReflect.Descriptor instead.
+func (*ConsensusReceiveRes) Descriptor() ([]byte, []int) {
+	return file_consensus_proto_rawDescGZIP(), []int{1}
+}
+
+var File_consensus_proto protoreflect.FileDescriptor
+
+const file_consensus_proto_rawDesc = "" +
+	"\n" +
+	"\x0fconsensus.proto\"'\n" +
+	"\x13ConsensusReceiveReq\x12\x10\n" +
+	"\x03Msg\x18\x01 \x01(\fR\x03Msg\"\x15\n" +
+	"\x13ConsensusReceiveRes2M\n" +
+	"\tConsensus\x12@\n" +
+	"\x10ConsensusReceive\x12\x14.ConsensusReceiveReq\x1a\x14.ConsensusReceiveRes(\x01B\aZ\x05./rpcb\x06proto3"
+
+var (
+	file_consensus_proto_rawDescOnce sync.Once
+	file_consensus_proto_rawDescData []byte
+)
+
+func file_consensus_proto_rawDescGZIP() []byte {
+	file_consensus_proto_rawDescOnce.Do(func() {
+		file_consensus_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_consensus_proto_rawDesc), len(file_consensus_proto_rawDesc)))
+	})
+	return file_consensus_proto_rawDescData
+}
+
+var file_consensus_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_consensus_proto_goTypes = []any{
+	(*ConsensusReceiveReq)(nil), // 0: ConsensusReceiveReq
+	(*ConsensusReceiveRes)(nil), // 1: ConsensusReceiveRes
+}
+var file_consensus_proto_depIdxs = []int32{
+	0, // 0: Consensus.ConsensusReceive:input_type -> ConsensusReceiveReq
+	1, // 1: Consensus.ConsensusReceive:output_type -> ConsensusReceiveRes
+	1, // [1:2] is the sub-list for method output_type
+	0, // [0:1] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_consensus_proto_init() }
+func file_consensus_proto_init() {
+	if File_consensus_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_consensus_proto_rawDesc), len(file_consensus_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_consensus_proto_goTypes,
+		DependencyIndexes: file_consensus_proto_depIdxs,
+		MessageInfos:      file_consensus_proto_msgTypes,
+	}.Build()
+	File_consensus_proto = out.File
+	file_consensus_proto_goTypes = nil
+	file_consensus_proto_depIdxs = nil
+}
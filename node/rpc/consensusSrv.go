@@ -0,0 +1,54 @@
+package rpc
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+
+	"github.com/Ansh1902396/chain"
+	"google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+type ConsensusHandler interface {
+	HandleConsensus(msg chain.ConsensusMsg)
+}
+
+type ConsensusRelayer interface {
+	RelayConsensus(msg chain.ConsensusMsg)
+}
+
+// ConsensusSrv receives the proposals and the votes of the validators
+type ConsensusSrv struct {
+	UnimplementedConsensusServer
+	handler ConsensusHandler
+	log     *slog.Logger
+}
+
+func NewConsensusSrv(handler ConsensusHandler, log *slog.Logger) *ConsensusSrv {
+	return &ConsensusSrv{handler: handler, log: log}
+}
+
+func (s *ConsensusSrv) ConsensusReceive(
+	stream grpc.ClientStreamingServer[ConsensusReceiveReq, ConsensusReceiveRes],
+) error {
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			res := &ConsensusReceiveRes{}
+			return stream.SendAndClose(res)
+		}
+		if err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+
+		var msg chain.ConsensusMsg
+		err = json.Unmarshal(req.Msg, &msg)
+		if err != nil {
+			s.log.Warn("Consensus decode", "err", err)
+			continue
+		}
+		s.handler.HandleConsensus(msg)
+	}
+}
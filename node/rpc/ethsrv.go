@@ -0,0 +1,457 @@
+package rpc
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Ansh1902396/chain"
+)
+
+// JSON-RPC 2.0 error codes
+const (
+	ethParseError     = -32700
+	ethInvalidRequest = -32600
+	ethMethodNotFound = -32601
+	ethInvalidParams  = -32602
+	ethServerError    = -32000
+)
+
+type StateReader interface {
+	LastBlock() chain.SigBlock
+	Balance(acc chain.Address) (uint64, bool)
+	Nonce(acc chain.Address) uint64
+}
+
+type PendingReader interface {
+	StateReader
+	Tx(hash chain.Hash) (chain.SigTx, bool)
+}
+
+type ethReq struct {
+	JSONRPC string            `json:"jsonrpc"`
+	ID      json.RawMessage   `json:"id,omitempty"`
+	Method  string            `json:"method"`
+	Params  []json.RawMessage `json:"params"`
+}
+
+type ethErr struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *ethErr) Error() string {
+	return e.Message
+}
+
+type ethRes struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *ethErr         `json:"error,omitempty"`
+}
+
+type ethBlock struct {
+	Number           string `json:"number"`
+	Hash             string `json:"hash"`
+	ParentHash       string `json:"parentHash"`
+	TransactionsRoot string `json:"transactionsRoot"`
+	Timestamp        string `json:"timestamp"`
+	Transactions     []any  `json:"transactions"`
+}
+
+type ethTx struct {
+	Hash             string  `json:"hash"`
+	From             string  `json:"from"`
+	To               *string `json:"to"`
+	Value            string  `json:"value"`
+	Nonce            string  `json:"nonce"`
+	Input            string  `json:"input"`
+	BlockHash        *string `json:"blockHash"`
+	BlockNumber      *string `json:"blockNumber"`
+	TransactionIndex *string `json:"transactionIndex"`
+}
+
+// EthSrv serves a subset of the Ethereum JSON-RPC API over HTTP for block
+// explorers and monitoring tools. Hashes and addresses are 0x prefixed hex,
+// quantities are 0x prefixed hex numbers, and raw transactions are the hex
+// encoded JSON of a signed transaction
+type EthSrv struct {
+	blockStoreDir string
+	state         StateReader
+	pending       PendingReader
+	txApplier     TxApplier
+	txRelayer     TxRelayer
+}
+
+func NewEthSrv(
+	blockStoreDir string, state StateReader, pending PendingReader,
+	txApplier TxApplier, txRelayer TxRelayer,
+) *EthSrv {
+	return &EthSrv{
+		blockStoreDir: blockStoreDir,
+		state:         state,
+		pending:       pending,
+		txApplier:     txApplier,
+		txRelayer:     txRelayer,
+	}
+}
+
+func (s *EthSrv) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "JSON-RPC requires POST", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	body = []byte(strings.TrimSpace(string(body)))
+	// A batch is an array of requests answered with an array of responses
+	if len(body) > 0 && body[0] == '[' {
+		var reqs []json.RawMessage
+		err = json.Unmarshal(body, &reqs)
+		if err != nil || len(reqs) == 0 {
+			writeJSON(w, http.StatusOK, newEthErrRes(nil, ethInvalidRequest, "invalid batch"))
+			return
+		}
+		ress := make([]ethRes, 0, len(reqs))
+		for _, req := range reqs {
+			res, notify := s.call(req)
+			if !notify {
+				ress = append(ress, res)
+			}
+		}
+		if len(ress) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		writeJSON(w, http.StatusOK, ress)
+		return
+	}
+	res, notify := s.call(body)
+	if notify {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+func newEthErrRes(id json.RawMessage, code int, msg string) ethRes {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	return ethRes{JSONRPC: "2.0", ID: id, Error: &ethErr{Code: code, Message: msg}}
+}
+
+// call dispatches a request. Notifications, requests without an id, are
+// executed without a response
+func (s *EthSrv) call(data []byte) (ethRes, bool) {
+	var req ethReq
+	err := json.Unmarshal(data, &req)
+	if err != nil {
+		return newEthErrRes(nil, ethParseError, err.Error()), false
+	}
+	if req.JSONRPC != "2.0" || len(req.Method) == 0 {
+		return newEthErrRes(req.ID, ethInvalidRequest, "expected jsonrpc 2.0 request"), false
+	}
+	result, err := s.dispatch(req.Method, req.Params)
+	if req.ID == nil {
+		return ethRes{}, true
+	}
+	if err != nil {
+		if e, ok := err.(*ethErr); ok {
+			return newEthErrRes(req.ID, e.Code, e.Message), false
+		}
+		return newEthErrRes(req.ID, ethServerError, err.Error()), false
+	}
+	if result == nil {
+		result = json.RawMessage("null")
+	}
+	return ethRes{JSONRPC: "2.0", ID: req.ID, Result: result}, false
+}
+
+func (s *EthSrv) dispatch(method string, params []json.RawMessage) (any, error) {
+	switch method {
+	case "eth_blockNumber":
+		return ethQuantity(s.state.LastBlock().Number), nil
+	case "eth_getBalance":
+		return s.getBalance(params)
+	case "eth_getTransactionCount":
+		return s.getTransactionCount(params)
+	case "eth_getBlockByNumber":
+		return s.getBlockByNumber(params)
+	case "eth_getTransactionByHash":
+		return s.getTransactionByHash(params)
+	case "eth_sendRawTransaction":
+		return s.sendRawTransaction(params)
+	default:
+		return nil, &ethErr{
+			Code: ethMethodNotFound,
+			Message: fmt.Sprintf(
+				"method %v is not supported, supported methods are eth_blockNumber, "+
+					"eth_getBalance, eth_getTransactionCount, eth_getBlockByNumber, "+
+					"eth_getTransactionByHash, eth_sendRawTransaction", method,
+			),
+		}
+	}
+}
+
+func invalidParams(format string, args ...any) error {
+	return &ethErr{Code: ethInvalidParams, Message: fmt.Sprintf(format, args...)}
+}
+
+func ethQuantity(u uint64) string {
+	return "0x" + strconv.FormatUint(u, 16)
+}
+
+func ethHex(str string) string {
+	return "0x" + str
+}
+
+func readParam(params []json.RawMessage, i int, name string, val any) error {
+	if i >= len(params) {
+		return invalidParams("missing %v parameter", name)
+	}
+	err := json.Unmarshal(params[i], val)
+	if err != nil {
+		return invalidParams("invalid %v parameter: %v", name, err)
+	}
+	return nil
+}
+
+func readAddress(params []json.RawMessage, i int) (chain.Address, error) {
+	var str string
+	err := readParam(params, i, "address", &str)
+	if err != nil {
+		return "", err
+	}
+	str = strings.TrimPrefix(strings.ToLower(str), "0x")
+	_, err = hex.DecodeString(str)
+	if err != nil || len(str) != 64 {
+		return "", invalidParams("expected 32 byte hex address, got %v", params[i])
+	}
+	return chain.Address(str), nil
+}
+
+func readHash(params []json.RawMessage, i int) (chain.Hash, error) {
+	var str string
+	err := readParam(params, i, "hash", &str)
+	if err != nil {
+		return chain.Hash{}, err
+	}
+	hash, err := chain.DecodeHash(strings.TrimPrefix(str, "0x"))
+	if err != nil {
+		return chain.Hash{}, invalidParams("expected 32 byte hex hash, got %v", str)
+	}
+	return hash, nil
+}
+
+// readStateTag selects the confirmed state for latest and the pending pool
+// for pending. Historical states are not kept by the node
+func (s *EthSrv) readStateTag(params []json.RawMessage, i int) (StateReader, error) {
+	tag := "latest"
+	if i < len(params) {
+		err := readParam(params, i, "block", &tag)
+		if err != nil {
+			return nil, err
+		}
+	}
+	switch tag {
+	case "latest", "safe", "finalized":
+		return s.state, nil
+	case "pending":
+		return s.pending, nil
+	}
+	number, err := readQuantity(tag)
+	if err == nil && number == s.state.LastBlock().Number {
+		return s.state, nil
+	}
+	return nil, invalidParams("only the latest or pending state is available, got %v", tag)
+}
+
+func readQuantity(str string) (uint64, error) {
+	if !strings.HasPrefix(str, "0x") {
+		return 0, fmt.Errorf("expected 0x prefixed quantity, got %v", str)
+	}
+	return strconv.ParseUint(str[2:], 16, 64)
+}
+
+func (s *EthSrv) getBalance(params []json.RawMessage) (any, error) {
+	acc, err := readAddress(params, 0)
+	if err != nil {
+		return nil, err
+	}
+	state, err := s.readStateTag(params, 1)
+	if err != nil {
+		return nil, err
+	}
+	balance, _ := state.Balance(acc)
+	return ethQuantity(balance), nil
+}
+
+func (s *EthSrv) getTransactionCount(params []json.RawMessage) (any, error) {
+	acc, err := readAddress(params, 0)
+	if err != nil {
+		return nil, err
+	}
+	state, err := s.readStateTag(params, 1)
+	if err != nil {
+		return nil, err
+	}
+	return ethQuantity(state.Nonce(acc)), nil
+}
+
+func (s *EthSrv) getBlockByNumber(params []json.RawMessage) (any, error) {
+	var tag string
+	err := readParam(params, 0, "block", &tag)
+	if err != nil {
+		return nil, err
+	}
+	var full bool
+	if len(params) > 1 {
+		err = readParam(params, 1, "full transactions", &full)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var number uint64
+	switch tag {
+	case "latest", "pending", "safe", "finalized":
+		number = s.state.LastBlock().Number
+	case "earliest":
+		number = 1
+	default:
+		number, err = readQuantity(tag)
+		if err != nil {
+			return nil, invalidParams("%v", err)
+		}
+	}
+	blk, found, err := s.readBlock(func(blk chain.SigBlock) bool {
+		return blk.Number == number
+	})
+	if err != nil || !found {
+		return nil, err
+	}
+	return newEthBlock(blk, full), nil
+}
+
+// readBlock returns the first block of the block store that matches
+func (s *EthSrv) readBlock(
+	match func(blk chain.SigBlock) bool,
+) (chain.SigBlock, bool, error) {
+	blocks, closeBlocks, err := chain.ReadBlocks(s.blockStoreDir)
+	if err != nil {
+		return chain.SigBlock{}, false, err
+	}
+	defer closeBlocks()
+	for err, blk := range blocks {
+		if err != nil {
+			return chain.SigBlock{}, false, err
+		}
+		if match(blk) {
+			return blk, true, nil
+		}
+	}
+	return chain.SigBlock{}, false, nil
+}
+
+func newEthBlock(blk chain.SigBlock, full bool) ethBlock {
+	blkHash := blk.Hash()
+	eblk := ethBlock{
+		Number:           ethQuantity(blk.Number),
+		Hash:             ethHex(blkHash.String()),
+		ParentHash:       ethHex(blk.Parent.String()),
+		TransactionsRoot: ethHex(blk.MerkleRoot.String()),
+		Timestamp:        ethQuantity(uint64(blk.Time.Unix())),
+		Transactions:     make([]any, len(blk.Txs)),
+	}
+	for i, tx := range blk.Txs {
+		if full {
+			eblk.Transactions[i] = newEthTx(tx, &blk, blkHash, i)
+		} else {
+			eblk.Transactions[i] = ethHex(tx.Hash().String())
+		}
+	}
+	return eblk
+}
+
+// newEthTx maps a transaction, the block is nil for a pending transaction.
+// A batch transaction has no single recipient, its value is the batch total
+func newEthTx(tx chain.SigTx, blk *chain.SigBlock, blkHash chain.Hash, index int) ethTx {
+	total, _ := tx.Total()
+	etx := ethTx{
+		Hash:  ethHex(tx.Hash().String()),
+		From:  ethHex(string(tx.From)),
+		Value: ethQuantity(total),
+		Nonce: ethQuantity(tx.Nonce),
+		Input: "0x" + hex.EncodeToString(tx.Data),
+	}
+	if len(tx.To) > 0 {
+		to := ethHex(string(tx.To))
+		etx.To = &to
+	}
+	if blk != nil {
+		hash, number, idx := ethHex(blkHash.String()), ethQuantity(blk.Number),
+			ethQuantity(uint64(index))
+		etx.BlockHash, etx.BlockNumber, etx.TransactionIndex = &hash, &number, &idx
+	}
+	return etx
+}
+
+func (s *EthSrv) getTransactionByHash(params []json.RawMessage) (any, error) {
+	hash, err := readHash(params, 0)
+	if err != nil {
+		return nil, err
+	}
+	tx, exist := s.pending.Tx(hash)
+	if exist {
+		return newEthTx(tx, nil, chain.Hash{}, 0), nil
+	}
+	var index int
+	blk, found, err := s.readBlock(func(blk chain.SigBlock) bool {
+		for i, tx := range blk.Txs {
+			if tx.Hash() == hash {
+				index = i
+				return true
+			}
+		}
+		return false
+	})
+	if err != nil || !found {
+		return nil, err
+	}
+	return newEthTx(blk.Txs[index], &blk, blk.Hash(), index), nil
+}
+
+func (s *EthSrv) sendRawTransaction(params []json.RawMessage) (any, error) {
+	var str string
+	err := readParam(params, 0, "raw transaction", &str)
+	if err != nil {
+		return nil, err
+	}
+	jtx, err := hex.DecodeString(strings.TrimPrefix(str, "0x"))
+	if err != nil {
+		return nil, invalidParams("expected hex encoded signed transaction: %v", err)
+	}
+	var tx chain.SigTx
+	err = json.Unmarshal(jtx, &tx)
+	if err != nil {
+		return nil, invalidParams("invalid signed transaction: %v", err)
+	}
+	err = s.txApplier.ApplyTx(tx)
+	if err != nil {
+		reason, _, _ := strings.Cut(err.Error(), "\n")
+		return nil, &ethErr{Code: ethServerError, Message: reason}
+	}
+	if s.txRelayer != nil {
+		s.txRelayer.RelayTx(tx)
+	}
+	return ethHex(tx.Hash().String()), nil
+}
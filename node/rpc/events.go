@@ -0,0 +1,301 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Ansh1902396/chain"
+	"golang.org/x/net/websocket"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+const eventHeartbeat = 15 * time.Second
+
+// eventRes is an event with the JSON encoded tx or block body embedded as is
+type eventRes struct {
+	Seq    uint64          `json:"seq"`
+	Height uint64          `json:"height"`
+	Type   string          `json:"type"`
+	Action string          `json:"action"`
+	Body   json.RawMessage `json:"body"`
+}
+
+// readEventTypes reads the event type filter from the repeated or comma
+// separated types parameter e.g. ?types=tx,blk. All events are selected by
+// default
+func readEventTypes(r *http.Request) ([]uint64, error) {
+	evTypes := make([]uint64, 0)
+	for _, types := range r.URL.Query()["types"] {
+		for evTypeStr := range strings.SplitSeq(types, ",") {
+			evType, err := chain.ParseEventType(strings.TrimSpace(evTypeStr))
+			if err != nil {
+				return nil, err
+			}
+			evTypes = append(evTypes, uint64(evType))
+		}
+	}
+	if len(evTypes) == 0 {
+		evTypes = append(evTypes, uint64(chain.EvAll))
+	}
+	return evTypes, nil
+}
+
+// readEventReq reads the event subscription from the request parameters. The
+// Last-Event-ID header of a reconnecting EventSource takes precedence over
+// the cursor parameter
+func readEventReq(r *http.Request) (*StreamSubscribeReq, error) {
+	evTypes, err := readEventTypes(r)
+	if err != nil {
+		return nil, err
+	}
+	req := &StreamSubscribeReq{EventTypes: evTypes}
+	m := req.ProtoReflect()
+	query := r.URL.Query()
+	if lastID := r.Header.Get("Last-Event-ID"); len(lastID) > 0 {
+		query.Set("cursor", lastID)
+	}
+	for name, strs := range query {
+		if name == "types" {
+			continue
+		}
+		fd := fieldByName(m.Descriptor(), name)
+		if fd == nil {
+			return nil, fmt.Errorf("unknown parameter %v", name)
+		}
+		err = setParam(m, fd, strs)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return req, nil
+}
+
+// streamEvents subscribes to the event stream of the node gRPC server. The
+// subscription is removed when the context is canceled
+func (g *Gateway) streamEvents(
+	ctx context.Context, req *StreamSubscribeReq,
+) (chan eventRes, error) {
+	cln := NewNodeClient(g.conn)
+	stream, err := cln.StreamSubscribe(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	chEvent := make(chan eventRes)
+	go func() {
+		defer close(chEvent)
+		for {
+			res, err := stream.Recv()
+			if err != nil {
+				return
+			}
+			var event chain.Event
+			err = json.Unmarshal(res.Event, &event)
+			if err != nil {
+				g.log.Error("Event decode", "err", err)
+				continue
+			}
+			evRes := eventRes{
+				Seq: event.Seq, Height: event.Height, Type: event.Type.String(),
+				Action: event.Action, Body: event.Body,
+			}
+			select {
+			case chEvent <- evRes:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return chEvent, nil
+}
+
+// serveSSE streams events as Server-Sent Events with a comment line as the
+// heartbeat
+func (g *Gateway) serveSSE(w http.ResponseWriter, r *http.Request) {
+	req, err := readEventReq(r)
+	if err != nil {
+		writeError(w, status.Error(codes.InvalidArgument, err.Error()))
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, status.Error(codes.Internal, "streaming not supported"))
+		return
+	}
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	chEvent, err := g.streamEvents(ctx, req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	ticker := time.NewTicker(eventHeartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_, err = fmt.Fprint(w, ": ping\n\n")
+		case evRes, open := <-chEvent:
+			if !open {
+				return
+			}
+			var jev []byte
+			jev, err = json.Marshal(evRes)
+			if err != nil {
+				g.log.Error("Event encode", "err", err)
+				continue
+			}
+			_, err = fmt.Fprintf(
+				w, "id: %d\nevent: %s\ndata: %s\n\n", evRes.Seq, evRes.Type, jev,
+			)
+		}
+		if err != nil {
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+// serveWebSocket streams events as WebSocket text messages with ping frames
+// as the heartbeat
+func (g *Gateway) serveWebSocket(w http.ResponseWriter, r *http.Request) {
+	req, err := readEventReq(r)
+	if err != nil {
+		writeError(w, status.Error(codes.InvalidArgument, err.Error()))
+		return
+	}
+	srv := websocket.Server{
+		Handshake: func(_ *websocket.Config, r *http.Request) error {
+			return g.checkOrigin(r)
+		},
+		Handler: func(ws *websocket.Conn) {
+			g.streamWebSocket(ws, req)
+		},
+	}
+	srv.ServeHTTP(w, r)
+}
+
+// checkOrigin accepts the WebSocket clients without the Origin header e.g.
+// non-browser clients, and the pages served by the node itself or by the
+// allowed origins
+func (g *Gateway) checkOrigin(r *http.Request) error {
+	origin := r.Header.Get("Origin")
+	if len(origin) == 0 || slices.Contains(g.origins, origin) {
+		return nil
+	}
+	u, err := url.Parse(origin)
+	if err == nil && u.Host == r.Host {
+		return nil
+	}
+	return fmt.Errorf("websocket: origin %v not allowed", origin)
+}
+
+func (g *Gateway) streamWebSocket(ws *websocket.Conn, req *StreamSubscribeReq) {
+	ctx, cancel := context.WithCancel(ws.Request().Context())
+	defer cancel()
+	// The client sends no messages, the read ends when the client disconnects
+	go func() {
+		defer cancel()
+		_, _ = io.Copy(io.Discard, ws)
+	}()
+	chEvent, err := g.streamEvents(ctx, req)
+	if err != nil {
+		st := status.Convert(err)
+		res := map[string]any{"code": st.Code().String(), "message": st.Message()}
+		_ = websocket.JSON.Send(ws, res)
+		return
+	}
+	ticker := time.NewTicker(eventHeartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			ws.PayloadType = websocket.PingFrame
+			_, err = ws.Write(nil)
+			ws.PayloadType = websocket.TextFrame
+		case evRes, open := <-chEvent:
+			if !open {
+				return
+			}
+			err = websocket.JSON.Send(ws, evRes)
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// eventPaths documents the event stream endpoints in the OpenAPI document
+func eventPaths(schemas map[string]any) map[string]any {
+	schemas["Event"] = map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"seq":    map[string]any{"type": "integer", "format": "uint64"},
+			"height": map[string]any{"type": "integer", "format": "uint64"},
+			"type":   map[string]any{"type": "string", "enum": []string{"tx", "blk", "htlc"}},
+			"action": map[string]any{"type": "string"},
+			"body":   map[string]any{"description": "JSON encoded chain.SigTx or chain.SigBlock"},
+		},
+	}
+	params := []any{
+		map[string]any{
+			"name": "types", "in": "query",
+			"description": "comma separated event types all, tx, blk, htlc",
+			"schema":      map[string]any{"type": "string", "default": "all"},
+		},
+	}
+	fields := (&StreamSubscribeReq{}).ProtoReflect().Descriptor().Fields()
+	for i := range fields.Len() {
+		fd := fields.Get(i)
+		if fd.Name() == "EventTypes" {
+			continue
+		}
+		params = append(params, map[string]any{
+			"name": jsonName(fd), "in": "query", "schema": fieldSchema(fd, schemas),
+		})
+	}
+	return map[string]any{
+		"/v1/events": map[string]any{
+			"get": map[string]any{
+				"operationId": "EventsSSE",
+				"tags":        []string{"Node"},
+				"summary":     "Server-Sent Events of /Node/StreamSubscribe",
+				"parameters":  params,
+				"responses": map[string]any{
+					"200": map[string]any{
+						"description": "event: type, data: Event",
+						"content": map[string]any{
+							"text/event-stream": map[string]any{"schema": schemaRef("Event")},
+						},
+					},
+				},
+			},
+		},
+		"/v1/events/ws": map[string]any{
+			"get": map[string]any{
+				"operationId": "EventsWebSocket",
+				"tags":        []string{"Node"},
+				"summary":     "WebSocket of /Node/StreamSubscribe, text messages of Event",
+				"parameters":  params,
+				"responses": map[string]any{
+					"101": map[string]any{"description": "Switching Protocols"},
+				},
+			},
+		},
+	}
+}
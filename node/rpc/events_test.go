@@ -0,0 +1,35 @@
+package rpc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWebSocketOrigin(t *testing.T) {
+	cases := []struct {
+		name   string
+		origin string
+		valid  bool
+	}{
+		{"no origin", "", true},
+		{"same origin", "http://node.example.com:8080", true},
+		{"allowed origin", "https://dash.example.com", true},
+		{"other origin", "https://evil.example.com", false},
+		{"other port", "http://node.example.com:9090", false},
+		{"invalid origin", "://", false},
+	}
+	g := &Gateway{origins: []string{"https://dash.example.com"}}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "http://node.example.com:8080/v1/events/ws", nil)
+			if len(c.origin) > 0 {
+				r.Header.Set("Origin", c.origin)
+			}
+			err := g.checkOrigin(r)
+			if (err == nil) != c.valid {
+				t.Fatalf("expected valid %v, got %v", c.valid, err)
+			}
+		})
+	}
+}
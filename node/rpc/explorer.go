@@ -0,0 +1,408 @@
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Ansh1902396/chain"
+	"google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+const (
+	explorerBlocks  = 20
+	explorerEntries = 100
+)
+
+//go:embed explorer
+var explorerFS embed.FS
+
+var explorerPages = []string{"home", "block", "genesis", "tx", "account", "error"}
+
+// Explorer serves a read-only web explorer of the blocks, the transactions,
+// and the accounts of the node with a live feed of the event stream. The
+// pages are rendered from the gRPC server of the node
+type Explorer struct {
+	conn    grpc.ClientConnInterface
+	gateway *Gateway
+	pages   map[string]*template.Template
+	mux     *http.ServeMux
+	log     *slog.Logger
+}
+
+func NewExplorer(conn grpc.ClientConnInterface, log *slog.Logger) (*Explorer, error) {
+	gateway, err := NewGateway(conn, log)
+	if err != nil {
+		return nil, err
+	}
+	e := &Explorer{
+		conn: conn, gateway: gateway, pages: make(map[string]*template.Template),
+		mux: http.NewServeMux(), log: log,
+	}
+	funcs := template.FuncMap{
+		"short": func(v any) string {
+			str := fmt.Sprint(v)
+			return str[:min(8, len(str))]
+		},
+		"time":   func(t time.Time) string { return t.UTC().Format(time.RFC3339) },
+		"memo":   func(data []byte) string { return string(data) },
+		"parent": func(number uint64) uint64 { return number - 1 },
+	}
+	for _, page := range explorerPages {
+		tmpl, err := template.New(page).Funcs(funcs).ParseFS(
+			explorerFS, "explorer/layout.html", "explorer/"+page+".html",
+		)
+		if err != nil {
+			return nil, err
+		}
+		e.pages[page] = tmpl
+	}
+	static, err := fs.Sub(explorerFS, "explorer/static")
+	if err != nil {
+		return nil, err
+	}
+	e.mux.Handle("GET /static/", http.StripPrefix("/static/", http.FileServerFS(static)))
+	e.mux.HandleFunc("GET /{$}", e.serveHome)
+	e.mux.HandleFunc("GET /blocks/{number}", e.serveBlock)
+	e.mux.HandleFunc("GET /txs/{hash}", e.serveTx)
+	e.mux.HandleFunc("GET /accounts/{address}", e.serveAccount)
+	e.mux.HandleFunc("GET /search", e.serveSearch)
+	e.mux.HandleFunc("GET /events", gateway.serveSSE)
+	return e, nil
+}
+
+func (e *Explorer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	e.mux.ServeHTTP(w, r)
+}
+
+// render writes the page into a buffer first, so a template error results
+// in an error page instead of a partial page
+func (e *Explorer) render(w http.ResponseWriter, code int, page string, data any) {
+	var buf bytes.Buffer
+	err := e.pages[page].ExecuteTemplate(&buf, "layout", data)
+	if err != nil {
+		e.log.Error("Explorer render", "page", page, "err", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(code)
+	// The client has gone away when the response cannot be written
+	_, _ = buf.WriteTo(w)
+}
+
+func (e *Explorer) renderError(w http.ResponseWriter, err error) {
+	st := status.Convert(err)
+	code := HTTPStatus(st.Code())
+	data := struct {
+		Status  string
+		Message string
+	}{Status: http.StatusText(code), Message: st.Message()}
+	e.render(w, code, "error", data)
+}
+
+// recvAll reads the server stream to the end
+func recvAll[Res any](stream grpc.ServerStreamingClient[Res]) ([]*Res, error) {
+	items := make([]*Res, 0)
+	for {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			return items, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, res)
+	}
+}
+
+func (e *Explorer) searchBlocks(
+	ctx context.Context, req *BlockSearchReq,
+) ([]chain.SigBlock, error) {
+	stream, err := NewBlockClient(e.conn).BlockSearch(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	items, err := recvAll(stream)
+	if err != nil {
+		return nil, err
+	}
+	blocks := make([]chain.SigBlock, len(items))
+	for i, res := range items {
+		err = json.Unmarshal(res.Block, &blocks[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+	return blocks, nil
+}
+
+func (e *Explorer) searchTxs(
+	ctx context.Context, req *TxSearchReq,
+) ([]chain.SearchTx, error) {
+	stream, err := NewTxClient(e.conn).TxSearch(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	items, err := recvAll(stream)
+	if err != nil {
+		return nil, err
+	}
+	txs := make([]chain.SearchTx, len(items))
+	for i, res := range items {
+		err = json.Unmarshal(res.Tx, &txs[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+	return txs, nil
+}
+
+func (e *Explorer) serveHome(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	st, err := NewNodeClient(e.conn).NodeStatus(ctx, &NodeStatusReq{})
+	if err != nil {
+		e.renderError(w, err)
+		return
+	}
+	blocks, err := e.searchBlocks(
+		ctx, &BlockSearchReq{Desc: true, Limit: explorerBlocks},
+	)
+	if err != nil {
+		e.renderError(w, err)
+		return
+	}
+	data := struct {
+		Status *NodeStatusRes
+		Blocks []chain.SigBlock
+	}{Status: st, Blocks: blocks}
+	e.render(w, http.StatusOK, "home", data)
+}
+
+func (e *Explorer) serveBlock(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	number, err := strconv.ParseUint(r.PathValue("number"), 10, 64)
+	if err != nil {
+		e.renderError(w, status.Errorf(
+			codes.InvalidArgument, "expected block number, got %v",
+			r.PathValue("number"),
+		))
+		return
+	}
+	if number == 0 {
+		e.serveGenesis(w, r)
+		return
+	}
+	blocks, err := e.searchBlocks(ctx, &BlockSearchReq{Number: number, Limit: 1})
+	if err != nil {
+		e.renderError(w, err)
+		return
+	}
+	if len(blocks) == 0 {
+		e.renderError(w, status.Errorf(codes.NotFound, "block %d not found", number))
+		return
+	}
+	e.render(w, http.StatusOK, "block", blocks[0])
+}
+
+func (e *Explorer) serveGenesis(w http.ResponseWriter, r *http.Request) {
+	res, err := NewBlockClient(e.conn).GenesisSync(r.Context(), &GenesisSyncReq{})
+	if err != nil {
+		e.renderError(w, err)
+		return
+	}
+	var gen chain.SigGenesis
+	err = json.Unmarshal(res.Genesis, &gen)
+	if err != nil {
+		e.renderError(w, status.Error(codes.Internal, err.Error()))
+		return
+	}
+	type balance struct {
+		Address chain.Address
+		Balance uint64
+	}
+	balances := make([]balance, 0, len(gen.Balances))
+	for acc, bal := range gen.Balances {
+		balances = append(balances, balance{Address: acc, Balance: bal})
+	}
+	slices.SortFunc(balances, func(a, b balance) int {
+		return strings.Compare(string(a.Address), string(b.Address))
+	})
+	data := struct {
+		Genesis  chain.SigGenesis
+		Balances []balance
+	}{Genesis: gen, Balances: balances}
+	e.render(w, http.StatusOK, "genesis", data)
+}
+
+// serveTx shows a confirmed transaction with its transfers, or the receipt
+// of a pending one. The verify parameter checks the Merkle proof of the
+// transaction against the Merkle root of its block
+func (e *Explorer) serveTx(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	hash := r.PathValue("hash")
+	txs, err := e.searchTxs(ctx, &TxSearchReq{Hash: hash, Limit: 1})
+	if err != nil {
+		e.renderError(w, err)
+		return
+	}
+	data := struct {
+		Hash      string
+		Tx        *chain.SearchTx
+		Receipt   *chain.Receipt
+		Verified  bool
+		Valid     bool
+		VerifyErr string
+	}{Hash: hash}
+	if len(txs) > 0 {
+		data.Tx = &txs[0]
+		data.Hash = txs[0].Hash().String()
+	}
+	rcp, err := NewTxClient(e.conn).TxStatus(ctx, &TxStatusReq{Hash: data.Hash})
+	if err == nil {
+		data.Receipt = new(chain.Receipt)
+		err = json.Unmarshal(rcp.Receipt, data.Receipt)
+		if err != nil {
+			e.renderError(w, status.Error(codes.Internal, err.Error()))
+			return
+		}
+	}
+	if data.Tx == nil && data.Receipt == nil {
+		e.renderError(w, status.Errorf(codes.NotFound, "transaction %v not found", hash))
+		return
+	}
+	if data.Tx != nil && r.URL.Query().Has("verify") {
+		data.Verified = true
+		data.Valid, err = e.verifyTx(ctx, data.Hash, data.Tx.MerkleRoot)
+		if err != nil {
+			data.VerifyErr = status.Convert(err).Message()
+		}
+	}
+	e.render(w, http.StatusOK, "tx", data)
+}
+
+// verifyTx proves the transaction with the Merkle tree of its block, then
+// verifies the proof against the Merkle root
+func (e *Explorer) verifyTx(
+	ctx context.Context, hash string, merkleRoot chain.Hash,
+) (bool, error) {
+	cln := NewTxClient(e.conn)
+	proof, err := cln.TxProve(ctx, &TxProveReq{Hash: hash})
+	if err != nil {
+		return false, err
+	}
+	res, err := cln.TxVerify(ctx, &TxVerifyReq{
+		Hash: hash, MerkleProof: proof.MerkleProof,
+		MerkleRoot: merkleRoot.String(),
+	})
+	if err != nil {
+		return false, err
+	}
+	return res.Valid, nil
+}
+
+// serveAccount shows the balance and the most recent balance changes of an
+// account
+func (e *Explorer) serveAccount(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	address := r.PathValue("address")
+	cln := NewAccountClient(e.conn)
+	bal, err := cln.AccountBalance(ctx, &AccountBalanceReq{Address: address})
+	if err != nil {
+		e.renderError(w, err)
+		return
+	}
+	stream, err := cln.AccountHistory(ctx, &AccountHistoryReq{Address: address})
+	if err != nil {
+		e.renderError(w, err)
+		return
+	}
+	items, err := recvAll(stream)
+	if err != nil {
+		e.renderError(w, err)
+		return
+	}
+	entries := make([]chain.HistoryEntry, 0, min(len(items), explorerEntries))
+	for _, res := range slices.Backward(items) {
+		if len(entries) == explorerEntries {
+			break
+		}
+		var entry chain.HistoryEntry
+		err = json.Unmarshal(res.Entry, &entry)
+		if err != nil {
+			e.renderError(w, status.Error(codes.Internal, err.Error()))
+			return
+		}
+		entries = append(entries, entry)
+	}
+	data := struct {
+		Address string
+		Balance uint64
+		Entries []chain.HistoryEntry
+		Total   int
+	}{Address: address, Balance: bal.Balance, Entries: entries, Total: len(items)}
+	e.render(w, http.StatusOK, "account", data)
+}
+
+// serveSearch redirects to the block with the number, or to the transaction
+// or the block with the hash prefix, or to the account with the address
+func (e *Explorer) serveSearch(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	redirect := func(path string) {
+		http.Redirect(w, r, path, http.StatusSeeOther)
+	}
+	if len(query) == 0 {
+		redirect("/")
+		return
+	}
+	_, err := strconv.ParseUint(query, 10, 64)
+	if err == nil {
+		redirect("/blocks/" + query)
+		return
+	}
+	txs, err := e.searchTxs(ctx, &TxSearchReq{Hash: query, Limit: 1})
+	if err != nil {
+		e.renderError(w, err)
+		return
+	}
+	if len(txs) > 0 {
+		redirect("/txs/" + txs[0].Hash().String())
+		return
+	}
+	blocks, err := e.searchBlocks(ctx, &BlockSearchReq{Hash: query, Limit: 1})
+	if err != nil {
+		e.renderError(w, err)
+		return
+	}
+	if len(blocks) > 0 {
+		redirect(fmt.Sprintf("/blocks/%d", blocks[0].Number))
+		return
+	}
+	_, err = chain.DecodeHash(query)
+	if err == nil {
+		// Pending transactions are only known by their full hash
+		_, err = NewTxClient(e.conn).TxStatus(ctx, &TxStatusReq{Hash: query})
+		if err == nil {
+			redirect("/txs/" + query)
+			return
+		}
+		redirect("/accounts/" + url.PathEscape(query))
+		return
+	}
+	e.renderError(w, status.Errorf(codes.NotFound, "nothing matches %v", query))
+}
@@ -0,0 +1,47 @@
+// Live feed of the validated blocks and transactions from the event stream
+(function () {
+  const feed = document.getElementById("feed");
+  const maxItems = 50;
+
+  function link(href, text) {
+    const a = document.createElement("a");
+    a.href = href;
+    a.textContent = text;
+    return a;
+  }
+
+  function add(...parts) {
+    const li = document.createElement("li");
+    for (const part of parts) {
+      li.append(part);
+    }
+    feed.prepend(li);
+    while (feed.children.length > maxItems) {
+      feed.lastChild.remove();
+    }
+  }
+
+  const events = new EventSource("/events");
+  events.addEventListener("blk", (msg) => {
+    const ev = JSON.parse(msg.data);
+    const blk = ev.body;
+    add(
+      "block ", link("/blocks/" + blk.number, blk.number),
+      " with " + (blk.txs || []).length + " txs",
+    );
+  });
+  events.addEventListener("tx", (msg) => {
+    const ev = JSON.parse(msg.data);
+    const tx = ev.body;
+    const to = tx.batch ? "batch of " + tx.batch.length : tx.to;
+    add(
+      "tx ", link("/accounts/" + tx.from, tx.from.slice(0, 8)),
+      " -> ", tx.batch ? to : link("/accounts/" + to, to.slice(0, 8)),
+      " " + tx.value + " in block ", link("/blocks/" + ev.height, ev.height),
+    );
+  });
+  events.addEventListener("htlc", (msg) => {
+    const ev = JSON.parse(msg.data);
+    add("htlc " + ev.action + " in block ", link("/blocks/" + ev.height, ev.height));
+  });
+})();
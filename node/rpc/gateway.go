@@ -0,0 +1,684 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+const (
+	gatewayPageLimit    = 100
+	gatewayMaxPageLimit = 1000
+	// maxBodySize limits HTTP request bodies to the default gRPC message size
+	maxBodySize = 4 << 20
+	// gatewayWaitTimeout bounds the wait for the receipts of a transaction
+	gatewayWaitTimeout = 30 * time.Second
+)
+
+// route maps an HTTP method and path to a gRPC method. Path parameters and,
+// if enabled, query parameters fill the request fields with the same name,
+// the JSON body of POST requests fills the remaining request fields
+type route struct {
+	method  string
+	pattern string
+	rpc     protoreflect.FullName
+	// id overrides the OpenAPI operation id when a gRPC method has many routes
+	id string
+	// status is the HTTP status of a successful response
+	status int
+	// query enables request fields in the query string of GET requests
+	query bool
+	// single returns the first item of a server stream or not found
+	single bool
+	// timeout ends a server stream. The items received so far are returned,
+	// or not found if there are none
+	timeout time.Duration
+	// private routes take keystore passwords or change the node, see
+	// AllowPrivate
+	private bool
+}
+
+var gatewayRoutes = []route{
+	{method: http.MethodPost, pattern: "/v1/accounts", rpc: "Account.AccountCreate", status: http.StatusCreated, private: true},
+	{method: http.MethodGet, pattern: "/v1/accounts/{address}/balance", rpc: "Account.AccountBalance"},
+	{method: http.MethodGet, pattern: "/v1/accounts/{address}/history", rpc: "Account.AccountHistory", query: true},
+	{method: http.MethodGet, pattern: "/v1/txs", rpc: "Tx.TxSearch", query: true},
+	{method: http.MethodPost, pattern: "/v1/txs", rpc: "Tx.TxSend", status: http.StatusAccepted},
+	{method: http.MethodPost, pattern: "/v1/txs/sign", rpc: "Tx.TxSign", private: true},
+	{method: http.MethodPost, pattern: "/v1/txs/verify", rpc: "Tx.TxVerify"},
+	{method: http.MethodGet, pattern: "/v1/txs/{hash}/proof", rpc: "Tx.TxProve"},
+	{method: http.MethodGet, pattern: "/v1/txs/{hash}/status", rpc: "Tx.TxStatus"},
+	{method: http.MethodGet, pattern: "/v1/txs/{hash}/receipts", rpc: "Tx.TxWait", timeout: gatewayWaitTimeout},
+	{method: http.MethodGet, pattern: "/v1/locks/{hash}", rpc: "Tx.TxLock"},
+	{method: http.MethodGet, pattern: "/v1/blocks", rpc: "Block.BlockSync", query: true},
+	{method: http.MethodGet, pattern: "/v1/blocks/search", rpc: "Block.BlockSearch", query: true},
+	{method: http.MethodGet, pattern: "/v1/blocks/{number}", rpc: "Block.BlockSearch", id: "BlockGet", single: true},
+	{method: http.MethodGet, pattern: "/v1/genesis", rpc: "Block.GenesisSync"},
+	{method: http.MethodGet, pattern: "/v1/peers", rpc: "Node.PeerDiscover"},
+	{method: http.MethodGet, pattern: "/v1/status", rpc: "Node.NodeStatus"},
+	{method: http.MethodPost, pattern: "/v1/webhooks", rpc: "Node.WebhookCreate", status: http.StatusCreated, private: true},
+	{method: http.MethodGet, pattern: "/v1/webhooks", rpc: "Node.WebhookList"},
+	{method: http.MethodDelete, pattern: "/v1/webhooks/{id}", rpc: "Node.WebhookDelete", private: true},
+}
+
+// jsonFields are the bytes fields that carry JSON encoded chain resources.
+// The gateway embeds them as JSON values instead of base64 strings
+var jsonFields = map[protoreflect.FullName]string{
+	"AccountHistoryRes.Entry":  "chain.HistoryEntry",
+	"TxSearchRes.Tx":           "chain.SearchTx",
+	"TxSignRes.Tx":             "chain.SigTx",
+	"TxSendReq.Tx":             "chain.SigTx",
+	"TxProveRes.MerkleProof":   "[]chain.Proof",
+	"TxVerifyReq.MerkleProof":  "[]chain.Proof",
+	"TxStatusRes.Receipt":      "chain.Receipt",
+	"TxWaitRes.Receipt":        "chain.Receipt",
+	"TxLockRes.Lock":           "chain.Lock",
+	"BlockSearchRes.Block":     "chain.SigBlock",
+	"BlockSyncRes.Block":       "chain.SigBlock",
+	"GenesisSyncRes.Genesis":   "chain.SigGenesis",
+	"StreamSubscribeRes.Event": "chain.Event",
+	"WebhookCreateRes.Webhook": "chain.Webhook",
+	"WebhookListRes.Webhook":   "chain.Webhook",
+}
+
+var rePathParam = regexp.MustCompile(`\{(\w+)\}`)
+
+// Gateway serves the Account, Tx, Block, and Node services as an HTTP/JSON
+// API by forwarding requests to the gRPC server of the node
+type Gateway struct {
+	conn    grpc.ClientConnInterface
+	mux     *http.ServeMux
+	openAPI []byte
+	origins []string
+	private bool
+	log     *slog.Logger
+}
+
+func NewGateway(conn grpc.ClientConnInterface, log *slog.Logger) (*Gateway, error) {
+	g := &Gateway{conn: conn, mux: http.NewServeMux(), log: log}
+	for _, rt := range gatewayRoutes {
+		md, err := methodDesc(rt.rpc)
+		if err != nil {
+			return nil, err
+		}
+		g.mux.HandleFunc(rt.method+" "+rt.pattern, g.handle(rt, md))
+	}
+	doc, err := newOpenAPI(gatewayRoutes)
+	if err != nil {
+		return nil, err
+	}
+	g.openAPI, err = json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	g.mux.HandleFunc("GET /v1/events", g.serveSSE)
+	g.mux.HandleFunc("GET /v1/events/ws", g.serveWebSocket)
+	g.mux.HandleFunc("GET /v1/openapi.json", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(g.openAPI)
+	})
+	return g, nil
+}
+
+// Handle serves the handler next to the gateway routes
+func (g *Gateway) Handle(pattern string, handler http.Handler) {
+	g.mux.Handle(pattern, handler)
+}
+
+// AllowOrigins allows browser pages of the origins e.g. dashboards to call
+// the gateway. No cross-origin requests are allowed by default
+func (g *Gateway) AllowOrigins(origins []string) {
+	g.origins = origins
+}
+
+// AllowPrivate serves the routes that take keystore passwords or change the
+// node. The gateway has no authentication, so they are disabled by default
+func (g *Gateway) AllowPrivate() {
+	g.private = true
+}
+
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	origin := r.Header.Get("Origin")
+	if len(origin) > 0 && slices.Contains(g.origins, origin) {
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		w.Header().Add("Vary", "Origin")
+		if r.Method == http.MethodOptions {
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+	g.mux.ServeHTTP(w, r)
+}
+
+func methodDesc(name protoreflect.FullName) (protoreflect.MethodDescriptor, error) {
+	desc, err := protoregistry.GlobalFiles.FindDescriptorByName(name)
+	if err != nil {
+		return nil, fmt.Errorf("gateway: %v: %v", name, err)
+	}
+	md, ok := desc.(protoreflect.MethodDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("gateway: %v is not a method", name)
+	}
+	return md, nil
+}
+
+func newMsg(desc protoreflect.MessageDescriptor) (proto.Message, error) {
+	mt, err := protoregistry.GlobalTypes.FindMessageByName(desc.FullName())
+	if err != nil {
+		return nil, err
+	}
+	return mt.New().Interface(), nil
+}
+
+func grpcMethod(md protoreflect.MethodDescriptor) string {
+	return fmt.Sprintf("/%s/%s", md.Parent().FullName(), md.Name())
+}
+
+// jsonName converts a proto field name to lower camel case e.g. HashLock to
+// hashLock and HTLC to htlc
+func jsonName(fd protoreflect.FieldDescriptor) string {
+	name := string(fd.Name())
+	i := 0
+	for i < len(name) && 'A' <= name[i] && name[i] <= 'Z' {
+		i++
+	}
+	if i > 1 && i < len(name) {
+		i--
+	}
+	return strings.ToLower(name[:i]) + name[i:]
+}
+
+// fieldByName finds a field ignoring the case of the name
+func fieldByName(
+	desc protoreflect.MessageDescriptor, name string,
+) protoreflect.FieldDescriptor {
+	fields := desc.Fields()
+	for i := range fields.Len() {
+		if strings.EqualFold(string(fields.Get(i).Name()), name) {
+			return fields.Get(i)
+		}
+	}
+	return nil
+}
+
+// pageFields are the search result fields that the gateway moves from the
+// items to the page
+var pageFields = []protoreflect.Name{"Total", "Cursor"}
+
+// resourceField returns the only field of the message besides the page
+// fields if it carries a JSON encoded chain resource. Such messages are
+// exchanged as the resource itself
+func resourceField(desc protoreflect.MessageDescriptor) protoreflect.FieldDescriptor {
+	var res protoreflect.FieldDescriptor
+	fields := desc.Fields()
+	for i := range fields.Len() {
+		fd := fields.Get(i)
+		if slices.Contains(pageFields, fd.Name()) {
+			continue
+		}
+		if res != nil {
+			return nil
+		}
+		res = fd
+	}
+	if res == nil {
+		return nil
+	}
+	if _, exist := jsonFields[res.FullName()]; !exist {
+		return nil
+	}
+	return res
+}
+
+func encodeMsg(m protoreflect.Message) any {
+	if fd := resourceField(m.Descriptor()); fd != nil {
+		return encodeValue(fd, m.Get(fd))
+	}
+	obj := make(map[string]any)
+	fields := m.Descriptor().Fields()
+	for i := range fields.Len() {
+		fd := fields.Get(i)
+		obj[jsonName(fd)] = encodeValue(fd, m.Get(fd))
+	}
+	return obj
+}
+
+func encodeValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) any {
+	if fd.IsList() {
+		list := v.List()
+		items := make([]any, list.Len())
+		for i := range list.Len() {
+			items[i] = encodeScalar(fd, list.Get(i))
+		}
+		return items
+	}
+	return encodeScalar(fd, v)
+}
+
+func encodeScalar(fd protoreflect.FieldDescriptor, v protoreflect.Value) any {
+	switch fd.Kind() {
+	case protoreflect.MessageKind:
+		if !v.Message().IsValid() {
+			return nil
+		}
+		return encodeMsg(v.Message())
+	case protoreflect.BytesKind:
+		if _, exist := jsonFields[fd.FullName()]; exist {
+			if len(v.Bytes()) == 0 {
+				return nil
+			}
+			return json.RawMessage(v.Bytes())
+		}
+		return v.Bytes()
+	default:
+		return v.Interface()
+	}
+}
+
+func decodeMsg(data []byte, m protoreflect.Message) error {
+	if fd := resourceField(m.Descriptor()); fd != nil {
+		if !json.Valid(data) {
+			return fmt.Errorf("invalid JSON %v", fd.Name())
+		}
+		m.Set(fd, protoreflect.ValueOfBytes(data))
+		return nil
+	}
+	var obj map[string]json.RawMessage
+	err := json.Unmarshal(data, &obj)
+	if err != nil {
+		return err
+	}
+	for name, raw := range obj {
+		fd := fieldByName(m.Descriptor(), name)
+		if fd == nil {
+			return fmt.Errorf("unknown field %v", name)
+		}
+		if !fd.IsList() {
+			err = decodeValue(fd, raw, m)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		var items []json.RawMessage
+		err = json.Unmarshal(raw, &items)
+		if err != nil {
+			return fmt.Errorf("%v: %v", name, err)
+		}
+		list := m.Mutable(fd).List()
+		for _, item := range items {
+			v := list.NewElement()
+			if fd.Kind() == protoreflect.MessageKind {
+				err = decodeMsg(item, v.Message())
+			} else {
+				v, err = decodeScalar(fd, item)
+			}
+			if err != nil {
+				return err
+			}
+			list.Append(v)
+		}
+	}
+	return nil
+}
+
+func decodeValue(
+	fd protoreflect.FieldDescriptor, raw json.RawMessage, m protoreflect.Message,
+) error {
+	if fd.Kind() == protoreflect.MessageKind {
+		return decodeMsg(raw, m.Mutable(fd).Message())
+	}
+	v, err := decodeScalar(fd, raw)
+	if err != nil {
+		return err
+	}
+	m.Set(fd, v)
+	return nil
+}
+
+func decodeScalar(
+	fd protoreflect.FieldDescriptor, raw json.RawMessage,
+) (protoreflect.Value, error) {
+	var err error
+	var v protoreflect.Value
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		var s string
+		err = json.Unmarshal(raw, &s)
+		v = protoreflect.ValueOfString(s)
+	case protoreflect.Uint64Kind:
+		var u uint64
+		err = json.Unmarshal(raw, &u)
+		v = protoreflect.ValueOfUint64(u)
+	case protoreflect.BoolKind:
+		var b bool
+		err = json.Unmarshal(raw, &b)
+		v = protoreflect.ValueOfBool(b)
+	case protoreflect.BytesKind:
+		if _, exist := jsonFields[fd.FullName()]; exist {
+			return protoreflect.ValueOfBytes(raw), nil
+		}
+		var b []byte
+		err = json.Unmarshal(raw, &b)
+		v = protoreflect.ValueOfBytes(b)
+	default:
+		err = fmt.Errorf("unsupported type %v", fd.Kind())
+	}
+	if err != nil {
+		return v, fmt.Errorf("%v: %v", jsonName(fd), err)
+	}
+	return v, nil
+}
+
+func decodeParam(fd protoreflect.FieldDescriptor, str string) (protoreflect.Value, error) {
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		return protoreflect.ValueOfString(str), nil
+	case protoreflect.Uint64Kind:
+		u, err := strconv.ParseUint(str, 10, 64)
+		if err != nil {
+			return protoreflect.Value{}, fmt.Errorf("%v: expected unsigned integer, got %v", jsonName(fd), str)
+		}
+		return protoreflect.ValueOfUint64(u), nil
+	case protoreflect.BoolKind:
+		b, err := strconv.ParseBool(str)
+		if err != nil {
+			return protoreflect.Value{}, fmt.Errorf("%v: expected boolean, got %v", jsonName(fd), str)
+		}
+		return protoreflect.ValueOfBool(b), nil
+	default:
+		return protoreflect.Value{}, fmt.Errorf("%v: not supported as a parameter", jsonName(fd))
+	}
+}
+
+func setParam(m protoreflect.Message, fd protoreflect.FieldDescriptor, strs []string) error {
+	for _, str := range strs {
+		v, err := decodeParam(fd, str)
+		if err != nil {
+			return err
+		}
+		if fd.IsList() {
+			m.Mutable(fd).List().Append(v)
+		} else {
+			m.Set(fd, v)
+		}
+	}
+	return nil
+}
+
+// readReq fills the gRPC request from the body, the path, and the query
+func readReq(r *http.Request, rt route, m protoreflect.Message) error {
+	if r.Method == http.MethodPost {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			return err
+		}
+		if len(body) > 0 {
+			err = decodeMsg(body, m)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	for _, match := range rePathParam.FindAllStringSubmatch(rt.pattern, -1) {
+		fd := fieldByName(m.Descriptor(), match[1])
+		err := setParam(m, fd, []string{r.PathValue(match[1])})
+		if err != nil {
+			return err
+		}
+	}
+	if !rt.query {
+		return nil
+	}
+	for name, strs := range r.URL.Query() {
+		if name == "limit" || name == "offset" {
+			continue
+		}
+		fd := fieldByName(m.Descriptor(), name)
+		if fd == nil {
+			return fmt.Errorf("unknown parameter %v", name)
+		}
+		err := setParam(m, fd, strs)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readPage(r *http.Request) (int, int, error) {
+	limit, offset := gatewayPageLimit, 0
+	var err error
+	query := r.URL.Query()
+	if str := query.Get("limit"); len(str) > 0 {
+		limit, err = strconv.Atoi(str)
+		if err != nil || limit < 1 || limit > gatewayMaxPageLimit {
+			return 0, 0, fmt.Errorf(
+				"limit: expected 1 to %d, got %v", gatewayMaxPageLimit, str,
+			)
+		}
+	}
+	if str := query.Get("offset"); len(str) > 0 {
+		offset, err = strconv.Atoi(str)
+		if err != nil || offset < 0 {
+			return 0, 0, fmt.Errorf("offset: expected non-negative integer, got %v", str)
+		}
+	}
+	return limit, offset, nil
+}
+
+func (g *Gateway) handle(
+	rt route, md protoreflect.MethodDescriptor,
+) http.HandlerFunc {
+	if rt.status == 0 {
+		rt.status = http.StatusOK
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		if rt.private && !g.private {
+			writeError(w, status.Errorf(
+				codes.PermissionDenied, "%v %v is disabled, see --http-private",
+				rt.method, rt.pattern,
+			))
+			return
+		}
+		req, err := newMsg(md.Input())
+		if err != nil {
+			writeError(w, status.Error(codes.Internal, err.Error()))
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, maxBodySize)
+		err = readReq(r, rt, req.ProtoReflect())
+		if err != nil {
+			writeError(w, status.Error(codes.InvalidArgument, err.Error()))
+			return
+		}
+		if md.IsStreamingServer() {
+			g.serveStream(w, r, rt, md, req)
+			return
+		}
+		res, err := newMsg(md.Output())
+		if err != nil {
+			writeError(w, status.Error(codes.Internal, err.Error()))
+			return
+		}
+		err = g.conn.Invoke(r.Context(), grpcMethod(md), req, res)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, rt.status, encodeMsg(res.ProtoReflect()))
+	}
+}
+
+// serveStream reads a page of items from a server stream. The next offset is
+// set while more items are available
+func (g *Gateway) serveStream(
+	w http.ResponseWriter, r *http.Request, rt route,
+	md protoreflect.MethodDescriptor, req proto.Message,
+) {
+	limit, offset, err := readPage(r)
+	if err != nil {
+		writeError(w, status.Error(codes.InvalidArgument, err.Error()))
+		return
+	}
+	if rt.single {
+		limit, offset = 1, 0
+	}
+	// Search methods page on the server and report the total of matches
+	m := req.ProtoReflect()
+	fdLimit := m.Descriptor().Fields().ByName("Limit")
+	fdOffset := m.Descriptor().Fields().ByName("Offset")
+	paged := fdLimit != nil && fdOffset != nil
+	if paged {
+		m.Set(fdLimit, protoreflect.ValueOfUint64(uint64(limit)))
+		m.Set(fdOffset, protoreflect.ValueOfUint64(uint64(offset)))
+	}
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if rt.timeout > 0 {
+		ctx, cancel = context.WithTimeout(r.Context(), rt.timeout)
+	} else {
+		ctx, cancel = context.WithCancel(r.Context())
+	}
+	defer cancel()
+	desc := &grpc.StreamDesc{StreamName: string(md.Name()), ServerStreams: true}
+	stream, err := g.conn.NewStream(ctx, desc, grpcMethod(md))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	err = stream.SendMsg(req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	err = stream.CloseSend()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	items, more := make([]any, 0), false
+	var total uint64
+	var cursor string
+	for i := 0; ; i++ {
+		res, err := newMsg(md.Output())
+		if err != nil {
+			writeError(w, status.Error(codes.Internal, err.Error()))
+			return
+		}
+		err = stream.RecvMsg(res)
+		if err == io.EOF {
+			break
+		}
+		if rt.timeout > 0 && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			break
+		}
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		if paged {
+			mres := res.ProtoReflect()
+			fields := mres.Descriptor().Fields()
+			total = mres.Get(fields.ByName("Total")).Uint()
+			cursor = mres.Get(fields.ByName("Cursor")).String()
+			items = append(items, encodeMsg(mres))
+			continue
+		}
+		if i < offset {
+			continue
+		}
+		if len(items) == limit {
+			more = true
+			break
+		}
+		items = append(items, encodeMsg(res.ProtoReflect()))
+	}
+	if rt.timeout > 0 && len(items) == 0 {
+		writeError(w, status.Errorf(codes.NotFound, "%v not found", r.URL.Path))
+		return
+	}
+	if paged {
+		more = len(items) == limit && uint64(offset+len(items)) < total
+	}
+	if rt.single {
+		if len(items) == 0 {
+			writeError(w, status.Errorf(codes.NotFound, "%v not found", r.URL.Path))
+			return
+		}
+		writeJSON(w, rt.status, items[0])
+		return
+	}
+	page := map[string]any{"items": items}
+	if more {
+		page["next"] = offset + limit
+	}
+	if paged {
+		page["total"] = total
+		if len(cursor) > 0 {
+			page["cursor"] = cursor
+		}
+	}
+	writeJSON(w, rt.status, page)
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	// The client has gone away when the response cannot be written
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	st := status.Convert(err)
+	res := map[string]any{"code": st.Code().String(), "message": st.Message()}
+	writeJSON(w, HTTPStatus(st.Code()), res)
+}
+
+// HTTPStatus maps a gRPC status code to the HTTP status code
+func HTTPStatus(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		return 499
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,244 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	status "google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestGatewayDecode(t *testing.T) {
+	cases := []struct {
+		name string
+		body string
+		msg  proto.Message
+		exp  proto.Message
+		err  string
+	}{
+		{"fields", `{"from": "a", "to": "b", "value": 10, "password": "p"}`,
+			&TxSignReq{}, &TxSignReq{From: "a", To: "b", Value: 10, Password: "p"}, ""},
+		{"case insensitive", `{"FROM": "a", "notbefore": 5}`,
+			&TxSignReq{}, &TxSignReq{From: "a", NotBefore: 5}, ""},
+		{"bytes", `{"data": "aGk="}`,
+			&TxSignReq{}, &TxSignReq{Data: []byte("hi")}, ""},
+		{"list of messages", `{"batch": [{"to": "b", "value": 1}, {"to": "c", "value": 2}]}`,
+			&TxSignReq{}, &TxSignReq{Batch: []*Transfer{{To: "b", Value: 1}, {To: "c", Value: 2}}}, ""},
+		{"message", `{"htlc": {"op": "lock", "hashLock": "h", "timeLock": 3}}`,
+			&TxSignReq{}, &TxSignReq{HTLC: &HTLC{Op: "lock", HashLock: "h", TimeLock: 3}}, ""},
+		{"JSON resource", `{"hash": "h", "merkleProof": [{"hash": "p"}]}`,
+			&TxVerifyReq{}, &TxVerifyReq{Hash: "h", MerkleProof: []byte(`[{"hash": "p"}]`)}, ""},
+		{"resource message", `{"from": "a"}`,
+			&TxSendReq{}, &TxSendReq{Tx: []byte(`{"from": "a"}`)}, ""},
+		{"invalid resource", `{"from": `, &TxSendReq{}, nil, "invalid JSON"},
+		{"unknown field", `{"form": "a"}`, &TxSignReq{}, nil, "unknown field form"},
+		{"invalid value", `{"value": "ten"}`, &TxSignReq{}, nil, "value:"},
+		{"invalid list", `{"batch": {"to": "b"}}`, &TxSignReq{}, nil, "batch:"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := decodeMsg([]byte(c.body), c.msg.ProtoReflect())
+			if c.err != "" {
+				if err == nil || !strings.Contains(err.Error(), c.err) {
+					t.Fatalf("expected error %q, got %v", c.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !proto.Equal(c.msg, c.exp) {
+				t.Fatalf("expected %v, got %v", c.exp, c.msg)
+			}
+		})
+	}
+}
+
+func TestGatewayEncode(t *testing.T) {
+	cases := []struct {
+		name string
+		msg  proto.Message
+		exp  string
+	}{
+		{"fields", &AccountBalanceRes{Balance: 10}, `{"balance":10}`},
+		{"message", &HTLC{Op: "claim", Lock: "l", Preimage: []byte("hi")},
+			`{"hashLock":"","lock":"l","op":"claim","preimage":"aGk=","timeLock":0}`},
+		{"JSON resource", &TxVerifyReq{Hash: "h", MerkleProof: []byte(`[{"hash":"p"}]`)},
+			`{"hash":"h","merkleProof":[{"hash":"p"}],"merkleRoot":""}`},
+		{"empty JSON resource", &TxVerifyReq{Hash: "h"},
+			`{"hash":"h","merkleProof":null,"merkleRoot":""}`},
+		{"resource message", &TxStatusRes{Receipt: []byte(`{"status":"pending"}`)},
+			`{"status":"pending"}`},
+		{"list of messages", &TxSignReq{Batch: []*Transfer{{To: "b", Value: 1}}},
+			`[{"to":"b","value":1}]`},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			jmsg, err := json.Marshal(encodeMsg(c.msg.ProtoReflect()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c.name == "list of messages" {
+				var obj map[string]json.RawMessage
+				err = json.Unmarshal(jmsg, &obj)
+				if err != nil {
+					t.Fatal(err)
+				}
+				jmsg = obj["batch"]
+			}
+			if string(jmsg) != c.exp {
+				t.Fatalf("expected %s, got %s", c.exp, jmsg)
+			}
+		})
+	}
+}
+
+func TestGatewayPage(t *testing.T) {
+	cases := []struct {
+		query         string
+		limit, offset int
+		err           bool
+	}{
+		{"", gatewayPageLimit, 0, false},
+		{"limit=10&offset=20", 10, 20, false},
+		{"limit=1000", 1000, 0, false},
+		{"limit=0", 0, 0, true},
+		{"limit=1001", 0, 0, true},
+		{"offset=-1", 0, 0, true},
+		{"limit=ten", 0, 0, true},
+	}
+	for _, c := range cases {
+		t.Run(c.query, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/v1/txs?"+c.query, nil)
+			limit, offset, err := readPage(r)
+			if (err != nil) != c.err {
+				t.Fatalf("expected error %v, got %v", c.err, err)
+			}
+			if limit != c.limit || offset != c.offset {
+				t.Fatalf("expected %d %d, got %d %d", c.limit, c.offset, limit, offset)
+			}
+		})
+	}
+}
+
+// testConn answers unary calls with the response and streams the responses
+// of the stream, then blocks until the call is canceled
+type testConn struct {
+	res    proto.Message
+	stream []proto.Message
+}
+
+func (c *testConn) Invoke(
+	_ context.Context, _ string, _, res any, _ ...grpc.CallOption,
+) error {
+	proto.Merge(res.(proto.Message), c.res)
+	return nil
+}
+
+func (c *testConn) NewStream(
+	ctx context.Context, _ *grpc.StreamDesc, _ string, _ ...grpc.CallOption,
+) (grpc.ClientStream, error) {
+	return &testStream{ctx: ctx, res: c.stream}, nil
+}
+
+type testStream struct {
+	ctx context.Context
+	res []proto.Message
+}
+
+func (s *testStream) Header() (metadata.MD, error) { return nil, nil }
+func (s *testStream) Trailer() metadata.MD         { return nil }
+func (s *testStream) CloseSend() error             { return nil }
+func (s *testStream) Context() context.Context     { return s.ctx }
+func (s *testStream) SendMsg(any) error            { return nil }
+
+func (s *testStream) RecvMsg(res any) error {
+	if len(s.res) == 0 {
+		<-s.ctx.Done()
+		return status.FromContextError(s.ctx.Err()).Err()
+	}
+	proto.Merge(res.(proto.Message), s.res[0])
+	s.res = s.res[1:]
+	return nil
+}
+
+func TestGatewayRoutes(t *testing.T) {
+	pending := &TxWaitRes{Receipt: []byte(`{"status":"pending"}`)}
+	cases := []struct {
+		name    string
+		method  string
+		path    string
+		body    string
+		private bool
+		conn    *testConn
+		code    int
+		exp     string
+	}{
+		{"public", http.MethodGet, "/v1/accounts/a/balance", "", false,
+			&testConn{res: &AccountBalanceRes{Balance: 10}}, http.StatusOK, `{"balance":10}`},
+		{"private disabled", http.MethodPost, "/v1/txs/sign", `{"password": "p"}`, false,
+			&testConn{res: &TxSignRes{Tx: []byte(`{}`)}}, http.StatusForbidden, "PermissionDenied"},
+		{"private", http.MethodPost, "/v1/txs/sign", `{"password": "p"}`, true,
+			&testConn{res: &TxSignRes{Tx: []byte(`{}`)}}, http.StatusOK, `{}`},
+		{"body too large", http.MethodPost, "/v1/txs/verify",
+			`{"hash": "` + strings.Repeat("a", maxBodySize) + `"}`, false,
+			&testConn{res: &TxVerifyRes{}}, http.StatusBadRequest, "too large"},
+		{"wait for receipts", http.MethodGet, "/v1/txs/h/receipts", "", false,
+			&testConn{stream: []proto.Message{pending}}, http.StatusOK,
+			`{"items":[{"status":"pending"}]}`},
+		{"wait for unknown tx", http.MethodGet, "/v1/txs/h/receipts", "", false,
+			&testConn{}, http.StatusNotFound, "NotFound"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			gateway, err := NewGateway(c.conn, slog.New(slog.DiscardHandler))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c.private {
+				gateway.AllowPrivate()
+			}
+			var handler http.Handler = gateway
+			if strings.HasSuffix(c.path, "/receipts") {
+				// Waits end quickly in tests
+				handler = waitHandler(t, gateway, 50*time.Millisecond)
+			}
+			r := httptest.NewRequest(c.method, c.path, strings.NewReader(c.body))
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			res, _ := io.ReadAll(w.Body)
+			if w.Code != c.code || !strings.Contains(string(res), c.exp) {
+				t.Fatalf("expected %d %s, got %d %s", c.code, c.exp, w.Code, res)
+			}
+		})
+	}
+}
+
+// waitHandler serves the receipts of transactions with the timeout
+func waitHandler(t *testing.T, g *Gateway, timeout time.Duration) http.Handler {
+	t.Helper()
+	for _, rt := range gatewayRoutes {
+		if rt.rpc != "Tx.TxWait" {
+			continue
+		}
+		md, err := methodDesc(rt.rpc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rt.timeout = timeout
+		mux := http.NewServeMux()
+		mux.HandleFunc(rt.method+" "+rt.pattern, g.handle(rt, md))
+		return mux
+	}
+	t.Fatal("no route for Tx.TxWait")
+	return nil
+}
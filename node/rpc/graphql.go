@@ -0,0 +1,632 @@
+package rpc
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/Ansh1902396/chain"
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+const (
+	gqlMaxDepth       = 8
+	gqlMaxQueryLength = 8192
+	gqlPageLimit      = 20
+	gqlMaxPageLimit   = 100
+	// gqlMaxComplexity limits the number of nodes a query may request. Every
+	// connection costs its page size, every single lookup costs one, and
+	// every scan of the block store costs gqlScanCost
+	gqlMaxComplexity = 2000
+	gqlScanCost      = 50
+	// gqlMaxBodySize limits POST bodies with the query and its variables
+	gqlMaxBodySize = 64 << 10
+)
+
+const gqlSchema = `
+schema {
+  query: Query
+}
+
+scalar Time
+scalar Uint64
+
+type Query {
+  block(number: Int, hash: String): Block
+  blocks(from: Int, to: Int, first: Int, after: String): BlockConnection!
+  tx(hash: String!): Tx
+  txs(
+    account: String, since: Time, until: Time, first: Int, after: String
+  ): TxConnection!
+  account(address: String!): Account!
+}
+
+type Block {
+  number: Int!
+  hash: String!
+  parent: String!
+  parentBlock: Block
+  merkleRoot: String!
+  time: Time!
+  txs(first: Int, after: String): TxConnection!
+}
+
+type Tx {
+  hash: String!
+  from: Account!
+  to: Account
+  value: Uint64!
+  nonce: Int!
+  memo: String
+  transfers: [Transfer!]!
+  time: Time!
+  block: Block!
+  index: Int!
+}
+
+type Transfer {
+  to: Account!
+  value: Uint64!
+}
+
+type Account {
+  address: String!
+  balance: Uint64!
+  nonce: Int!
+  txs(since: Time, until: Time, first: Int, after: String): TxConnection!
+}
+
+type PageInfo {
+  hasNextPage: Boolean!
+  endCursor: String
+}
+
+type BlockEdge {
+  cursor: String!
+  node: Block!
+}
+
+type BlockConnection {
+  edges: [BlockEdge!]!
+  pageInfo: PageInfo!
+}
+
+type TxEdge {
+  cursor: String!
+  node: Tx!
+}
+
+type TxConnection {
+  edges: [TxEdge!]!
+  pageInfo: PageInfo!
+}
+`
+
+// Uint64 carries balances and values that exceed the 32-bit GraphQL Int
+type Uint64 uint64
+
+func (Uint64) ImplementsGraphQLType(name string) bool {
+	return name == "Uint64"
+}
+
+func (u *Uint64) UnmarshalGraphQL(input any) error {
+	switch input := input.(type) {
+	case int32:
+		if input < 0 {
+			return fmt.Errorf("expected unsigned integer, got %v", input)
+		}
+		*u = Uint64(input)
+		return nil
+	case string:
+		val, err := strconv.ParseUint(input, 10, 64)
+		*u = Uint64(val)
+		return err
+	default:
+		return fmt.Errorf("expected unsigned integer, got %T", input)
+	}
+}
+
+func (u Uint64) MarshalJSON() ([]byte, error) {
+	return strconv.AppendUint(nil, uint64(u), 10), nil
+}
+
+type gqlBudgetKey struct{}
+
+// charge spends the complexity budget of the query
+func charge(ctx context.Context, cost int) error {
+	budget, ok := ctx.Value(gqlBudgetKey{}).(*atomic.Int64)
+	if !ok {
+		return nil
+	}
+	if budget.Add(-int64(cost)) < 0 {
+		return fmt.Errorf("query complexity exceeds %d nodes", gqlMaxComplexity)
+	}
+	return nil
+}
+
+func pageSize(first *int32) (int, error) {
+	if first == nil {
+		return gqlPageLimit, nil
+	}
+	if *first < 1 || *first > gqlMaxPageLimit {
+		return 0, fmt.Errorf("first: expected 1 to %d, got %d", gqlMaxPageLimit, *first)
+	}
+	return int(*first), nil
+}
+
+// Cursors are opaque to clients and encode the position of a block or of a
+// transaction in the chain
+func blockCursor(number uint64) string {
+	return base64.RawURLEncoding.EncodeToString(fmt.Appendf(nil, "blk:%d", number))
+}
+
+func txCursor(number uint64, index int) string {
+	return base64.RawURLEncoding.EncodeToString(fmt.Appendf(nil, "tx:%d:%d", number, index))
+}
+
+func readCursor(cursor *string, kind string) ([]uint64, error) {
+	if cursor == nil {
+		return nil, nil
+	}
+	pos, err := base64.RawURLEncoding.DecodeString(*cursor)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor %v", *cursor)
+	}
+	parts := strings.Split(string(pos), ":")
+	if parts[0] != kind {
+		return nil, fmt.Errorf("invalid %v cursor %v", kind, *cursor)
+	}
+	vals := make([]uint64, len(parts)-1)
+	for i, part := range parts[1:] {
+		vals[i], err = strconv.ParseUint(part, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %v cursor %v", kind, *cursor)
+		}
+	}
+	return vals, nil
+}
+
+// GraphQLSrv serves GraphQL queries over blocks, transactions, and accounts
+// backed by the block store and the state
+type GraphQLSrv struct {
+	blockStoreDir string
+	state         StateReader
+	schema        *graphql.Schema
+}
+
+func NewGraphQLSrv(blockStoreDir string, state StateReader) (*GraphQLSrv, error) {
+	s := &GraphQLSrv{blockStoreDir: blockStoreDir, state: state}
+	schema, err := graphql.ParseSchema(
+		gqlSchema, &gqlQuery{s: s},
+		graphql.UseFieldResolvers(),
+		graphql.MaxDepth(gqlMaxDepth),
+		graphql.MaxQueryLength(gqlMaxQueryLength),
+	)
+	if err != nil {
+		return nil, err
+	}
+	s.schema = schema
+	return s, nil
+}
+
+type gqlReq struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+func (s *GraphQLSrv) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req gqlReq
+	switch r.Method {
+	case http.MethodGet:
+		req.Query = r.URL.Query().Get("query")
+		req.OperationName = r.URL.Query().Get("operationName")
+		if vars := r.URL.Query().Get("variables"); len(vars) > 0 {
+			err := json.Unmarshal([]byte(vars), &req.Variables)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+	case http.MethodPost:
+		body := http.MaxBytesReader(w, r.Body, gqlMaxBodySize)
+		err := json.NewDecoder(body).Decode(&req)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "GraphQL requires GET or POST", http.StatusMethodNotAllowed)
+		return
+	}
+	budget := new(atomic.Int64)
+	budget.Store(gqlMaxComplexity)
+	ctx := context.WithValue(r.Context(), gqlBudgetKey{}, budget)
+	res := s.schema.Exec(ctx, req.Query, req.OperationName, req.Variables)
+	writeJSON(w, http.StatusOK, res)
+}
+
+// scanBlocks yields the stored blocks in chain order until yield returns
+// false. Every scan reads the block store, so it is charged to the query
+func (s *GraphQLSrv) scanBlocks(
+	ctx context.Context, yield func(blk chain.SigBlock) bool,
+) error {
+	err := charge(ctx, gqlScanCost)
+	if err != nil {
+		return err
+	}
+	blocks, closeBlocks, err := chain.ReadBlocks(s.blockStoreDir)
+	if err != nil {
+		return err
+	}
+	defer closeBlocks()
+	for err, blk := range blocks {
+		if err != nil {
+			return err
+		}
+		if !yield(blk) {
+			return nil
+		}
+	}
+	return nil
+}
+
+func (s *GraphQLSrv) findBlock(
+	ctx context.Context, match func(blk chain.SigBlock) bool,
+) (*gqlBlock, error) {
+	err := charge(ctx, 1)
+	if err != nil {
+		return nil, err
+	}
+	var found *gqlBlock
+	err = s.scanBlocks(ctx, func(blk chain.SigBlock) bool {
+		if match(blk) {
+			found = &gqlBlock{s: s, blk: blk}
+			return false
+		}
+		return true
+	})
+	return found, err
+}
+
+type gqlTxFilter struct {
+	account      chain.Address
+	since, until *graphql.Time
+}
+
+func (f gqlTxFilter) match(blk chain.SigBlock, tx chain.SigTx) bool {
+	if f.since != nil && blk.Time.Before(f.since.Time) ||
+		f.until != nil && !blk.Time.Before(f.until.Time) {
+		return false
+	}
+	if len(f.account) == 0 || tx.From == f.account {
+		return true
+	}
+	for _, tr := range tx.Transfers() {
+		if tr.To == f.account {
+			return true
+		}
+	}
+	return false
+}
+
+// newTxConn pages the transactions of the blocks that match the filter
+func (s *GraphQLSrv) newTxConn(
+	ctx context.Context,
+	blocks func(ctx context.Context, yield func(blk chain.SigBlock) bool) error,
+	filter gqlTxFilter, first *int32, after *string,
+) (*gqlTxConn, error) {
+	size, err := pageSize(first)
+	if err != nil {
+		return nil, err
+	}
+	pos, err := readCursor(after, "tx")
+	if err != nil {
+		return nil, err
+	}
+	if pos != nil && len(pos) != 2 {
+		return nil, fmt.Errorf("invalid tx cursor %v", *after)
+	}
+	err = charge(ctx, size)
+	if err != nil {
+		return nil, err
+	}
+	conn := &gqlTxConn{Edges: make([]*gqlTxEdge, 0, size), PageInfo: &gqlPageInfo{}}
+	err = blocks(ctx, func(blk chain.SigBlock) bool {
+		for i, tx := range blk.Txs {
+			if pos != nil && (blk.Number < pos[0] ||
+				blk.Number == pos[0] && uint64(i) <= pos[1]) {
+				continue
+			}
+			if !filter.match(blk, tx) {
+				continue
+			}
+			if len(conn.Edges) == size {
+				conn.PageInfo.HasNextPage = true
+				return false
+			}
+			cursor := txCursor(blk.Number, i)
+			node := &gqlTx{s: s, blk: blk, tx: tx, index: i}
+			conn.Edges = append(conn.Edges, &gqlTxEdge{Cursor: cursor, Node: node})
+			conn.PageInfo.EndCursor = &cursor
+		}
+		return true
+	})
+	return conn, err
+}
+
+type gqlQuery struct {
+	s *GraphQLSrv
+}
+
+func (q *gqlQuery) Block(
+	ctx context.Context, args struct {
+		Number *int32
+		Hash   *string
+	},
+) (*gqlBlock, error) {
+	if args.Number == nil && args.Hash == nil {
+		return nil, fmt.Errorf("either number or hash must be provided")
+	}
+	return q.s.findBlock(ctx, func(blk chain.SigBlock) bool {
+		if args.Number != nil {
+			return blk.Number == uint64(*args.Number)
+		}
+		return blk.Hash().String() == *args.Hash
+	})
+}
+
+func (q *gqlQuery) Blocks(
+	ctx context.Context, args struct {
+		From, To *int32
+		First    *int32
+		After    *string
+	},
+) (*gqlBlockConn, error) {
+	size, err := pageSize(args.First)
+	if err != nil {
+		return nil, err
+	}
+	pos, err := readCursor(args.After, "blk")
+	if err != nil {
+		return nil, err
+	}
+	if pos != nil && len(pos) != 1 {
+		return nil, fmt.Errorf("invalid blk cursor %v", *args.After)
+	}
+	err = charge(ctx, size)
+	if err != nil {
+		return nil, err
+	}
+	conn := &gqlBlockConn{Edges: make([]*gqlBlockEdge, 0, size), PageInfo: &gqlPageInfo{}}
+	err = q.s.scanBlocks(ctx, func(blk chain.SigBlock) bool {
+		if args.From != nil && blk.Number < uint64(*args.From) ||
+			pos != nil && blk.Number <= pos[0] {
+			return true
+		}
+		if args.To != nil && blk.Number > uint64(*args.To) {
+			return false
+		}
+		if len(conn.Edges) == size {
+			conn.PageInfo.HasNextPage = true
+			return false
+		}
+		cursor := blockCursor(blk.Number)
+		node := &gqlBlock{s: q.s, blk: blk}
+		conn.Edges = append(conn.Edges, &gqlBlockEdge{Cursor: cursor, Node: node})
+		conn.PageInfo.EndCursor = &cursor
+		return true
+	})
+	return conn, err
+}
+
+func (q *gqlQuery) Tx(
+	ctx context.Context, args struct{ Hash string },
+) (*gqlTx, error) {
+	err := charge(ctx, 1)
+	if err != nil {
+		return nil, err
+	}
+	var found *gqlTx
+	err = q.s.scanBlocks(ctx, func(blk chain.SigBlock) bool {
+		for i, tx := range blk.Txs {
+			if tx.Hash().String() == args.Hash {
+				found = &gqlTx{s: q.s, blk: blk, tx: tx, index: i}
+				return false
+			}
+		}
+		return true
+	})
+	return found, err
+}
+
+func (q *gqlQuery) Txs(
+	ctx context.Context, args struct {
+		Account      *string
+		Since, Until *graphql.Time
+		First        *int32
+		After        *string
+	},
+) (*gqlTxConn, error) {
+	filter := gqlTxFilter{since: args.Since, until: args.Until}
+	if args.Account != nil {
+		filter.account = chain.Address(*args.Account)
+	}
+	return q.s.newTxConn(ctx, q.s.scanBlocks, filter, args.First, args.After)
+}
+
+func (q *gqlQuery) Account(args struct{ Address string }) *gqlAccount {
+	return &gqlAccount{s: q.s, Address: args.Address}
+}
+
+type gqlPageInfo struct {
+	HasNextPage bool
+	EndCursor   *string
+}
+
+type gqlBlockEdge struct {
+	Cursor string
+	Node   *gqlBlock
+}
+
+type gqlBlockConn struct {
+	Edges    []*gqlBlockEdge
+	PageInfo *gqlPageInfo
+}
+
+type gqlTxEdge struct {
+	Cursor string
+	Node   *gqlTx
+}
+
+type gqlTxConn struct {
+	Edges    []*gqlTxEdge
+	PageInfo *gqlPageInfo
+}
+
+type gqlBlock struct {
+	s   *GraphQLSrv
+	blk chain.SigBlock
+}
+
+func (b *gqlBlock) Number() int32 {
+	return int32(b.blk.Number)
+}
+
+func (b *gqlBlock) Hash() string {
+	return b.blk.Hash().String()
+}
+
+func (b *gqlBlock) Parent() string {
+	return b.blk.Parent.String()
+}
+
+func (b *gqlBlock) ParentBlock(ctx context.Context) (*gqlBlock, error) {
+	if b.blk.Number <= 1 {
+		return nil, nil
+	}
+	return b.s.findBlock(ctx, func(blk chain.SigBlock) bool {
+		return blk.Number == b.blk.Number-1
+	})
+}
+
+func (b *gqlBlock) MerkleRoot() string {
+	return b.blk.MerkleRoot.String()
+}
+
+func (b *gqlBlock) Time() graphql.Time {
+	return graphql.Time{Time: b.blk.Time}
+}
+
+func (b *gqlBlock) Txs(
+	ctx context.Context, args struct {
+		First *int32
+		After *string
+	},
+) (*gqlTxConn, error) {
+	blocks := func(_ context.Context, yield func(blk chain.SigBlock) bool) error {
+		yield(b.blk)
+		return nil
+	}
+	return b.s.newTxConn(ctx, blocks, gqlTxFilter{}, args.First, args.After)
+}
+
+type gqlTx struct {
+	s     *GraphQLSrv
+	blk   chain.SigBlock
+	tx    chain.SigTx
+	index int
+}
+
+func (t *gqlTx) Hash() string {
+	return t.tx.Hash().String()
+}
+
+func (t *gqlTx) From() *gqlAccount {
+	return &gqlAccount{s: t.s, Address: string(t.tx.From)}
+}
+
+func (t *gqlTx) To() *gqlAccount {
+	if len(t.tx.To) == 0 {
+		return nil
+	}
+	return &gqlAccount{s: t.s, Address: string(t.tx.To)}
+}
+
+// Value is the total of all transfers of a batch transaction
+func (t *gqlTx) Value() Uint64 {
+	total, _ := t.tx.Total()
+	return Uint64(total)
+}
+
+func (t *gqlTx) Nonce() int32 {
+	return int32(t.tx.Nonce)
+}
+
+func (t *gqlTx) Memo() *string {
+	if len(t.tx.Data) == 0 {
+		return nil
+	}
+	memo := string(t.tx.Data)
+	return &memo
+}
+
+func (t *gqlTx) Transfers() []*gqlTransfer {
+	trs := make([]*gqlTransfer, 0)
+	for _, tr := range t.tx.Transfers() {
+		trs = append(trs, &gqlTransfer{
+			To: &gqlAccount{s: t.s, Address: string(tr.To)}, Value: Uint64(tr.Value),
+		})
+	}
+	return trs
+}
+
+func (t *gqlTx) Time() graphql.Time {
+	return graphql.Time{Time: t.tx.Time}
+}
+
+func (t *gqlTx) Block() *gqlBlock {
+	return &gqlBlock{s: t.s, blk: t.blk}
+}
+
+func (t *gqlTx) Index() int32 {
+	return int32(t.index)
+}
+
+type gqlTransfer struct {
+	To    *gqlAccount
+	Value Uint64
+}
+
+type gqlAccount struct {
+	s       *GraphQLSrv
+	Address string
+}
+
+func (a *gqlAccount) Balance() Uint64 {
+	balance, _ := a.s.state.Balance(chain.Address(a.Address))
+	return Uint64(balance)
+}
+
+func (a *gqlAccount) Nonce() int32 {
+	return int32(a.s.state.Nonce(chain.Address(a.Address)))
+}
+
+func (a *gqlAccount) Txs(
+	ctx context.Context, args struct {
+		Since, Until *graphql.Time
+		First        *int32
+		After        *string
+	},
+) (*gqlTxConn, error) {
+	filter := gqlTxFilter{
+		account: chain.Address(a.Address), since: args.Since, until: args.Until,
+	}
+	return a.s.newTxConn(ctx, a.s.scanBlocks, filter, args.First, args.After)
+}
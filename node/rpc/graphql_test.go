@@ -0,0 +1,126 @@
+package rpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Ansh1902396/chain"
+)
+
+type testState struct{}
+
+func (testState) LastBlock() chain.SigBlock            { return chain.SigBlock{} }
+func (testState) Balance(chain.Address) (uint64, bool) { return 10, true }
+func (testState) Nonce(chain.Address) uint64           { return 1 }
+
+// testBlockStore writes n blocks with a transaction each to a block store
+func testBlockStore(t *testing.T, n int) string {
+	t.Helper()
+	dir := t.TempDir()
+	err := chain.InitBlockStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	acc, err := chain.NewAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var parent chain.Hash
+	for i := range n {
+		tx := chain.NewTx(chain.SystemClock, acc.Address(), acc.Address(), 1, uint64(i)+1, nil)
+		stx, err := acc.SignTx(tx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		blk, err := chain.NewBlock(chain.SystemClock, uint64(i)+1, parent, []chain.SigTx{stx})
+		if err != nil {
+			t.Fatal(err)
+		}
+		sblk, err := acc.SignBlock(blk)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = sblk.Write(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parent = sblk.Hash()
+	}
+	return dir
+}
+
+func TestGraphQLComplexity(t *testing.T) {
+	var lookups strings.Builder
+	for i := range gqlMaxComplexity/(gqlScanCost+1) + 1 {
+		fmt.Fprintf(&lookups, "b%d: block(number: 1) { number } ", i)
+	}
+	// Two of the blocks have a parent
+	var parents strings.Builder
+	for i := range gqlMaxComplexity/(gqlScanCost+1)/2 + 1 {
+		fmt.Fprintf(&parents, "p%d: parentBlock { number } ", i)
+	}
+	cases := []struct {
+		name  string
+		query string
+		exp   string
+		err   string
+	}{
+		{"blocks", `{ blocks(first: 3) { edges { node { number } } } }`,
+			`{"blocks":{"edges":[{"node":{"number":1}},{"node":{"number":2}},{"node":{"number":3}}]}}`, ""},
+		{"parent blocks", `{ block(number: 3) { parentBlock { parentBlock { number } } } }`,
+			`{"block":{"parentBlock":{"parentBlock":{"number":1}}}}`, ""},
+		{"block txs", `{ block(number: 2) { txs { edges { node { nonce } } } } }`,
+			`{"block":{"txs":{"edges":[{"node":{"nonce":2}}]}}}`, ""},
+		{"store scans", "{ " + lookups.String() + "}", "", "complexity exceeds"},
+		{"parent block scans", "{ blocks(first: 3) { edges { node { " + parents.String() + "} } } }",
+			"", "complexity exceeds"},
+	}
+	gql, err := NewGraphQLSrv(testBlockStore(t, 3), testState{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			jreq, _ := json.Marshal(gqlReq{Query: c.query})
+			r := httptest.NewRequest(http.MethodPost, "/v1/graphql", strings.NewReader(string(jreq)))
+			w := httptest.NewRecorder()
+			gql.ServeHTTP(w, r)
+			var res struct {
+				Data   json.RawMessage
+				Errors []struct{ Message string }
+			}
+			err := json.NewDecoder(w.Body).Decode(&res)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c.err != "" {
+				if len(res.Errors) == 0 || !strings.Contains(res.Errors[0].Message, c.err) {
+					t.Fatalf("expected error %q, got %v", c.err, res.Errors)
+				}
+				return
+			}
+			if len(res.Errors) > 0 || string(res.Data) != c.exp {
+				t.Fatalf("expected %s, got %s %v", c.exp, res.Data, res.Errors)
+			}
+		})
+	}
+}
+
+func TestGraphQLBodySize(t *testing.T) {
+	gql, err := NewGraphQLSrv(t.TempDir(), testState{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := `{"query": "{ block(number: 1) { number } }", "variables": {"pad": "` +
+		strings.Repeat("a", gqlMaxBodySize) + `"}}`
+	r := httptest.NewRequest(http.MethodPost, "/v1/graphql", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	gql.ServeHTTP(w, r)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected %d, got %d %s", http.StatusBadRequest, w.Code, w.Body)
+	}
+}
@@ -0,0 +1,29 @@
+package rpc
+
+import (
+	"github.com/Ansh1902396/chain"
+	"google.golang.org/grpc"
+)
+
+// DefaultMsgSize is the gRPC default max received message size, the message
+// size limits never go below it
+const DefaultMsgSize = 4 << 20
+
+// msgOverhead leaves room for the protobuf framing and the trace context of
+// an encoded block
+const msgOverhead = 64 << 10
+
+// MaxMsgSize limits the messages received by the clients, which do not know
+// the chain parameters of the node
+const MaxMsgSize = chain.MaxBlockSizeLimit + msgOverhead
+
+// MsgSize returns the max message size received by the node for the block
+// size limit of the chain
+func MsgSize(params chain.Params) int {
+	return max(DefaultMsgSize, params.SizeLimit()+msgOverhead)
+}
+
+// WithMaxMsgSize limits the messages received by a client
+func WithMaxMsgSize() grpc.DialOption {
+	return grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(MaxMsgSize))
+}
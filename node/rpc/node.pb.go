@@ -112,6 +112,13 @@ func (x *PeerDiscoverRes) GetPeers() []string {
 type StreamSubscribeReq struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	EventTypes    []uint64               `protobuf:"varint,1,rep,packed,name=EventTypes,proto3" json:"EventTypes,omitempty"`
+	Cursor        uint64                 `protobuf:"varint,2,opt,name=Cursor,proto3" json:"Cursor,omitempty"`
+	Height        uint64                 `protobuf:"varint,3,opt,name=Height,proto3" json:"Height,omitempty"`
+	Account       string                 `protobuf:"bytes,4,opt,name=Account,proto3" json:"Account,omitempty"`
+	MinValue      uint64                 `protobuf:"varint,5,opt,name=MinValue,proto3" json:"MinValue,omitempty"`
+	Actions       []string               `protobuf:"bytes,6,rep,name=Actions,proto3" json:"Actions,omitempty"`
+	MinTxs        uint64                 `protobuf:"varint,7,opt,name=MinTxs,proto3" json:"MinTxs,omitempty"`
+	MaxHeight     uint64                 `protobuf:"varint,8,opt,name=MaxHeight,proto3" json:"MaxHeight,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -153,6 +160,55 @@ func (x *StreamSubscribeReq) GetEventTypes() []uint64 {
 	return nil
 }
 
+func (x *StreamSubscribeReq) GetCursor() uint64 {
+	if x != nil {
+		return x.Cursor
+	}
+	return 0
+}
+
+func (x *StreamSubscribeReq) GetHeight() uint64 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *StreamSubscribeReq) GetAccount() string {
+	if x != nil {
+		return x.Account
+	}
+	return ""
+}
+
+func (x *StreamSubscribeReq) GetMinValue() uint64 {
+	if x != nil {
+		return x.MinValue
+	}
+	return 0
+}
+
+func (x *StreamSubscribeReq) GetActions() []string {
+	if x != nil {
+		return x.Actions
+	}
+	return nil
+}
+
+func (x *StreamSubscribeReq) GetMinTxs() uint64 {
+	if x != nil {
+		return x.MinTxs
+	}
+	return 0
+}
+
+func (x *StreamSubscribeReq) GetMaxHeight() uint64 {
+	if x != nil {
+		return x.MaxHeight
+	}
+	return 0
+}
+
 type StreamSubscribeRes struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Event         []byte                 `protobuf:"bytes,1,opt,name=Event,proto3" json:"Event,omitempty"`
@@ -197,6 +253,430 @@ func (x *StreamSubscribeRes) GetEvent() []byte {
 	return nil
 }
 
+type WebhookCreateReq struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	URL           string                 `protobuf:"bytes,1,opt,name=URL,proto3" json:"URL,omitempty"`
+	Secret        string                 `protobuf:"bytes,2,opt,name=Secret,proto3" json:"Secret,omitempty"`
+	Filter        *StreamSubscribeReq    `protobuf:"bytes,3,opt,name=Filter,proto3" json:"Filter,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WebhookCreateReq) Reset() {
+	*x = WebhookCreateReq{}
+	mi := &file_node_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WebhookCreateReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WebhookCreateReq) ProtoMessage() {}
+
+func (x *WebhookCreateReq) ProtoReflect() protoreflect.Message {
+	mi := &file_node_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WebhookCreateReq.ProtoReflect.Descriptor instead.
+func (*WebhookCreateReq) Descriptor() ([]byte, []int) {
+	return file_node_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *WebhookCreateReq) GetURL() string {
+	if x != nil {
+		return x.URL
+	}
+	return ""
+}
+
+func (x *WebhookCreateReq) GetSecret() string {
+	if x != nil {
+		return x.Secret
+	}
+	return ""
+}
+
+func (x *WebhookCreateReq) GetFilter() *StreamSubscribeReq {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+type WebhookCreateRes struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Webhook       []byte                 `protobuf:"bytes,1,opt,name=Webhook,proto3" json:"Webhook,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WebhookCreateRes) Reset() {
+	*x = WebhookCreateRes{}
+	mi := &file_node_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WebhookCreateRes) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WebhookCreateRes) ProtoMessage() {}
+
+func (x *WebhookCreateRes) ProtoReflect() protoreflect.Message {
+	mi := &file_node_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WebhookCreateRes.ProtoReflect.Descriptor instead.
+func (*WebhookCreateRes) Descriptor() ([]byte, []int) {
+	return file_node_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *WebhookCreateRes) GetWebhook() []byte {
+	if x != nil {
+		return x.Webhook
+	}
+	return nil
+}
+
+type WebhookListReq struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WebhookListReq) Reset() {
+	*x = WebhookListReq{}
+	mi := &file_node_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WebhookListReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WebhookListReq) ProtoMessage() {}
+
+func (x *WebhookListReq) ProtoReflect() protoreflect.Message {
+	mi := &file_node_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WebhookListReq.ProtoReflect.Descriptor instead.
+func (*WebhookListReq) Descriptor() ([]byte, []int) {
+	return file_node_proto_rawDescGZIP(), []int{6}
+}
+
+type WebhookListRes struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Webhook       []byte                 `protobuf:"bytes,1,opt,name=Webhook,proto3" json:"Webhook,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WebhookListRes) Reset() {
+	*x = WebhookListRes{}
+	mi := &file_node_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WebhookListRes) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WebhookListRes) ProtoMessage() {}
+
+func (x *WebhookListRes) ProtoReflect() protoreflect.Message {
+	mi := &file_node_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WebhookListRes.ProtoReflect.Descriptor instead.
+func (*WebhookListRes) Descriptor() ([]byte, []int) {
+	return file_node_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *WebhookListRes) GetWebhook() []byte {
+	if x != nil {
+		return x.Webhook
+	}
+	return nil
+}
+
+type WebhookDeleteReq struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ID            string                 `protobuf:"bytes,1,opt,name=ID,proto3" json:"ID,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WebhookDeleteReq) Reset() {
+	*x = WebhookDeleteReq{}
+	mi := &file_node_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WebhookDeleteReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WebhookDeleteReq) ProtoMessage() {}
+
+func (x *WebhookDeleteReq) ProtoReflect() protoreflect.Message {
+	mi := &file_node_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WebhookDeleteReq.ProtoReflect.Descriptor instead.
+func (*WebhookDeleteReq) Descriptor() ([]byte, []int) {
+	return file_node_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *WebhookDeleteReq) GetID() string {
+	if x != nil {
+		return x.ID
+	}
+	return ""
+}
+
+type WebhookDeleteRes struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WebhookDeleteRes) Reset() {
+	*x = WebhookDeleteRes{}
+	mi := &file_node_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WebhookDeleteRes) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WebhookDeleteRes) ProtoMessage() {}
+
+func (x *WebhookDeleteRes) ProtoReflect() protoreflect.Message {
+	mi := &file_node_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WebhookDeleteRes.ProtoReflect.Descriptor instead.
+func (*WebhookDeleteRes) Descriptor() ([]byte, []int) {
+	return file_node_proto_rawDescGZIP(), []int{9}
+}
+
+type NodeStatusReq struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NodeStatusReq) Reset() {
+	*x = NodeStatusReq{}
+	mi := &file_node_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NodeStatusReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NodeStatusReq) ProtoMessage() {}
+
+func (x *NodeStatusReq) ProtoReflect() protoreflect.Message {
+	mi := &file_node_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NodeStatusReq.ProtoReflect.Descriptor instead.
+func (*NodeStatusReq) Descriptor() ([]byte, []int) {
+	return file_node_proto_rawDescGZIP(), []int{10}
+}
+
+type NodeStatusRes struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Chain         string                 `protobuf:"bytes,1,opt,name=Chain,proto3" json:"Chain,omitempty"`
+	Height        uint64                 `protobuf:"varint,2,opt,name=Height,proto3" json:"Height,omitempty"`
+	HeadHash      string                 `protobuf:"bytes,3,opt,name=HeadHash,proto3" json:"HeadHash,omitempty"`
+	GenesisHash   string                 `protobuf:"bytes,4,opt,name=GenesisHash,proto3" json:"GenesisHash,omitempty"`
+	Peers         uint64                 `protobuf:"varint,5,opt,name=Peers,proto3" json:"Peers,omitempty"`
+	Sync          string                 `protobuf:"bytes,6,opt,name=Sync,proto3" json:"Sync,omitempty"`
+	Pending       uint64                 `protobuf:"varint,7,opt,name=Pending,proto3" json:"Pending,omitempty"`
+	Uptime        uint64                 `protobuf:"varint,8,opt,name=Uptime,proto3" json:"Uptime,omitempty"`
+	Version       string                 `protobuf:"bytes,9,opt,name=Version,proto3" json:"Version,omitempty"`
+	Authority     string                 `protobuf:"bytes,10,opt,name=Authority,proto3" json:"Authority,omitempty"`
+	IsAuthority   bool                   `protobuf:"varint,11,opt,name=IsAuthority,proto3" json:"IsAuthority,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NodeStatusRes) Reset() {
+	*x = NodeStatusRes{}
+	mi := &file_node_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NodeStatusRes) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NodeStatusRes) ProtoMessage() {}
+
+func (x *NodeStatusRes) ProtoReflect() protoreflect.Message {
+	mi := &file_node_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NodeStatusRes.ProtoReflect.Descriptor instead.
+func (*NodeStatusRes) Descriptor() ([]byte, []int) {
+	return file_node_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *NodeStatusRes) GetChain() string {
+	if x != nil {
+		return x.Chain
+	}
+	return ""
+}
+
+func (x *NodeStatusRes) GetHeight() uint64 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *NodeStatusRes) GetHeadHash() string {
+	if x != nil {
+		return x.HeadHash
+	}
+	return ""
+}
+
+func (x *NodeStatusRes) GetGenesisHash() string {
+	if x != nil {
+		return x.GenesisHash
+	}
+	return ""
+}
+
+func (x *NodeStatusRes) GetPeers() uint64 {
+	if x != nil {
+		return x.Peers
+	}
+	return 0
+}
+
+func (x *NodeStatusRes) GetSync() string {
+	if x != nil {
+		return x.Sync
+	}
+	return ""
+}
+
+func (x *NodeStatusRes) GetPending() uint64 {
+	if x != nil {
+		return x.Pending
+	}
+	return 0
+}
+
+func (x *NodeStatusRes) GetUptime() uint64 {
+	if x != nil {
+		return x.Uptime
+	}
+	return 0
+}
+
+func (x *NodeStatusRes) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *NodeStatusRes) GetAuthority() string {
+	if x != nil {
+		return x.Authority
+	}
+	return ""
+}
+
+func (x *NodeStatusRes) GetIsAuthority() bool {
+	if x != nil {
+		return x.IsAuthority
+	}
+	return false
+}
+
 var File_node_proto protoreflect.FileDescriptor
 
 const file_node_proto_rawDesc = "" +
@@ -206,16 +686,54 @@ const file_node_proto_rawDesc = "" +
 	"\x0fPeerDiscoverReq\x12\x12\n" +
 	"\x04Peer\x18\x01 \x01(\tR\x04Peer\"'\n" +
 	"\x0fPeerDiscoverRes\x12\x14\n" +
-	"\x05Peers\x18\x01 \x03(\tR\x05Peers\"4\n" +
+	"\x05Peers\x18\x01 \x03(\tR\x05Peers\"\xea\x01\n" +
 	"\x12StreamSubscribeReq\x12\x1e\n" +
 	"\n" +
 	"EventTypes\x18\x01 \x03(\x04R\n" +
-	"EventTypes\"*\n" +
+	"EventTypes\x12\x16\n" +
+	"\x06Cursor\x18\x02 \x01(\x04R\x06Cursor\x12\x16\n" +
+	"\x06Height\x18\x03 \x01(\x04R\x06Height\x12\x18\n" +
+	"\aAccount\x18\x04 \x01(\tR\aAccount\x12\x1a\n" +
+	"\bMinValue\x18\x05 \x01(\x04R\bMinValue\x12\x18\n" +
+	"\aActions\x18\x06 \x03(\tR\aActions\x12\x16\n" +
+	"\x06MinTxs\x18\a \x01(\x04R\x06MinTxs\x12\x1c\n" +
+	"\tMaxHeight\x18\b \x01(\x04R\tMaxHeight\"*\n" +
 	"\x12StreamSubscribeRes\x12\x14\n" +
-	"\x05Event\x18\x01 \x01(\fR\x05Event2y\n" +
+	"\x05Event\x18\x01 \x01(\fR\x05Event\"i\n" +
+	"\x10WebhookCreateReq\x12\x10\n" +
+	"\x03URL\x18\x01 \x01(\tR\x03URL\x12\x16\n" +
+	"\x06Secret\x18\x02 \x01(\tR\x06Secret\x12+\n" +
+	"\x06Filter\x18\x03 \x01(\v2\x13.StreamSubscribeReqR\x06Filter\",\n" +
+	"\x10WebhookCreateRes\x12\x18\n" +
+	"\aWebhook\x18\x01 \x01(\fR\aWebhook\"\x10\n" +
+	"\x0eWebhookListReq\"*\n" +
+	"\x0eWebhookListRes\x12\x18\n" +
+	"\aWebhook\x18\x01 \x01(\fR\aWebhook\"\"\n" +
+	"\x10WebhookDeleteReq\x12\x0e\n" +
+	"\x02ID\x18\x01 \x01(\tR\x02ID\"\x12\n" +
+	"\x10WebhookDeleteRes\"\x0f\n" +
+	"\rNodeStatusReq\"\xb1\x02\n" +
+	"\rNodeStatusRes\x12\x14\n" +
+	"\x05Chain\x18\x01 \x01(\tR\x05Chain\x12\x16\n" +
+	"\x06Height\x18\x02 \x01(\x04R\x06Height\x12\x1a\n" +
+	"\bHeadHash\x18\x03 \x01(\tR\bHeadHash\x12 \n" +
+	"\vGenesisHash\x18\x04 \x01(\tR\vGenesisHash\x12\x14\n" +
+	"\x05Peers\x18\x05 \x01(\x04R\x05Peers\x12\x12\n" +
+	"\x04Sync\x18\x06 \x01(\tR\x04Sync\x12\x18\n" +
+	"\aPending\x18\a \x01(\x04R\aPending\x12\x16\n" +
+	"\x06Uptime\x18\b \x01(\x04R\x06Uptime\x12\x18\n" +
+	"\aVersion\x18\t \x01(\tR\aVersion\x12\x1c\n" +
+	"\tAuthority\x18\n" +
+	" \x01(\tR\tAuthority\x12 \n" +
+	"\vIsAuthority\x18\v \x01(\bR\vIsAuthority2\xc8\x02\n" +
 	"\x04Node\x122\n" +
 	"\fPeerDiscover\x12\x10.PeerDiscoverReq\x1a\x10.PeerDiscoverRes\x12=\n" +
-	"\x0fStreamSubscribe\x12\x13.StreamSubscribeReq\x1a\x13.StreamSubscribeRes0\x01B\aZ\x05./rpcb\x06proto3"
+	"\x0fStreamSubscribe\x12\x13.StreamSubscribeReq\x1a\x13.StreamSubscribeRes0\x01\x125\n" +
+	"\rWebhookCreate\x12\x11.WebhookCreateReq\x1a\x11.WebhookCreateRes\x121\n" +
+	"\vWebhookList\x12\x0f.WebhookListReq\x1a\x0f.WebhookListRes0\x01\x125\n" +
+	"\rWebhookDelete\x12\x11.WebhookDeleteReq\x1a\x11.WebhookDeleteRes\x12,\n" +
+	"\n" +
+	"NodeStatus\x12\x0e.NodeStatusReq\x1a\x0e.NodeStatusResB\aZ\x05./rpcb\x06proto3"
 
 var (
 	file_node_proto_rawDescOnce sync.Once
@@ -229,23 +747,40 @@ func file_node_proto_rawDescGZIP() []byte {
 	return file_node_proto_rawDescData
 }
 
-var file_node_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_node_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
 var file_node_proto_goTypes = []any{
 	(*PeerDiscoverReq)(nil),    // 0: PeerDiscoverReq
 	(*PeerDiscoverRes)(nil),    // 1: PeerDiscoverRes
 	(*StreamSubscribeReq)(nil), // 2: StreamSubscribeReq
 	(*StreamSubscribeRes)(nil), // 3: StreamSubscribeRes
+	(*WebhookCreateReq)(nil),   // 4: WebhookCreateReq
+	(*WebhookCreateRes)(nil),   // 5: WebhookCreateRes
+	(*WebhookListReq)(nil),     // 6: WebhookListReq
+	(*WebhookListRes)(nil),     // 7: WebhookListRes
+	(*WebhookDeleteReq)(nil),   // 8: WebhookDeleteReq
+	(*WebhookDeleteRes)(nil),   // 9: WebhookDeleteRes
+	(*NodeStatusReq)(nil),      // 10: NodeStatusReq
+	(*NodeStatusRes)(nil),      // 11: NodeStatusRes
 }
 var file_node_proto_depIdxs = []int32{
-	0, // 0: Node.PeerDiscover:input_type -> PeerDiscoverReq
-	2, // 1: Node.StreamSubscribe:input_type -> StreamSubscribeReq
-	1, // 2: Node.PeerDiscover:output_type -> PeerDiscoverRes
-	3, // 3: Node.StreamSubscribe:output_type -> StreamSubscribeRes
-	2, // [2:4] is the sub-list for method output_type
-	0, // [0:2] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	2,  // 0: WebhookCreateReq.Filter:type_name -> StreamSubscribeReq
+	0,  // 1: Node.PeerDiscover:input_type -> PeerDiscoverReq
+	2,  // 2: Node.StreamSubscribe:input_type -> StreamSubscribeReq
+	4,  // 3: Node.WebhookCreate:input_type -> WebhookCreateReq
+	6,  // 4: Node.WebhookList:input_type -> WebhookListReq
+	8,  // 5: Node.WebhookDelete:input_type -> WebhookDeleteReq
+	10, // 6: Node.NodeStatus:input_type -> NodeStatusReq
+	1,  // 7: Node.PeerDiscover:output_type -> PeerDiscoverRes
+	3,  // 8: Node.StreamSubscribe:output_type -> StreamSubscribeRes
+	5,  // 9: Node.WebhookCreate:output_type -> WebhookCreateRes
+	7,  // 10: Node.WebhookList:output_type -> WebhookListRes
+	9,  // 11: Node.WebhookDelete:output_type -> WebhookDeleteRes
+	11, // 12: Node.NodeStatus:output_type -> NodeStatusRes
+	7,  // [7:13] is the sub-list for method output_type
+	1,  // [1:7] is the sub-list for method input_type
+	1,  // [1:1] is the sub-list for extension type_name
+	1,  // [1:1] is the sub-list for extension extendee
+	0,  // [0:1] is the sub-list for field type_name
 }
 
 func init() { file_node_proto_init() }
@@ -259,7 +794,7 @@ func file_node_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_node_proto_rawDesc), len(file_node_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   4,
+			NumMessages:   12,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
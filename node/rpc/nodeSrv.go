@@ -3,9 +3,12 @@ package rpc
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"math/rand"
-	"slices"
+	"net/url"
+	"time"
 
 	"github.com/Ansh1902396/chain"
 	"google.golang.org/grpc"
@@ -20,27 +23,55 @@ type PeerDiscoverer interface {
 }
 
 type EventStreamer interface {
-	AddSubscriber(sub string) chan chain.Event
+	AddSubscriber(sub string) (chan chain.Event, uint64)
 	RemoveSubscriber(sub string)
 }
 
+type WebhookRegistry interface {
+	AddWebhook(
+		url, secret string, filter chain.EventFilter, cursor uint64,
+	) (chain.Webhook, error)
+	RemoveWebhook(id string) error
+	Webhooks() []chain.Webhook
+}
+
+type StatusReader interface {
+	LastBlock() chain.SigBlock
+	PendingCount() int
+	SyncStatus() string
+	Uptime() time.Duration
+	Version() string
+	IsAuthority() bool
+}
+
 type NodeSrv struct {
 	UnimplementedNodeServer
-	peerDisc   PeerDiscoverer
-	evStreamer EventStreamer
+	blockStoreDir string
+	peerDisc      PeerDiscoverer
+	evStreamer    EventStreamer
+	webhooks      WebhookRegistry
+	status        StatusReader
+	log           *slog.Logger
 }
 
-func NewNodeSrv(peerDisc PeerDiscoverer, evStreamer EventStreamer) *NodeSrv {
+func NewNodeSrv(
+	blockStoreDir string, peerDisc PeerDiscoverer, evStreamer EventStreamer,
+	webhooks WebhookRegistry, status StatusReader, log *slog.Logger,
+) *NodeSrv {
 	return &NodeSrv{
-		peerDisc:   peerDisc,
-		evStreamer: evStreamer,
+		blockStoreDir: blockStoreDir,
+		peerDisc:      peerDisc,
+		evStreamer:    evStreamer,
+		webhooks:      webhooks,
+		status:        status,
+		log:           log,
 	}
 }
 
 func (s *NodeSrv) PeerDiscover(
 	_ context.Context, req *PeerDiscoverReq,
 ) (*PeerDiscoverRes, error) {
-	if s.peerDisc.Bootstrap() {
+	if s.peerDisc.Bootstrap() && len(req.Peer) > 0 {
 		s.peerDisc.AddPeers(req.Peer)
 	}
 	peers := s.peerDisc.Peers()
@@ -48,14 +79,75 @@ func (s *NodeSrv) PeerDiscover(
 	return res, nil
 }
 
+func (s *NodeSrv) NodeStatus(
+	_ context.Context, _ *NodeStatusReq,
+) (*NodeStatusRes, error) {
+	gen, err := chain.ReadGenesis(s.blockStoreDir)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	res := &NodeStatusRes{
+		Chain:       gen.Chain,
+		GenesisHash: gen.Hash().String(),
+		Peers:       uint64(len(s.peerDisc.Peers())),
+		Sync:        s.status.SyncStatus(),
+		Pending:     uint64(s.status.PendingCount()),
+		Uptime:      uint64(s.status.Uptime().Seconds()),
+		Version:     s.status.Version(),
+		Authority:   string(gen.Authority),
+		IsAuthority: s.status.IsAuthority(),
+	}
+	head := s.status.LastBlock()
+	if head.Number > 0 {
+		res.Height = head.Number
+		res.HeadHash = head.Hash().String()
+	} else {
+		res.HeadHash = res.GenesisHash
+	}
+	return res, nil
+}
+
+// StreamSubscribe replays the persisted events after the cursor or from the
+// block height when either is set, then streams the live events. Events
+// missed by a slow subscriber are caught up from the event store
 func (s *NodeSrv) StreamSubscribe(
 	req *StreamSubscribeReq, stream grpc.ServerStreamingServer[StreamSubscribeRes],
 ) error {
+	if req.MaxHeight > 0 && req.Height > req.MaxHeight {
+		return status.Errorf(
+			codes.InvalidArgument, "height %d is above max height %d",
+			req.Height, req.MaxHeight,
+		)
+	}
 	sub := fmt.Sprint(rand.Intn(999999))
-	chStream := s.evStreamer.AddSubscriber(sub)
+	chStream, seq := s.evStreamer.AddSubscriber(sub)
 
 	defer s.evStreamer.RemoveSubscriber(sub)
 
+	filter := NewEventFilter(req)
+	send := func(event chain.Event) error {
+		if !filter.Match(event) {
+			return nil
+		}
+		jev, err := json.Marshal(event)
+		if err != nil {
+			s.log.Error("Event encode", "err", err)
+			return nil
+		}
+		res := &StreamSubscribeRes{Event: jev}
+		return stream.Send(res)
+	}
+
+	last := seq
+	if req.Cursor > 0 || req.Height > 0 {
+		last = min(req.Cursor, seq)
+		err := s.replayEvents(last, seq, send)
+		if err != nil {
+			return err
+		}
+		last = seq
+	}
+
 	for {
 		select {
 		case <-stream.Context().Done():
@@ -64,24 +156,124 @@ func (s *NodeSrv) StreamSubscribe(
 			if !open {
 				return nil
 			}
-			if slices.Contains(req.EventTypes, uint64(0)) ||
-				slices.Contains(req.EventTypes, uint64(event.Type)) {
-				jev, err := json.Marshal(event)
+			if event.Seq <= last {
+				continue
+			}
+			if event.Seq > last+1 {
+				err := s.replayEvents(last, event.Seq-1, send)
 				if err != nil {
-					fmt.Println(err)
-					continue
+					return err
 				}
+			}
+			last = event.Seq
+			err := send(event)
+			if err != nil {
+				return status.Error(codes.Internal, err.Error())
+			}
+		}
+	}
+}
 
-				res := &StreamSubscribeRes{Event: jev}
+// NewEventFilter converts the subscription filters of the request
+func NewEventFilter(req *StreamSubscribeReq) chain.EventFilter {
+	evTypes := make([]chain.EventType, len(req.EventTypes))
+	for i, evType := range req.EventTypes {
+		evTypes[i] = chain.EventType(evType)
+	}
+	return chain.EventFilter{
+		Types: evTypes, Actions: req.Actions, Account: req.Account,
+		MinValue: req.MinValue, MinTxs: req.MinTxs,
+		MinHeight: req.Height, MaxHeight: req.MaxHeight,
+	}
+}
 
-				err = stream.Send(res)
+// replayEvents sends the persisted events after the from sequence number up
+// to and including the to sequence number
+func (s *NodeSrv) replayEvents(
+	from, to uint64, send func(event chain.Event) error,
+) error {
+	if from >= to {
+		return nil
+	}
+	events, closeEvents, err := chain.ReadEvents(s.blockStoreDir, from)
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	defer closeEvents()
+	for err, event := range events {
+		if err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+		err = send(event)
+		if err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+		// Stop before the events being appended to the event store
+		if event.Seq >= to {
+			break
+		}
+	}
+	return nil
+}
 
-				if err != nil {
-					return status.Errorf(codes.Internal, err.Error())
-				}
-			}
+func (s *NodeSrv) WebhookCreate(
+	_ context.Context, req *WebhookCreateReq,
+) (*WebhookCreateRes, error) {
+	hookURL, err := url.Parse(req.URL)
+	if err != nil || hookURL.Scheme != "http" && hookURL.Scheme != "https" ||
+		len(hookURL.Host) == 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid webhook URL %v", req.URL)
+	}
+	filter := req.Filter
+	if filter == nil {
+		filter = &StreamSubscribeReq{}
+	}
+	if filter.MaxHeight > 0 && filter.Height > filter.MaxHeight {
+		return nil, status.Errorf(
+			codes.InvalidArgument, "height %d is above max height %d",
+			filter.Height, filter.MaxHeight,
+		)
+	}
+	hook, err := s.webhooks.AddWebhook(
+		req.URL, req.Secret, NewEventFilter(filter), filter.Cursor,
+	)
+	if errors.Is(err, chain.ErrWebhookTarget) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	jhook, err := json.Marshal(hook)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	res := &WebhookCreateRes{Webhook: jhook}
+	return res, nil
+}
 
+func (s *NodeSrv) WebhookList(
+	_ *WebhookListReq, stream grpc.ServerStreamingServer[WebhookListRes],
+) error {
+	for _, hook := range s.webhooks.Webhooks() {
+		jhook, err := json.Marshal(hook)
+		if err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+		res := &WebhookListRes{Webhook: jhook}
+		err = stream.Send(res)
+		if err != nil {
+			return status.Error(codes.Internal, err.Error())
 		}
 	}
+	return nil
+}
 
+func (s *NodeSrv) WebhookDelete(
+	_ context.Context, req *WebhookDeleteReq,
+) (*WebhookDeleteRes, error) {
+	err := s.webhooks.RemoveWebhook(req.ID)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	return &WebhookDeleteRes{}, nil
 }
@@ -21,6 +21,10 @@ const _ = grpc.SupportPackageIsVersion9
 const (
 	Node_PeerDiscover_FullMethodName    = "/Node/PeerDiscover"
 	Node_StreamSubscribe_FullMethodName = "/Node/StreamSubscribe"
+	Node_WebhookCreate_FullMethodName   = "/Node/WebhookCreate"
+	Node_WebhookList_FullMethodName     = "/Node/WebhookList"
+	Node_WebhookDelete_FullMethodName   = "/Node/WebhookDelete"
+	Node_NodeStatus_FullMethodName      = "/Node/NodeStatus"
 )
 
 // NodeClient is the client API for Node service.
@@ -29,6 +33,10 @@ const (
 type NodeClient interface {
 	PeerDiscover(ctx context.Context, in *PeerDiscoverReq, opts ...grpc.CallOption) (*PeerDiscoverRes, error)
 	StreamSubscribe(ctx context.Context, in *StreamSubscribeReq, opts ...grpc.CallOption) (grpc.ServerStreamingClient[StreamSubscribeRes], error)
+	WebhookCreate(ctx context.Context, in *WebhookCreateReq, opts ...grpc.CallOption) (*WebhookCreateRes, error)
+	WebhookList(ctx context.Context, in *WebhookListReq, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WebhookListRes], error)
+	WebhookDelete(ctx context.Context, in *WebhookDeleteReq, opts ...grpc.CallOption) (*WebhookDeleteRes, error)
+	NodeStatus(ctx context.Context, in *NodeStatusReq, opts ...grpc.CallOption) (*NodeStatusRes, error)
 }
 
 type nodeClient struct {
@@ -68,12 +76,65 @@ func (c *nodeClient) StreamSubscribe(ctx context.Context, in *StreamSubscribeReq
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type Node_StreamSubscribeClient = grpc.ServerStreamingClient[StreamSubscribeRes]
 
+func (c *nodeClient) WebhookCreate(ctx context.Context, in *WebhookCreateReq, opts ...grpc.CallOption) (*WebhookCreateRes, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(WebhookCreateRes)
+	err := c.cc.Invoke(ctx, Node_WebhookCreate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeClient) WebhookList(ctx context.Context, in *WebhookListReq, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WebhookListRes], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Node_ServiceDesc.Streams[1], Node_WebhookList_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WebhookListReq, WebhookListRes]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Node_WebhookListClient = grpc.ServerStreamingClient[WebhookListRes]
+
+func (c *nodeClient) WebhookDelete(ctx context.Context, in *WebhookDeleteReq, opts ...grpc.CallOption) (*WebhookDeleteRes, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(WebhookDeleteRes)
+	err := c.cc.Invoke(ctx, Node_WebhookDelete_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeClient) NodeStatus(ctx context.Context, in *NodeStatusReq, opts ...grpc.CallOption) (*NodeStatusRes, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(NodeStatusRes)
+	err := c.cc.Invoke(ctx, Node_NodeStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // NodeServer is the server API for Node service.
 // All implementations must embed UnimplementedNodeServer
 // for forward compatibility.
 type NodeServer interface {
 	PeerDiscover(context.Context, *PeerDiscoverReq) (*PeerDiscoverRes, error)
 	StreamSubscribe(*StreamSubscribeReq, grpc.ServerStreamingServer[StreamSubscribeRes]) error
+	WebhookCreate(context.Context, *WebhookCreateReq) (*WebhookCreateRes, error)
+	WebhookList(*WebhookListReq, grpc.ServerStreamingServer[WebhookListRes]) error
+	WebhookDelete(context.Context, *WebhookDeleteReq) (*WebhookDeleteRes, error)
+	NodeStatus(context.Context, *NodeStatusReq) (*NodeStatusRes, error)
 	mustEmbedUnimplementedNodeServer()
 }
 
@@ -90,6 +151,18 @@ func (UnimplementedNodeServer) PeerDiscover(context.Context, *PeerDiscoverReq) (
 func (UnimplementedNodeServer) StreamSubscribe(*StreamSubscribeReq, grpc.ServerStreamingServer[StreamSubscribeRes]) error {
 	return status.Errorf(codes.Unimplemented, "method StreamSubscribe not implemented")
 }
+func (UnimplementedNodeServer) WebhookCreate(context.Context, *WebhookCreateReq) (*WebhookCreateRes, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method WebhookCreate not implemented")
+}
+func (UnimplementedNodeServer) WebhookList(*WebhookListReq, grpc.ServerStreamingServer[WebhookListRes]) error {
+	return status.Errorf(codes.Unimplemented, "method WebhookList not implemented")
+}
+func (UnimplementedNodeServer) WebhookDelete(context.Context, *WebhookDeleteReq) (*WebhookDeleteRes, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method WebhookDelete not implemented")
+}
+func (UnimplementedNodeServer) NodeStatus(context.Context, *NodeStatusReq) (*NodeStatusRes, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method NodeStatus not implemented")
+}
 func (UnimplementedNodeServer) mustEmbedUnimplementedNodeServer() {}
 func (UnimplementedNodeServer) testEmbeddedByValue()              {}
 
@@ -140,6 +213,71 @@ func _Node_StreamSubscribe_Handler(srv interface{}, stream grpc.ServerStream) er
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type Node_StreamSubscribeServer = grpc.ServerStreamingServer[StreamSubscribeRes]
 
+func _Node_WebhookCreate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WebhookCreateReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServer).WebhookCreate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Node_WebhookCreate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServer).WebhookCreate(ctx, req.(*WebhookCreateReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Node_WebhookList_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WebhookListReq)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(NodeServer).WebhookList(m, &grpc.GenericServerStream[WebhookListReq, WebhookListRes]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Node_WebhookListServer = grpc.ServerStreamingServer[WebhookListRes]
+
+func _Node_WebhookDelete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WebhookDeleteReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServer).WebhookDelete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Node_WebhookDelete_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServer).WebhookDelete(ctx, req.(*WebhookDeleteReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Node_NodeStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NodeStatusReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServer).NodeStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Node_NodeStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServer).NodeStatus(ctx, req.(*NodeStatusReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Node_ServiceDesc is the grpc.ServiceDesc for Node service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -151,6 +289,18 @@ var Node_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "PeerDiscover",
 			Handler:    _Node_PeerDiscover_Handler,
 		},
+		{
+			MethodName: "WebhookCreate",
+			Handler:    _Node_WebhookCreate_Handler,
+		},
+		{
+			MethodName: "WebhookDelete",
+			Handler:    _Node_WebhookDelete_Handler,
+		},
+		{
+			MethodName: "NodeStatus",
+			Handler:    _Node_NodeStatus_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -158,6 +308,11 @@ var Node_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _Node_StreamSubscribe_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "WebhookList",
+			Handler:       _Node_WebhookList_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "node.proto",
 }
@@ -0,0 +1,198 @@
+package rpc
+
+import (
+	"maps"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// newOpenAPI generates the OpenAPI document of the gateway routes from the
+// descriptors of the gRPC services
+func newOpenAPI(routes []route) (map[string]any, error) {
+	schemas := map[string]any{
+		"Error": map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"code":    map[string]any{"type": "string", "description": "gRPC status code"},
+				"message": map[string]any{"type": "string"},
+			},
+		},
+	}
+	paths := make(map[string]any)
+	for _, rt := range routes {
+		md, err := methodDesc(rt.rpc)
+		if err != nil {
+			return nil, err
+		}
+		path, exist := paths[rt.pattern].(map[string]any)
+		if !exist {
+			path = make(map[string]any)
+			paths[rt.pattern] = path
+		}
+		path[strings.ToLower(rt.method)] = newOperation(rt, md, schemas)
+	}
+	maps.Copy(paths, eventPaths(schemas))
+	doc := map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":   "RuChain node API",
+			"version": "v1",
+		},
+		"paths":      paths,
+		"components": map[string]any{"schemas": schemas},
+	}
+	return doc, nil
+}
+
+func newOperation(
+	rt route, md protoreflect.MethodDescriptor, schemas map[string]any,
+) map[string]any {
+	id := rt.id
+	if len(id) == 0 {
+		id = string(md.Name())
+	}
+	op := map[string]any{
+		"operationId": id,
+		"tags":        []string{string(md.Parent().Name())},
+		"summary":     grpcMethod(md),
+	}
+	params := make([]any, 0)
+	inPath := make(map[string]bool)
+	for _, match := range rePathParam.FindAllStringSubmatch(rt.pattern, -1) {
+		fd := fieldByName(md.Input(), match[1])
+		inPath[string(fd.Name())] = true
+		params = append(params, map[string]any{
+			"name": match[1], "in": "path", "required": true,
+			"schema": fieldSchema(fd, schemas),
+		})
+	}
+	if rt.query {
+		fields := md.Input().Fields()
+		for i := range fields.Len() {
+			fd := fields.Get(i)
+			if inPath[string(fd.Name())] || fd.Name() == "Limit" || fd.Name() == "Offset" {
+				continue
+			}
+			params = append(params, map[string]any{
+				"name": jsonName(fd), "in": "query", "schema": fieldSchema(fd, schemas),
+			})
+		}
+	}
+	list := md.IsStreamingServer() && !rt.single
+	if list {
+		params = append(params,
+			map[string]any{
+				"name": "limit", "in": "query",
+				"schema": map[string]any{
+					"type": "integer", "minimum": 1, "maximum": gatewayMaxPageLimit,
+					"default": gatewayPageLimit,
+				},
+			},
+			map[string]any{
+				"name": "offset", "in": "query",
+				"schema": map[string]any{"type": "integer", "minimum": 0, "default": 0},
+			},
+		)
+	}
+	if len(params) > 0 {
+		op["parameters"] = params
+	}
+	if rt.method == http.MethodPost {
+		op["requestBody"] = map[string]any{
+			"content": jsonContent(msgSchema(md.Input(), schemas)),
+		}
+	}
+	res := msgSchema(md.Output(), schemas)
+	if list {
+		props := map[string]any{
+			"items": map[string]any{"type": "array", "items": res},
+			"next": map[string]any{
+				"type": "integer", "description": "offset of the next page",
+			},
+		}
+		if md.Output().Fields().ByName("Total") != nil {
+			props["total"] = map[string]any{
+				"type": "integer", "description": "number of all matches",
+			}
+			props["cursor"] = map[string]any{
+				"type": "string", "description": "cursor of the last item",
+			}
+		}
+		res = map[string]any{"type": "object", "properties": props}
+	}
+	code := rt.status
+	if code == 0 {
+		code = http.StatusOK
+	}
+	op["responses"] = map[string]any{
+		strconv.Itoa(code): map[string]any{
+			"description": http.StatusText(code), "content": jsonContent(res),
+		},
+		"default": map[string]any{
+			"description": "Error",
+			"content":     jsonContent(schemaRef("Error")),
+		},
+	}
+	return op
+}
+
+func jsonContent(schema map[string]any) map[string]any {
+	return map[string]any{"application/json": map[string]any{"schema": schema}}
+}
+
+func schemaRef(name string) map[string]any {
+	return map[string]any{"$ref": "#/components/schemas/" + name}
+}
+
+// msgSchema returns a reference to the message schema, adding the schema of
+// the message and its nested messages to the components
+func msgSchema(
+	desc protoreflect.MessageDescriptor, schemas map[string]any,
+) map[string]any {
+	if fd := resourceField(desc); fd != nil {
+		return fieldSchema(fd, schemas)
+	}
+	name := string(desc.Name())
+	if _, exist := schemas[name]; !exist {
+		props := make(map[string]any)
+		schema := map[string]any{"type": "object", "properties": props}
+		schemas[name] = schema
+		fields := desc.Fields()
+		for i := range fields.Len() {
+			fd := fields.Get(i)
+			props[jsonName(fd)] = fieldSchema(fd, schemas)
+		}
+	}
+	return schemaRef(name)
+}
+
+func fieldSchema(
+	fd protoreflect.FieldDescriptor, schemas map[string]any,
+) map[string]any {
+	var schema map[string]any
+	switch fd.Kind() {
+	case protoreflect.MessageKind:
+		schema = msgSchema(fd.Message(), schemas)
+	case protoreflect.StringKind:
+		schema = map[string]any{"type": "string"}
+	case protoreflect.Uint64Kind:
+		schema = map[string]any{"type": "integer", "format": "uint64"}
+	case protoreflect.BoolKind:
+		schema = map[string]any{"type": "boolean"}
+	case protoreflect.BytesKind:
+		if res, exist := jsonFields[fd.FullName()]; exist {
+			schema = map[string]any{"description": "JSON encoded " + res}
+		} else {
+			schema = map[string]any{"type": "string", "format": "byte"}
+		}
+	default:
+		schema = map[string]any{}
+	}
+	if fd.IsList() {
+		return map[string]any{"type": "array", "items": schema}
+	}
+	return schema
+}
@@ -0,0 +1,125 @@
+package rpc
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Ansh1902396/chain"
+)
+
+const (
+	searchLimit    = 100
+	searchMaxLimit = 1000
+)
+
+// searchPos is the position of a search result in the chain: the block
+// number, then the transaction index and the batch entry of transactions
+type searchPos []uint64
+
+func (p searchPos) String() string {
+	strs := make([]string, len(p))
+	for i, n := range p {
+		strs[i] = strconv.FormatUint(n, 10)
+	}
+	return strings.Join(strs, ".")
+}
+
+func parseSearchPos(str string) (searchPos, error) {
+	pos := make(searchPos, 0, 3)
+	for part := range strings.SplitSeq(str, ".") {
+		n, err := strconv.ParseUint(part, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cursor %v", str)
+		}
+		pos = append(pos, n)
+	}
+	return pos, nil
+}
+
+type searchItem[T any] struct {
+	pos  searchPos
+	item T
+}
+
+// searchPage selects a page of the matches added in the chain order. The
+// cursor excludes the matches up to and including the cursor in the
+// requested order, then the offset and the limit apply. The total counts the
+// matches after the cursor
+type searchPage[T any] struct {
+	limit, offset uint64
+	cursor        searchPos
+	desc          bool
+	items         []searchItem[T]
+	total         uint64
+}
+
+func newSearchPage[T any](
+	limit, offset uint64, cursor string, desc bool,
+) (*searchPage[T], error) {
+	if limit == 0 {
+		limit = searchLimit
+	}
+	if limit > searchMaxLimit {
+		return nil, fmt.Errorf("limit: expected 1 to %d, got %d", searchMaxLimit, limit)
+	}
+	page := &searchPage[T]{limit: limit, offset: offset, desc: desc}
+	if len(cursor) > 0 {
+		pos, err := parseSearchPos(cursor)
+		if err != nil {
+			return nil, err
+		}
+		page.cursor = pos
+	}
+	return page, nil
+}
+
+func (p *searchPage[T]) add(pos searchPos, item T) {
+	if p.cursor != nil {
+		c := slices.Compare(pos, p.cursor)
+		if !p.desc && c <= 0 || p.desc && c >= 0 {
+			return
+		}
+	}
+	k := p.total
+	p.total++
+	if !p.desc {
+		if k >= p.offset && k < p.offset+p.limit {
+			p.items = append(p.items, searchItem[T]{pos: pos, item: item})
+		}
+		return
+	}
+	// Keep the last offset + limit matches, the page is taken in reverse
+	p.items = append(p.items, searchItem[T]{pos: pos, item: item})
+	if uint64(len(p.items)) > p.offset+p.limit {
+		p.items = p.items[1:]
+	}
+}
+
+// page returns the selected matches in the requested order
+func (p *searchPage[T]) page() []searchItem[T] {
+	if !p.desc {
+		return p.items
+	}
+	items := slices.Clone(p.items)
+	slices.Reverse(items)
+	if uint64(len(items)) <= p.offset {
+		return nil
+	}
+	items = items[p.offset:]
+	return items[:min(uint64(len(items)), p.limit)]
+}
+
+// blockInRange checks the block against the inclusive block number range and
+// the time range that excludes the until time
+func blockInRange(blk chain.SigBlock, from, to, since, until uint64) bool {
+	return inRange(blk.Number, blk.Time, from, to, since, until)
+}
+
+func inRange(number uint64, tm time.Time, from, to, since, until uint64) bool {
+	unix := uint64(tm.Unix())
+	return number >= from && (to == 0 || number <= to) &&
+		unix >= since && (until == 0 || unix < until)
+}
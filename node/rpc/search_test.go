@@ -0,0 +1,91 @@
+package rpc
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSearchPos(t *testing.T) {
+	cases := []struct {
+		str   string
+		pos   searchPos
+		valid bool
+	}{
+		{"7", searchPos{7}, true},
+		{"7.2.1", searchPos{7, 2, 1}, true},
+		{"", nil, false},
+		{"7.", nil, false},
+		{"7.a", nil, false},
+		{"-1", nil, false},
+	}
+	for _, c := range cases {
+		t.Run(c.str, func(t *testing.T) {
+			pos, err := parseSearchPos(c.str)
+			if c.valid != (err == nil) {
+				t.Fatalf("expected valid %v, got %v", c.valid, err)
+			}
+			if !slices.Equal(pos, c.pos) {
+				t.Fatalf("expected %v, got %v", c.pos, pos)
+			}
+			if c.valid && pos.String() != c.str {
+				t.Errorf("expected %v, got %v", c.str, pos)
+			}
+		})
+	}
+}
+
+func TestSearchPage(t *testing.T) {
+	cases := []struct {
+		name          string
+		limit, offset uint64
+		cursor        string
+		desc          bool
+		page          []uint64
+		total         uint64
+	}{
+		{"default limit", 0, 0, "", false, []uint64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, 10},
+		{"first page", 3, 0, "", false, []uint64{1, 2, 3}, 10},
+		{"offset", 3, 3, "", false, []uint64{4, 5, 6}, 10},
+		{"last page", 3, 9, "", false, []uint64{10}, 10},
+		{"after the last page", 3, 10, "", false, nil, 10},
+		{"desc", 3, 0, "", true, []uint64{10, 9, 8}, 10},
+		{"desc offset", 3, 8, "", true, []uint64{2, 1}, 10},
+		{"cursor", 3, 0, "4", false, []uint64{5, 6, 7}, 6},
+		{"cursor offset", 3, 3, "4", false, []uint64{8, 9, 10}, 6},
+		{"cursor of the last", 3, 0, "10", false, nil, 0},
+		{"desc cursor", 3, 0, "4", true, []uint64{3, 2, 1}, 3},
+		{"cursor of a batch entry", 3, 0, "4.0.1", false, []uint64{5, 6, 7}, 6},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			page, err := newSearchPage[uint64](c.limit, c.offset, c.cursor, c.desc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for n := uint64(1); n <= 10; n++ {
+				page.add(searchPos{n}, n)
+			}
+			var items []uint64
+			for _, match := range page.page() {
+				items = append(items, match.item)
+			}
+			if !slices.Equal(items, c.page) {
+				t.Errorf("expected page %v, got %v", c.page, items)
+			}
+			if page.total != c.total {
+				t.Errorf("expected total %d, got %d", c.total, page.total)
+			}
+		})
+	}
+}
+
+func TestSearchPageInvalid(t *testing.T) {
+	_, err := newSearchPage[uint64](searchMaxLimit+1, 0, "", false)
+	if err == nil {
+		t.Error("expected a limit error")
+	}
+	_, err = newSearchPage[uint64](1, 0, "x", false)
+	if err == nil {
+		t.Error("expected a cursor error")
+	}
+}
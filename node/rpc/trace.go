@@ -0,0 +1,62 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/Ansh1902396/chain"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/Ansh1902396/node/rpc")
+
+// TraceStore keeps the trace context of the transactions and the blocks
+// seen by the node, so their later spans on the node join the same trace
+type TraceStore interface {
+	SaveTrace(ctx context.Context, hash chain.Hash)
+	TraceContext(ctx context.Context, hash chain.Hash) (context.Context, bool)
+}
+
+// InjectTrace encodes the trace context of a relayed message
+func InjectTrace(ctx context.Context) map[string]string {
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	return carrier
+}
+
+// ExtractTrace decodes the trace context of a received message
+func ExtractTrace(ctx context.Context, carrier map[string]string) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(carrier))
+}
+
+// traceTx applies the transaction to the pending pool in a child span
+func traceTx(ctx context.Context, txApplier TxApplier, tx chain.SigTx) error {
+	_, span := tracer.Start(ctx, "pending.apply")
+	defer span.End()
+	err := txApplier.ApplyTx(tx)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
+}
+
+// TxSpanAttrs identifies the transaction of a span
+func TxSpanAttrs(tx chain.SigTx) trace.SpanStartOption {
+	return trace.WithAttributes(
+		attribute.String("tx.hash", tx.Hash().String()),
+		attribute.String("tx.from", string(tx.From)),
+		attribute.Int64("tx.nonce", int64(tx.Nonce)),
+	)
+}
+
+// BlockSpanAttrs identifies the block of a span
+func BlockSpanAttrs(blk chain.SigBlock) trace.SpanStartOption {
+	return trace.WithAttributes(
+		attribute.Int64("block.number", int64(blk.Number)),
+		attribute.String("block.hash", blk.Hash().String()),
+		attribute.Int("block.txs", len(blk.Txs)),
+	)
+}
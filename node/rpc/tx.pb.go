@@ -27,6 +27,15 @@ type TxSearchReq struct {
 	From          string                 `protobuf:"bytes,2,opt,name=From,proto3" json:"From,omitempty"`
 	To            string                 `protobuf:"bytes,3,opt,name=To,proto3" json:"To,omitempty"`
 	Account       string                 `protobuf:"bytes,4,opt,name=Account,proto3" json:"Account,omitempty"`
+	Memo          string                 `protobuf:"bytes,5,opt,name=Memo,proto3" json:"Memo,omitempty"`
+	FromNumber    uint64                 `protobuf:"varint,6,opt,name=FromNumber,proto3" json:"FromNumber,omitempty"`
+	ToNumber      uint64                 `protobuf:"varint,7,opt,name=ToNumber,proto3" json:"ToNumber,omitempty"`
+	Since         uint64                 `protobuf:"varint,8,opt,name=Since,proto3" json:"Since,omitempty"`
+	Until         uint64                 `protobuf:"varint,9,opt,name=Until,proto3" json:"Until,omitempty"`
+	Limit         uint64                 `protobuf:"varint,10,opt,name=Limit,proto3" json:"Limit,omitempty"`
+	Offset        uint64                 `protobuf:"varint,11,opt,name=Offset,proto3" json:"Offset,omitempty"`
+	Cursor        string                 `protobuf:"bytes,12,opt,name=Cursor,proto3" json:"Cursor,omitempty"`
+	Desc          bool                   `protobuf:"varint,13,opt,name=Desc,proto3" json:"Desc,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -89,9 +98,74 @@ func (x *TxSearchReq) GetAccount() string {
 	return ""
 }
 
+func (x *TxSearchReq) GetMemo() string {
+	if x != nil {
+		return x.Memo
+	}
+	return ""
+}
+
+func (x *TxSearchReq) GetFromNumber() uint64 {
+	if x != nil {
+		return x.FromNumber
+	}
+	return 0
+}
+
+func (x *TxSearchReq) GetToNumber() uint64 {
+	if x != nil {
+		return x.ToNumber
+	}
+	return 0
+}
+
+func (x *TxSearchReq) GetSince() uint64 {
+	if x != nil {
+		return x.Since
+	}
+	return 0
+}
+
+func (x *TxSearchReq) GetUntil() uint64 {
+	if x != nil {
+		return x.Until
+	}
+	return 0
+}
+
+func (x *TxSearchReq) GetLimit() uint64 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *TxSearchReq) GetOffset() uint64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *TxSearchReq) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+func (x *TxSearchReq) GetDesc() bool {
+	if x != nil {
+		return x.Desc
+	}
+	return false
+}
+
 type TxSearchRes struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Tx            []byte                 `protobuf:"bytes,1,opt,name=Tx,proto3" json:"Tx,omitempty"`
+	Total         uint64                 `protobuf:"varint,2,opt,name=Total,proto3" json:"Total,omitempty"`
+	Cursor        string                 `protobuf:"bytes,3,opt,name=Cursor,proto3" json:"Cursor,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -133,6 +207,20 @@ func (x *TxSearchRes) GetTx() []byte {
 	return nil
 }
 
+func (x *TxSearchRes) GetTotal() uint64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *TxSearchRes) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
 type TxProveReq struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Hash          string                 `protobuf:"bytes,1,opt,name=Hash,proto3" json:"Hash,omitempty"`
@@ -413,19 +501,152 @@ func (x *TxVerifyRes) GetValid() bool {
 	return false
 }
 
+type Transfer struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	To            string                 `protobuf:"bytes,1,opt,name=To,proto3" json:"To,omitempty"`
+	Value         uint64                 `protobuf:"varint,2,opt,name=Value,proto3" json:"Value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Transfer) Reset() {
+	*x = Transfer{}
+	mi := &file_tx_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Transfer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Transfer) ProtoMessage() {}
+
+func (x *Transfer) ProtoReflect() protoreflect.Message {
+	mi := &file_tx_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Transfer.ProtoReflect.Descriptor instead.
+func (*Transfer) Descriptor() ([]byte, []int) {
+	return file_tx_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Transfer) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *Transfer) GetValue() uint64 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+type HTLC struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Op            string                 `protobuf:"bytes,1,opt,name=Op,proto3" json:"Op,omitempty"`
+	HashLock      string                 `protobuf:"bytes,2,opt,name=HashLock,proto3" json:"HashLock,omitempty"`
+	TimeLock      uint64                 `protobuf:"varint,3,opt,name=TimeLock,proto3" json:"TimeLock,omitempty"`
+	Lock          string                 `protobuf:"bytes,4,opt,name=Lock,proto3" json:"Lock,omitempty"`
+	Preimage      []byte                 `protobuf:"bytes,5,opt,name=Preimage,proto3" json:"Preimage,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HTLC) Reset() {
+	*x = HTLC{}
+	mi := &file_tx_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HTLC) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HTLC) ProtoMessage() {}
+
+func (x *HTLC) ProtoReflect() protoreflect.Message {
+	mi := &file_tx_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HTLC.ProtoReflect.Descriptor instead.
+func (*HTLC) Descriptor() ([]byte, []int) {
+	return file_tx_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *HTLC) GetOp() string {
+	if x != nil {
+		return x.Op
+	}
+	return ""
+}
+
+func (x *HTLC) GetHashLock() string {
+	if x != nil {
+		return x.HashLock
+	}
+	return ""
+}
+
+func (x *HTLC) GetTimeLock() uint64 {
+	if x != nil {
+		return x.TimeLock
+	}
+	return 0
+}
+
+func (x *HTLC) GetLock() string {
+	if x != nil {
+		return x.Lock
+	}
+	return ""
+}
+
+func (x *HTLC) GetPreimage() []byte {
+	if x != nil {
+		return x.Preimage
+	}
+	return nil
+}
+
 type TxSignReq struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	From          string                 `protobuf:"bytes,1,opt,name=From,proto3" json:"From,omitempty"`
 	To            string                 `protobuf:"bytes,2,opt,name=To,proto3" json:"To,omitempty"`
 	Value         uint64                 `protobuf:"varint,3,opt,name=Value,proto3" json:"Value,omitempty"`
 	Password      string                 `protobuf:"bytes,4,opt,name=Password,proto3" json:"Password,omitempty"`
+	Data          []byte                 `protobuf:"bytes,5,opt,name=Data,proto3" json:"Data,omitempty"`
+	NotBefore     uint64                 `protobuf:"varint,6,opt,name=NotBefore,proto3" json:"NotBefore,omitempty"`
+	ValidUntil    uint64                 `protobuf:"varint,7,opt,name=ValidUntil,proto3" json:"ValidUntil,omitempty"`
+	Batch         []*Transfer            `protobuf:"bytes,8,rep,name=Batch,proto3" json:"Batch,omitempty"`
+	HTLC          *HTLC                  `protobuf:"bytes,9,opt,name=HTLC,proto3" json:"HTLC,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *TxSignReq) Reset() {
 	*x = TxSignReq{}
-	mi := &file_tx_proto_msgTypes[8]
+	mi := &file_tx_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -437,7 +658,7 @@ func (x *TxSignReq) String() string {
 func (*TxSignReq) ProtoMessage() {}
 
 func (x *TxSignReq) ProtoReflect() protoreflect.Message {
-	mi := &file_tx_proto_msgTypes[8]
+	mi := &file_tx_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -450,7 +671,7 @@ func (x *TxSignReq) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TxSignReq.ProtoReflect.Descriptor instead.
 func (*TxSignReq) Descriptor() ([]byte, []int) {
-	return file_tx_proto_rawDescGZIP(), []int{8}
+	return file_tx_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *TxSignReq) GetFrom() string {
@@ -481,6 +702,41 @@ func (x *TxSignReq) GetPassword() string {
 	return ""
 }
 
+func (x *TxSignReq) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *TxSignReq) GetNotBefore() uint64 {
+	if x != nil {
+		return x.NotBefore
+	}
+	return 0
+}
+
+func (x *TxSignReq) GetValidUntil() uint64 {
+	if x != nil {
+		return x.ValidUntil
+	}
+	return 0
+}
+
+func (x *TxSignReq) GetBatch() []*Transfer {
+	if x != nil {
+		return x.Batch
+	}
+	return nil
+}
+
+func (x *TxSignReq) GetHTLC() *HTLC {
+	if x != nil {
+		return x.HTLC
+	}
+	return nil
+}
+
 type TxSignRes struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Tx            []byte                 `protobuf:"bytes,1,opt,name=Tx,proto3" json:"Tx,omitempty"`
@@ -490,7 +746,7 @@ type TxSignRes struct {
 
 func (x *TxSignRes) Reset() {
 	*x = TxSignRes{}
-	mi := &file_tx_proto_msgTypes[9]
+	mi := &file_tx_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -502,7 +758,7 @@ func (x *TxSignRes) String() string {
 func (*TxSignRes) ProtoMessage() {}
 
 func (x *TxSignRes) ProtoReflect() protoreflect.Message {
-	mi := &file_tx_proto_msgTypes[9]
+	mi := &file_tx_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -515,7 +771,7 @@ func (x *TxSignRes) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TxSignRes.ProtoReflect.Descriptor instead.
 func (*TxSignRes) Descriptor() ([]byte, []int) {
-	return file_tx_proto_rawDescGZIP(), []int{9}
+	return file_tx_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *TxSignRes) GetTx() []byte {
@@ -528,13 +784,14 @@ func (x *TxSignRes) GetTx() []byte {
 type TxReceiveReq struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Tx            []byte                 `protobuf:"bytes,1,opt,name=Tx,proto3" json:"Tx,omitempty"`
+	Trace         map[string]string      `protobuf:"bytes,2,rep,name=Trace,proto3" json:"Trace,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *TxReceiveReq) Reset() {
 	*x = TxReceiveReq{}
-	mi := &file_tx_proto_msgTypes[10]
+	mi := &file_tx_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -546,7 +803,7 @@ func (x *TxReceiveReq) String() string {
 func (*TxReceiveReq) ProtoMessage() {}
 
 func (x *TxReceiveReq) ProtoReflect() protoreflect.Message {
-	mi := &file_tx_proto_msgTypes[10]
+	mi := &file_tx_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -559,7 +816,7 @@ func (x *TxReceiveReq) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TxReceiveReq.ProtoReflect.Descriptor instead.
 func (*TxReceiveReq) Descriptor() ([]byte, []int) {
-	return file_tx_proto_rawDescGZIP(), []int{10}
+	return file_tx_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *TxReceiveReq) GetTx() []byte {
@@ -569,6 +826,13 @@ func (x *TxReceiveReq) GetTx() []byte {
 	return nil
 }
 
+func (x *TxReceiveReq) GetTrace() map[string]string {
+	if x != nil {
+		return x.Trace
+	}
+	return nil
+}
+
 type TxReceiveRes struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	unknownFields protoimpl.UnknownFields
@@ -577,7 +841,7 @@ type TxReceiveRes struct {
 
 func (x *TxReceiveRes) Reset() {
 	*x = TxReceiveRes{}
-	mi := &file_tx_proto_msgTypes[11]
+	mi := &file_tx_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -589,7 +853,7 @@ func (x *TxReceiveRes) String() string {
 func (*TxReceiveRes) ProtoMessage() {}
 
 func (x *TxReceiveRes) ProtoReflect() protoreflect.Message {
-	mi := &file_tx_proto_msgTypes[11]
+	mi := &file_tx_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -602,21 +866,299 @@ func (x *TxReceiveRes) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TxReceiveRes.ProtoReflect.Descriptor instead.
 func (*TxReceiveRes) Descriptor() ([]byte, []int) {
-	return file_tx_proto_rawDescGZIP(), []int{11}
+	return file_tx_proto_rawDescGZIP(), []int{13}
+}
+
+type TxStatusReq struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Hash          string                 `protobuf:"bytes,1,opt,name=Hash,proto3" json:"Hash,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TxStatusReq) Reset() {
+	*x = TxStatusReq{}
+	mi := &file_tx_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TxStatusReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TxStatusReq) ProtoMessage() {}
+
+func (x *TxStatusReq) ProtoReflect() protoreflect.Message {
+	mi := &file_tx_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TxStatusReq.ProtoReflect.Descriptor instead.
+func (*TxStatusReq) Descriptor() ([]byte, []int) {
+	return file_tx_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *TxStatusReq) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
+type TxStatusRes struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Receipt       []byte                 `protobuf:"bytes,1,opt,name=Receipt,proto3" json:"Receipt,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TxStatusRes) Reset() {
+	*x = TxStatusRes{}
+	mi := &file_tx_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TxStatusRes) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TxStatusRes) ProtoMessage() {}
+
+func (x *TxStatusRes) ProtoReflect() protoreflect.Message {
+	mi := &file_tx_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TxStatusRes.ProtoReflect.Descriptor instead.
+func (*TxStatusRes) Descriptor() ([]byte, []int) {
+	return file_tx_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *TxStatusRes) GetReceipt() []byte {
+	if x != nil {
+		return x.Receipt
+	}
+	return nil
+}
+
+type TxWaitReq struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Hash          string                 `protobuf:"bytes,1,opt,name=Hash,proto3" json:"Hash,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TxWaitReq) Reset() {
+	*x = TxWaitReq{}
+	mi := &file_tx_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TxWaitReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TxWaitReq) ProtoMessage() {}
+
+func (x *TxWaitReq) ProtoReflect() protoreflect.Message {
+	mi := &file_tx_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TxWaitReq.ProtoReflect.Descriptor instead.
+func (*TxWaitReq) Descriptor() ([]byte, []int) {
+	return file_tx_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *TxWaitReq) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
+type TxWaitRes struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Receipt       []byte                 `protobuf:"bytes,1,opt,name=Receipt,proto3" json:"Receipt,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TxWaitRes) Reset() {
+	*x = TxWaitRes{}
+	mi := &file_tx_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TxWaitRes) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TxWaitRes) ProtoMessage() {}
+
+func (x *TxWaitRes) ProtoReflect() protoreflect.Message {
+	mi := &file_tx_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TxWaitRes.ProtoReflect.Descriptor instead.
+func (*TxWaitRes) Descriptor() ([]byte, []int) {
+	return file_tx_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *TxWaitRes) GetReceipt() []byte {
+	if x != nil {
+		return x.Receipt
+	}
+	return nil
+}
+
+type TxLockReq struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Hash          string                 `protobuf:"bytes,1,opt,name=Hash,proto3" json:"Hash,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TxLockReq) Reset() {
+	*x = TxLockReq{}
+	mi := &file_tx_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TxLockReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TxLockReq) ProtoMessage() {}
+
+func (x *TxLockReq) ProtoReflect() protoreflect.Message {
+	mi := &file_tx_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TxLockReq.ProtoReflect.Descriptor instead.
+func (*TxLockReq) Descriptor() ([]byte, []int) {
+	return file_tx_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *TxLockReq) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
+type TxLockRes struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Lock          []byte                 `protobuf:"bytes,1,opt,name=Lock,proto3" json:"Lock,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TxLockRes) Reset() {
+	*x = TxLockRes{}
+	mi := &file_tx_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TxLockRes) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TxLockRes) ProtoMessage() {}
+
+func (x *TxLockRes) ProtoReflect() protoreflect.Message {
+	mi := &file_tx_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TxLockRes.ProtoReflect.Descriptor instead.
+func (*TxLockRes) Descriptor() ([]byte, []int) {
+	return file_tx_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *TxLockRes) GetLock() []byte {
+	if x != nil {
+		return x.Lock
+	}
+	return nil
 }
 
 var File_tx_proto protoreflect.FileDescriptor
 
 const file_tx_proto_rawDesc = "" +
 	"\n" +
-	"\btx.proto\"_\n" +
+	"\btx.proto\"\xb5\x02\n" +
 	"\vTxSearchReq\x12\x12\n" +
 	"\x04Hash\x18\x01 \x01(\tR\x04Hash\x12\x12\n" +
 	"\x04From\x18\x02 \x01(\tR\x04From\x12\x0e\n" +
 	"\x02To\x18\x03 \x01(\tR\x02To\x12\x18\n" +
-	"\aAccount\x18\x04 \x01(\tR\aAccount\"\x1d\n" +
+	"\aAccount\x18\x04 \x01(\tR\aAccount\x12\x12\n" +
+	"\x04Memo\x18\x05 \x01(\tR\x04Memo\x12\x1e\n" +
+	"\n" +
+	"FromNumber\x18\x06 \x01(\x04R\n" +
+	"FromNumber\x12\x1a\n" +
+	"\bToNumber\x18\a \x01(\x04R\bToNumber\x12\x14\n" +
+	"\x05Since\x18\b \x01(\x04R\x05Since\x12\x14\n" +
+	"\x05Until\x18\t \x01(\x04R\x05Until\x12\x14\n" +
+	"\x05Limit\x18\n" +
+	" \x01(\x04R\x05Limit\x12\x16\n" +
+	"\x06Offset\x18\v \x01(\x04R\x06Offset\x12\x16\n" +
+	"\x06Cursor\x18\f \x01(\tR\x06Cursor\x12\x12\n" +
+	"\x04Desc\x18\r \x01(\bR\x04Desc\"K\n" +
 	"\vTxSearchRes\x12\x0e\n" +
-	"\x02Tx\x18\x01 \x01(\fR\x02Tx\" \n" +
+	"\x02Tx\x18\x01 \x01(\fR\x02Tx\x12\x14\n" +
+	"\x05Total\x18\x02 \x01(\x04R\x05Total\x12\x16\n" +
+	"\x06Cursor\x18\x03 \x01(\tR\x06Cursor\" \n" +
 	"\n" +
 	"TxProveReq\x12\x12\n" +
 	"\x04Hash\x18\x01 \x01(\tR\x04Hash\".\n" +
@@ -634,17 +1176,50 @@ const file_tx_proto_rawDesc = "" +
 	"MerkleRoot\x18\x03 \x01(\tR\n" +
 	"MerkleRoot\"#\n" +
 	"\vTxVerifyRes\x12\x14\n" +
-	"\x05Valid\x18\x01 \x01(\bR\x05Valid\"a\n" +
+	"\x05Valid\x18\x01 \x01(\bR\x05Valid\"0\n" +
+	"\bTransfer\x12\x0e\n" +
+	"\x02To\x18\x01 \x01(\tR\x02To\x12\x14\n" +
+	"\x05Value\x18\x02 \x01(\x04R\x05Value\"~\n" +
+	"\x04HTLC\x12\x0e\n" +
+	"\x02Op\x18\x01 \x01(\tR\x02Op\x12\x1a\n" +
+	"\bHashLock\x18\x02 \x01(\tR\bHashLock\x12\x1a\n" +
+	"\bTimeLock\x18\x03 \x01(\x04R\bTimeLock\x12\x12\n" +
+	"\x04Lock\x18\x04 \x01(\tR\x04Lock\x12\x1a\n" +
+	"\bPreimage\x18\x05 \x01(\fR\bPreimage\"\xef\x01\n" +
 	"\tTxSignReq\x12\x12\n" +
 	"\x04From\x18\x01 \x01(\tR\x04From\x12\x0e\n" +
 	"\x02To\x18\x02 \x01(\tR\x02To\x12\x14\n" +
 	"\x05Value\x18\x03 \x01(\x04R\x05Value\x12\x1a\n" +
-	"\bPassword\x18\x04 \x01(\tR\bPassword\"\x1b\n" +
+	"\bPassword\x18\x04 \x01(\tR\bPassword\x12\x12\n" +
+	"\x04Data\x18\x05 \x01(\fR\x04Data\x12\x1c\n" +
+	"\tNotBefore\x18\x06 \x01(\x04R\tNotBefore\x12\x1e\n" +
+	"\n" +
+	"ValidUntil\x18\a \x01(\x04R\n" +
+	"ValidUntil\x12\x1f\n" +
+	"\x05Batch\x18\b \x03(\v2\t.TransferR\x05Batch\x12\x19\n" +
+	"\x04HTLC\x18\t \x01(\v2\x05.HTLCR\x04HTLC\"\x1b\n" +
 	"\tTxSignRes\x12\x0e\n" +
-	"\x02Tx\x18\x01 \x01(\fR\x02Tx\"\x1e\n" +
+	"\x02Tx\x18\x01 \x01(\fR\x02Tx\"\x88\x01\n" +
 	"\fTxReceiveReq\x12\x0e\n" +
-	"\x02Tx\x18\x01 \x01(\fR\x02Tx\"\x0e\n" +
-	"\fTxReceiveRes2\xec\x01\n" +
+	"\x02Tx\x18\x01 \x01(\fR\x02Tx\x12.\n" +
+	"\x05Trace\x18\x02 \x03(\v2\x18.TxReceiveReq.TraceEntryR\x05Trace\x1a8\n" +
+	"\n" +
+	"TraceEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x0e\n" +
+	"\fTxReceiveRes\"!\n" +
+	"\vTxStatusReq\x12\x12\n" +
+	"\x04Hash\x18\x01 \x01(\tR\x04Hash\"'\n" +
+	"\vTxStatusRes\x12\x18\n" +
+	"\aReceipt\x18\x01 \x01(\fR\aReceipt\"\x1f\n" +
+	"\tTxWaitReq\x12\x12\n" +
+	"\x04Hash\x18\x01 \x01(\tR\x04Hash\"%\n" +
+	"\tTxWaitRes\x12\x18\n" +
+	"\aReceipt\x18\x01 \x01(\fR\aReceipt\"\x1f\n" +
+	"\tTxLockReq\x12\x12\n" +
+	"\x04Hash\x18\x01 \x01(\tR\x04Hash\"\x1f\n" +
+	"\tTxLockRes\x12\x12\n" +
+	"\x04Lock\x18\x01 \x01(\fR\x04Lock2\xda\x02\n" +
 	"\x02Tx\x12(\n" +
 	"\bTxSearch\x12\f.TxSearchReq\x1a\f.TxSearchRes0\x01\x12 \n" +
 	"\x06TxSign\x12\n" +
@@ -655,7 +1230,14 @@ const file_tx_proto_rawDesc = "" +
 	".TxSendRes\x12#\n" +
 	"\aTxProve\x12\v.TxProveReq\x1a\v.TxProveRes\x12&\n" +
 	"\bTxVerify\x12\f.TxVerifyReq\x1a\f.TxVerifyRes\x12+\n" +
-	"\tTxReceive\x12\r.TxReceiveReq\x1a\r.TxReceiveRes(\x01B\aZ\x05./rpcb\x06proto3"
+	"\tTxReceive\x12\r.TxReceiveReq\x1a\r.TxReceiveRes(\x01\x12&\n" +
+	"\bTxStatus\x12\f.TxStatusReq\x1a\f.TxStatusRes\x12\"\n" +
+	"\x06TxWait\x12\n" +
+	".TxWaitReq\x1a\n" +
+	".TxWaitRes0\x01\x12 \n" +
+	"\x06TxLock\x12\n" +
+	".TxLockReq\x1a\n" +
+	".TxLockResB\aZ\x05./rpcb\x06proto3"
 
 var (
 	file_tx_proto_rawDescOnce sync.Once
@@ -669,7 +1251,7 @@ func file_tx_proto_rawDescGZIP() []byte {
 	return file_tx_proto_rawDescData
 }
 
-var file_tx_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_tx_proto_msgTypes = make([]protoimpl.MessageInfo, 21)
 var file_tx_proto_goTypes = []any{
 	(*TxSearchReq)(nil),  // 0: TxSearchReq
 	(*TxSearchRes)(nil),  // 1: TxSearchRes
@@ -679,29 +1261,47 @@ var file_tx_proto_goTypes = []any{
 	(*TxSendRes)(nil),    // 5: TxSendRes
 	(*TxVerifyReq)(nil),  // 6: TxVerifyReq
 	(*TxVerifyRes)(nil),  // 7: TxVerifyRes
-	(*TxSignReq)(nil),    // 8: TxSignReq
-	(*TxSignRes)(nil),    // 9: TxSignRes
-	(*TxReceiveReq)(nil), // 10: TxReceiveReq
-	(*TxReceiveRes)(nil), // 11: TxReceiveRes
+	(*Transfer)(nil),     // 8: Transfer
+	(*HTLC)(nil),         // 9: HTLC
+	(*TxSignReq)(nil),    // 10: TxSignReq
+	(*TxSignRes)(nil),    // 11: TxSignRes
+	(*TxReceiveReq)(nil), // 12: TxReceiveReq
+	(*TxReceiveRes)(nil), // 13: TxReceiveRes
+	(*TxStatusReq)(nil),  // 14: TxStatusReq
+	(*TxStatusRes)(nil),  // 15: TxStatusRes
+	(*TxWaitReq)(nil),    // 16: TxWaitReq
+	(*TxWaitRes)(nil),    // 17: TxWaitRes
+	(*TxLockReq)(nil),    // 18: TxLockReq
+	(*TxLockRes)(nil),    // 19: TxLockRes
+	nil,                  // 20: TxReceiveReq.TraceEntry
 }
 var file_tx_proto_depIdxs = []int32{
-	0,  // 0: Tx.TxSearch:input_type -> TxSearchReq
-	8,  // 1: Tx.TxSign:input_type -> TxSignReq
-	4,  // 2: Tx.TxSend:input_type -> TxSendReq
-	2,  // 3: Tx.TxProve:input_type -> TxProveReq
-	6,  // 4: Tx.TxVerify:input_type -> TxVerifyReq
-	10, // 5: Tx.TxReceive:input_type -> TxReceiveReq
-	1,  // 6: Tx.TxSearch:output_type -> TxSearchRes
-	9,  // 7: Tx.TxSign:output_type -> TxSignRes
-	5,  // 8: Tx.TxSend:output_type -> TxSendRes
-	3,  // 9: Tx.TxProve:output_type -> TxProveRes
-	7,  // 10: Tx.TxVerify:output_type -> TxVerifyRes
-	11, // 11: Tx.TxReceive:output_type -> TxReceiveRes
-	6,  // [6:12] is the sub-list for method output_type
-	0,  // [0:6] is the sub-list for method input_type
-	0,  // [0:0] is the sub-list for extension type_name
-	0,  // [0:0] is the sub-list for extension extendee
-	0,  // [0:0] is the sub-list for field type_name
+	8,  // 0: TxSignReq.Batch:type_name -> Transfer
+	9,  // 1: TxSignReq.HTLC:type_name -> HTLC
+	20, // 2: TxReceiveReq.Trace:type_name -> TxReceiveReq.TraceEntry
+	0,  // 3: Tx.TxSearch:input_type -> TxSearchReq
+	10, // 4: Tx.TxSign:input_type -> TxSignReq
+	4,  // 5: Tx.TxSend:input_type -> TxSendReq
+	2,  // 6: Tx.TxProve:input_type -> TxProveReq
+	6,  // 7: Tx.TxVerify:input_type -> TxVerifyReq
+	12, // 8: Tx.TxReceive:input_type -> TxReceiveReq
+	14, // 9: Tx.TxStatus:input_type -> TxStatusReq
+	16, // 10: Tx.TxWait:input_type -> TxWaitReq
+	18, // 11: Tx.TxLock:input_type -> TxLockReq
+	1,  // 12: Tx.TxSearch:output_type -> TxSearchRes
+	11, // 13: Tx.TxSign:output_type -> TxSignRes
+	5,  // 14: Tx.TxSend:output_type -> TxSendRes
+	3,  // 15: Tx.TxProve:output_type -> TxProveRes
+	7,  // 16: Tx.TxVerify:output_type -> TxVerifyRes
+	13, // 17: Tx.TxReceive:output_type -> TxReceiveRes
+	15, // 18: Tx.TxStatus:output_type -> TxStatusRes
+	17, // 19: Tx.TxWait:output_type -> TxWaitRes
+	19, // 20: Tx.TxLock:output_type -> TxLockRes
+	12, // [12:21] is the sub-list for method output_type
+	3,  // [3:12] is the sub-list for method input_type
+	3,  // [3:3] is the sub-list for extension type_name
+	3,  // [3:3] is the sub-list for extension extendee
+	0,  // [0:3] is the sub-list for field type_name
 }
 
 func init() { file_tx_proto_init() }
@@ -715,7 +1315,7 @@ func file_tx_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_tx_proto_rawDesc), len(file_tx_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   12,
+			NumMessages:   21,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -25,6 +25,9 @@ const (
 	Tx_TxProve_FullMethodName   = "/Tx/TxProve"
 	Tx_TxVerify_FullMethodName  = "/Tx/TxVerify"
 	Tx_TxReceive_FullMethodName = "/Tx/TxReceive"
+	Tx_TxStatus_FullMethodName  = "/Tx/TxStatus"
+	Tx_TxWait_FullMethodName    = "/Tx/TxWait"
+	Tx_TxLock_FullMethodName    = "/Tx/TxLock"
 )
 
 // TxClient is the client API for Tx service.
@@ -37,6 +40,9 @@ type TxClient interface {
 	TxProve(ctx context.Context, in *TxProveReq, opts ...grpc.CallOption) (*TxProveRes, error)
 	TxVerify(ctx context.Context, in *TxVerifyReq, opts ...grpc.CallOption) (*TxVerifyRes, error)
 	TxReceive(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[TxReceiveReq, TxReceiveRes], error)
+	TxStatus(ctx context.Context, in *TxStatusReq, opts ...grpc.CallOption) (*TxStatusRes, error)
+	TxWait(ctx context.Context, in *TxWaitReq, opts ...grpc.CallOption) (grpc.ServerStreamingClient[TxWaitRes], error)
+	TxLock(ctx context.Context, in *TxLockReq, opts ...grpc.CallOption) (*TxLockRes, error)
 }
 
 type txClient struct {
@@ -119,6 +125,45 @@ func (c *txClient) TxReceive(ctx context.Context, opts ...grpc.CallOption) (grpc
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type Tx_TxReceiveClient = grpc.ClientStreamingClient[TxReceiveReq, TxReceiveRes]
 
+func (c *txClient) TxStatus(ctx context.Context, in *TxStatusReq, opts ...grpc.CallOption) (*TxStatusRes, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TxStatusRes)
+	err := c.cc.Invoke(ctx, Tx_TxStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *txClient) TxWait(ctx context.Context, in *TxWaitReq, opts ...grpc.CallOption) (grpc.ServerStreamingClient[TxWaitRes], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Tx_ServiceDesc.Streams[2], Tx_TxWait_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[TxWaitReq, TxWaitRes]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Tx_TxWaitClient = grpc.ServerStreamingClient[TxWaitRes]
+
+func (c *txClient) TxLock(ctx context.Context, in *TxLockReq, opts ...grpc.CallOption) (*TxLockRes, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TxLockRes)
+	err := c.cc.Invoke(ctx, Tx_TxLock_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // TxServer is the server API for Tx service.
 // All implementations must embed UnimplementedTxServer
 // for forward compatibility.
@@ -129,6 +174,9 @@ type TxServer interface {
 	TxProve(context.Context, *TxProveReq) (*TxProveRes, error)
 	TxVerify(context.Context, *TxVerifyReq) (*TxVerifyRes, error)
 	TxReceive(grpc.ClientStreamingServer[TxReceiveReq, TxReceiveRes]) error
+	TxStatus(context.Context, *TxStatusReq) (*TxStatusRes, error)
+	TxWait(*TxWaitReq, grpc.ServerStreamingServer[TxWaitRes]) error
+	TxLock(context.Context, *TxLockReq) (*TxLockRes, error)
 	mustEmbedUnimplementedTxServer()
 }
 
@@ -157,6 +205,15 @@ func (UnimplementedTxServer) TxVerify(context.Context, *TxVerifyReq) (*TxVerifyR
 func (UnimplementedTxServer) TxReceive(grpc.ClientStreamingServer[TxReceiveReq, TxReceiveRes]) error {
 	return status.Errorf(codes.Unimplemented, "method TxReceive not implemented")
 }
+func (UnimplementedTxServer) TxStatus(context.Context, *TxStatusReq) (*TxStatusRes, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TxStatus not implemented")
+}
+func (UnimplementedTxServer) TxWait(*TxWaitReq, grpc.ServerStreamingServer[TxWaitRes]) error {
+	return status.Errorf(codes.Unimplemented, "method TxWait not implemented")
+}
+func (UnimplementedTxServer) TxLock(context.Context, *TxLockReq) (*TxLockRes, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TxLock not implemented")
+}
 func (UnimplementedTxServer) mustEmbedUnimplementedTxServer() {}
 func (UnimplementedTxServer) testEmbeddedByValue()            {}
 
@@ -268,6 +325,53 @@ func _Tx_TxReceive_Handler(srv interface{}, stream grpc.ServerStream) error {
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type Tx_TxReceiveServer = grpc.ClientStreamingServer[TxReceiveReq, TxReceiveRes]
 
+func _Tx_TxStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TxStatusReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TxServer).TxStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Tx_TxStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TxServer).TxStatus(ctx, req.(*TxStatusReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Tx_TxWait_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(TxWaitReq)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TxServer).TxWait(m, &grpc.GenericServerStream[TxWaitReq, TxWaitRes]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Tx_TxWaitServer = grpc.ServerStreamingServer[TxWaitRes]
+
+func _Tx_TxLock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TxLockReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TxServer).TxLock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Tx_TxLock_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TxServer).TxLock(ctx, req.(*TxLockReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Tx_ServiceDesc is the grpc.ServiceDesc for Tx service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -291,6 +395,14 @@ var Tx_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "TxVerify",
 			Handler:    _Tx_TxVerify_Handler,
 		},
+		{
+			MethodName: "TxStatus",
+			Handler:    _Tx_TxStatus_Handler,
+		},
+		{
+			MethodName: "TxLock",
+			Handler:    _Tx_TxLock_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -303,6 +415,11 @@ var Tx_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _Tx_TxReceive_Handler,
 			ClientStreams: true,
 		},
+		{
+			StreamName:    "TxWait",
+			Handler:       _Tx_TxWait_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "tx.proto",
 }
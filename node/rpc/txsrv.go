@@ -1,14 +1,17 @@
 package rpc
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"path/filepath"
 	"strings"
 
 	"github.com/Ansh1902396/chain"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
 	codes "google.golang.org/grpc/codes"
 	status "google.golang.org/grpc/status"
@@ -23,34 +26,86 @@ type TxRelayer interface {
 	RelayTx(tx chain.SigTx) error
 }
 
+type TxTracker interface {
+	Receipt(hash chain.Hash) (chain.Receipt, bool)
+	SubscribeReceipt(hash chain.Hash) (chan chain.Receipt, func())
+}
+
+type LockReader interface {
+	HTLC(hash chain.Hash) (chain.Lock, bool)
+}
+
 type TxSrv struct {
 	UnimplementedTxServer
 	keyStoreDir   string
 	blockStoreDir string
 	txApplier     TxApplier
 	txRelayer     TxRelayer
+	txTracker     TxTracker
+	lockReader    LockReader
+	clock         chain.Clock
+	traceStore    TraceStore
+	log           *slog.Logger
 }
 
-func NewTxSrv(keyStoreDir, blockStoreDir string, txApplier TxApplier, txRelayer TxRelayer) *TxSrv {
+func NewTxSrv(
+	keyStoreDir, blockStoreDir string, txApplier TxApplier, txRelayer TxRelayer,
+	txTracker TxTracker, lockReader LockReader, clock chain.Clock,
+	traceStore TraceStore, log *slog.Logger,
+) *TxSrv {
 	return &TxSrv{
 		keyStoreDir:   keyStoreDir,
 		blockStoreDir: blockStoreDir,
 		txApplier:     txApplier,
 		txRelayer:     txRelayer,
+		txTracker:     txTracker,
+		lockReader:    lockReader,
+		clock:         clock,
+		traceStore:    traceStore,
+		log:           log,
+	}
+}
+
+func newHTLC(req *HTLC) (*chain.HTLC, error) {
+	htlc := &chain.HTLC{
+		Op: req.Op, TimeLock: req.TimeLock, Preimage: req.Preimage,
+	}
+	var err error
+	if len(req.HashLock) > 0 {
+		htlc.HashLock, err = chain.DecodeHash(req.HashLock)
+		if err != nil {
+			return nil, fmt.Errorf("invalid hash lock: %v", err)
+		}
 	}
+	if len(req.Lock) > 0 {
+		htlc.Lock, err = chain.DecodeHash(req.Lock)
+		if err != nil {
+			return nil, fmt.Errorf("invalid lock hash: %v", err)
+		}
+	}
+	return htlc, nil
+}
+
+type txSearchMatch struct {
+	blk   chain.SigBlock
+	tx    chain.SigTx
+	entry int
 }
 
 func sendTxSearchRes(
-	blk chain.SigBlock, tx chain.SigTx,
+	blk chain.SigBlock, tx chain.SigTx, entry int, total uint64, cursor string,
 	stream grpc.ServerStreamingServer[TxSearchRes],
 ) error {
 	stx := chain.NewSearchTx(tx, blk.Number, blk.Hash(), blk.MerkleRoot)
+	if len(tx.Batch) > 0 {
+		stx.Entry = &chain.BatchEntry{Index: entry, Transfer: tx.Batch[entry]}
+	}
 	jtx, err := json.Marshal(stx)
 
 	if err != nil {
 		return err
 	}
-	res := &TxSearchRes{Tx: jtx}
+	res := &TxSearchRes{Tx: jtx, Total: total, Cursor: cursor}
 	err = stream.Send(res)
 	if err != nil {
 		return err
@@ -59,73 +114,138 @@ func sendTxSearchRes(
 }
 
 func (s *TxSrv) TxSign(_ context.Context, req *TxSignReq) (*TxSignRes, error) {
+	if len(req.Data) > chain.TxDataMaxLen {
+		return nil, status.Errorf(
+			codes.InvalidArgument, "data length %d exceeds %d bytes",
+			len(req.Data), chain.TxDataMaxLen,
+		)
+	}
 	path := filepath.Join(s.keyStoreDir, req.From)
 	acc, err := chain.ReadAccount(path, []byte(req.Password))
 	if err != nil {
-		return nil, status.Errorf(codes.InvalidArgument, err.Error())
+		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
+	nonce := s.txApplier.Nonce(chain.Address(req.From)) + 1
 	tx := chain.NewTx(
-		chain.Address(req.From), chain.Address(req.To), req.Value,
-		s.txApplier.Nonce(chain.Address(req.From))+1,
+		s.clock, chain.Address(req.From), chain.Address(req.To), req.Value, nonce,
+		req.Data,
 	)
+	if len(req.Batch) > 0 {
+		if len(req.To) > 0 || req.Value != 0 {
+			return nil, status.Error(
+				codes.InvalidArgument, "batch transaction must not set to and value",
+			)
+		}
+		if len(req.Batch) > chain.TxBatchMaxLen {
+			return nil, status.Errorf(
+				codes.InvalidArgument, "batch of %d transfers exceeds %d",
+				len(req.Batch), chain.TxBatchMaxLen,
+			)
+		}
+		batch := make([]chain.Transfer, len(req.Batch))
+		for i, tr := range req.Batch {
+			batch[i] = chain.Transfer{To: chain.Address(tr.To), Value: tr.Value}
+		}
+		tx = chain.NewBatchTx(s.clock, chain.Address(req.From), batch, nonce, req.Data)
+	}
+	if req.HTLC != nil {
+		tx.HTLC, err = newHTLC(req.HTLC)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+		}
+	}
+	tx.NotBefore, tx.ValidUntil = req.NotBefore, req.ValidUntil
+	sameUnit := (tx.NotBefore < chain.TxHeightLimit) ==
+		(tx.ValidUntil < chain.TxHeightLimit)
+	if tx.ValidUntil != 0 && sameUnit && tx.NotBefore > tx.ValidUntil {
+		return nil, status.Errorf(
+			codes.InvalidArgument, "not before %d is after valid until %d",
+			tx.NotBefore, tx.ValidUntil,
+		)
+	}
 	stx, err := acc.SignTx(tx)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, err.Error())
+		return nil, status.Error(codes.Internal, err.Error())
 	}
 	jtx, err := json.Marshal(stx)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, err.Error())
+		return nil, status.Error(codes.Internal, err.Error())
 	}
 	res := &TxSignRes{Tx: jtx}
 	return res, nil
 }
 
+// TxSearch streams a page of the transfers that match all the set criteria
+// with the total number of matches after the cursor
 func (s *TxSrv) TxSearch(
 	req *TxSearchReq, stream grpc.ServerStreamingServer[TxSearchRes],
 ) error {
+	page, err := newSearchPage[txSearchMatch](
+		req.Limit, req.Offset, req.Cursor, req.Desc,
+	)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
 	blocks, closeBlocks, err := chain.ReadBlocks(s.blockStoreDir)
 	if err != nil {
-		return status.Errorf(codes.NotFound, err.Error())
+		return status.Error(codes.NotFound, err.Error())
 	}
 	defer closeBlocks()
 	prefix := strings.HasPrefix
-block:
 	for err, blk := range blocks {
 		if err != nil {
-			return status.Errorf(codes.Internal, err.Error())
+			return status.Error(codes.Internal, err.Error())
 		}
-		for _, tx := range blk.Txs {
-			if len(req.Hash) > 0 && prefix(tx.Hash().String(), req.Hash) {
-				err = sendTxSearchRes(blk, tx, stream)
-				if err != nil {
-					return status.Errorf(codes.Internal, err.Error())
-				}
-				break block
+		if !blockInRange(blk, req.FromNumber, req.ToNumber, req.Since, req.Until) {
+			continue
+		}
+		for i, tx := range blk.Txs {
+			if len(req.Hash) > 0 && !prefix(tx.Hash().String(), req.Hash) ||
+				len(req.From) > 0 && !prefix(string(tx.From), req.From) ||
+				len(req.Memo) > 0 && !bytes.HasPrefix(tx.Data, []byte(req.Memo)) {
+				continue
 			}
-			if len(req.From) > 0 && prefix(string(tx.From), req.From) ||
-				len(req.To) > 0 && prefix(string(tx.To), req.To) ||
-				len(req.Account) > 0 &&
-					(prefix(string(tx.From), req.From) || prefix(string(tx.To), req.To)) {
-				err := sendTxSearchRes(blk, tx, stream)
-				if err != nil {
-					return status.Errorf(codes.Internal, err.Error())
+			// Every transfer of a batch transaction is matched individually
+			for e, tr := range tx.Transfers() {
+				if len(req.To) > 0 && !prefix(string(tr.To), req.To) ||
+					len(req.Account) > 0 &&
+						!prefix(string(tx.From), req.Account) &&
+						!prefix(string(tr.To), req.Account) {
+					continue
 				}
+				pos := searchPos{blk.Number, uint64(i), uint64(e)}
+				page.add(pos, txSearchMatch{blk: blk, tx: tx, entry: e})
 			}
 		}
 	}
+	for _, match := range page.page() {
+		m := match.item
+		err = sendTxSearchRes(
+			m.blk, m.tx, m.entry, page.total, match.pos.String(), stream,
+		)
+		if err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+	}
 	return nil
 }
 
-func (s *TxSrv) TxSend(_ context.Context, req *TxSendReq) (*TxSendRes, error) {
+func (s *TxSrv) TxSend(ctx context.Context, req *TxSendReq) (*TxSendRes, error) {
 	var tx chain.SigTx
 	err := json.Unmarshal(req.Tx, &tx)
 
 	if err != nil {
 		return nil, status.Errorf(codes.InvalidArgument, "Invalid transaction format: %v", err)
 	}
-	err = s.txApplier.ApplyTx(tx)
+	// The trace of the transaction starts when it is sent to the node
+	ctx, span := tracer.Start(ctx, "tx.send", TxSpanAttrs(tx))
+	defer span.End()
+	err = traceTx(ctx, s.txApplier, tx)
 	if err != nil {
-		return nil, status.Errorf(codes.FailedPrecondition, err.Error())
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	if s.traceStore != nil {
+		s.traceStore.SaveTrace(ctx, tx.Hash())
 	}
 
 	if s.txRelayer != nil {
@@ -139,18 +259,18 @@ func (s *TxSrv) TxProve(_ context.Context, req *TxProveReq) (*TxProveRes, error)
 	blocks, closeBlocks, err := chain.ReadBlocks(s.blockStoreDir)
 
 	if err != nil {
-		return nil, status.Errorf(codes.NotFound, err.Error())
+		return nil, status.Error(codes.NotFound, err.Error())
 	}
 
 	defer closeBlocks()
 
 	for err != nil {
-		return nil, status.Errorf(codes.Internal, err.Error())
+		return nil, status.Error(codes.Internal, err.Error())
 	}
 
 	for err, blk := range blocks {
 		if err != nil {
-			return nil, status.Errorf(codes.Internal, err.Error())
+			return nil, status.Error(codes.Internal, err.Error())
 		}
 
 		for _, tx := range blk.Txs {
@@ -160,19 +280,19 @@ func (s *TxSrv) TxProve(_ context.Context, req *TxProveReq) (*TxProveRes, error)
 				)
 
 				if err != nil {
-					return nil, status.Errorf(codes.Internal, err.Error())
+					return nil, status.Error(codes.Internal, err.Error())
 				}
 
 				merkleProof, err := chain.MerkleProve(tx.Hash(), merkleTree)
 
 				if err != nil {
-					return nil, status.Errorf(codes.Internal, err.Error())
+					return nil, status.Error(codes.Internal, err.Error())
 				}
 
 				jmp, err := json.Marshal(merkleProof)
 
 				if err != nil {
-					return nil, status.Errorf(codes.Internal, err.Error())
+					return nil, status.Error(codes.Internal, err.Error())
 				}
 				res := &TxProveRes{MerkleProof: jmp}
 
@@ -183,7 +303,7 @@ func (s *TxSrv) TxProve(_ context.Context, req *TxProveReq) (*TxProveRes, error)
 	}
 
 	return nil, status.Errorf(
-		codes.NotFound, fmt.Sprintf("Transaction not found: %v", req.Hash),
+		codes.NotFound, "Transaction not found: %v", req.Hash,
 	)
 
 }
@@ -201,12 +321,12 @@ func (s *TxSrv) TxVerify(
 	err = json.Unmarshal(req.MerkleProof, &merkleProof)
 
 	if err != nil {
-		return nil, status.Errorf(codes.InvalidArgument, err.Error())
+		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
 	merkleRoot, err := chain.DecodeHash(req.MerkleRoot)
 	if err != nil {
-		return nil, status.Errorf(codes.InvalidArgument, err.Error())
+		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
 	valid := chain.MerkleVerify(txh, merkleProof, merkleRoot, chain.TxPairHash)
@@ -225,7 +345,7 @@ func (s *TxSrv) TxReceive(
 		}
 
 		if err != nil {
-			return status.Errorf(codes.Internal, err.Error())
+			return status.Error(codes.Internal, err.Error())
 		}
 
 		var tx chain.SigTx
@@ -233,18 +353,29 @@ func (s *TxSrv) TxReceive(
 		err = json.Unmarshal(req.Tx, &tx)
 
 		if err != nil {
-			fmt.Println(err)
+			s.log.Warn("Tx decode", "err", err)
 			continue
 		}
 
-		fmt.Printf("<== Tx receive: %v\n", tx)
+		log := s.log.With("tx", tx.Hash().String())
+		log.Debug("Tx receive", "from", tx.From, "nonce", tx.Nonce)
 
-		err = s.txApplier.ApplyTx(tx)
+		ctx := ExtractTrace(stream.Context(), req.Trace)
+		ctx, span := tracer.Start(
+			ctx, "tx.receive", TxSpanAttrs(tx),
+			trace.WithSpanKind(trace.SpanKindConsumer),
+		)
+		err = traceTx(ctx, s.txApplier, tx)
 
 		if err != nil {
-			fmt.Println(err)
+			span.End()
+			log.Warn("Tx rejected", "err", err)
 			continue
 		}
+		if s.traceStore != nil {
+			s.traceStore.SaveTrace(ctx, tx.Hash())
+		}
+		span.End()
 
 		if s.txRelayer != nil {
 			s.txRelayer.RelayTx(tx)
@@ -252,3 +383,75 @@ func (s *TxSrv) TxReceive(
 
 	}
 }
+
+func (s *TxSrv) TxStatus(
+	_ context.Context, req *TxStatusReq,
+) (*TxStatusRes, error) {
+	hash, err := chain.DecodeHash(req.Hash)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid transaction hash: %v", err)
+	}
+	rcp, exist := s.txTracker.Receipt(hash)
+	if !exist {
+		return nil, status.Errorf(codes.NotFound, "Transaction not found: %v", req.Hash)
+	}
+	jrcp, err := json.Marshal(rcp)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "%v", err)
+	}
+	res := &TxStatusRes{Receipt: jrcp}
+	return res, nil
+}
+
+func (s *TxSrv) TxWait(
+	req *TxWaitReq, stream grpc.ServerStreamingServer[TxWaitRes],
+) error {
+	hash, err := chain.DecodeHash(req.Hash)
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "Invalid transaction hash: %v", err)
+	}
+	chRcp, unsubscribe := s.txTracker.SubscribeReceipt(hash)
+	defer unsubscribe()
+
+	last := chain.TxUnknown
+	rcp, exist := s.txTracker.Receipt(hash)
+	for {
+		if exist && rcp.Status != last {
+			jrcp, err := json.Marshal(rcp)
+			if err != nil {
+				return status.Errorf(codes.Internal, "%v", err)
+			}
+			err = stream.Send(&TxWaitRes{Receipt: jrcp})
+			if err != nil {
+				return status.Errorf(codes.Internal, "%v", err)
+			}
+			if rcp.Status.Final() {
+				return nil
+			}
+			last = rcp.Status
+		}
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case rcp = <-chRcp:
+			exist = true
+		}
+	}
+}
+
+func (s *TxSrv) TxLock(_ context.Context, req *TxLockReq) (*TxLockRes, error) {
+	hash, err := chain.DecodeHash(req.Hash)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid lock hash: %v", err)
+	}
+	lock, exist := s.lockReader.HTLC(hash)
+	if !exist {
+		return nil, status.Errorf(codes.NotFound, "Lock not found: %v", req.Hash)
+	}
+	jlock, err := json.Marshal(lock)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "%v", err)
+	}
+	res := &TxLockRes{Lock: jlock}
+	return res, nil
+}
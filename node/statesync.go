@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
+	"sync"
 
 	"github.com/Ansh1902396/chain"
 	"github.com/Ansh1902396/node/rpc"
@@ -12,29 +14,92 @@ import (
 	"google.golang.org/grpc/credentials/insecure"
 )
 
+const (
+	SyncGenesis = "genesis"
+	SyncBlocks  = "blocks"
+	SyncSynced  = "synced"
+	SyncBehind  = "behind"
+)
+
 type StateSync struct {
 	// Define the fields for the StateSync struct
 	cfg        NodeCfg
 	ctx        context.Context
 	state      *chain.State
 	peerReader PeerReader
+	log        *slog.Logger
+	mtx        sync.Mutex
+	stage      string
+	peerHeight uint64
 }
 
 func NewStateSync(
-	ctx context.Context, cfg NodeCfg, peerReader PeerReader,
+	ctx context.Context, cfg NodeCfg, peerReader PeerReader, log *slog.Logger,
 ) *StateSync {
 	return &StateSync{
 		ctx:        ctx,
 		cfg:        cfg,
 		peerReader: peerReader,
+		log:        log,
+		stage:      SyncGenesis,
+	}
+
+}
+
+func (s *StateSync) setStage(stage string) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.stage = stage
+}
+
+// ObserveBlock records the number of a block received from a peer when the
+// block is signed by the authority or committed by the validators
+func (s *StateSync) ObserveBlock(blk chain.SigBlock) {
+	// Verifying the signatures of the commits is costly
+	s.mtx.Lock()
+	known := blk.Number <= max(s.peerHeight, s.state.LastBlock().Number)
+	s.mtx.Unlock()
+	if known {
+		return
+	}
+	err := s.state.VerifyBlock(blk)
+	if err != nil {
+		return
 	}
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.peerHeight = max(s.peerHeight, blk.Number)
+}
 
+// SyncStatus returns the stage of the initial sync, then whether the node is
+// behind the blocks received from the peers
+func (s *StateSync) SyncStatus() string {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.stage != SyncSynced {
+		return s.stage
+	}
+	if s.state.LastBlock().Number < s.peerHeight {
+		return SyncBehind
+	}
+	return SyncSynced
 }
 
 func (s *StateSync) SyncState() (*chain.State, error) {
 	gen, err := chain.ReadGenesis(s.cfg.BlockStoreDir)
+	if err == nil && len(s.cfg.GenesisFile) > 0 {
+		errCheck := s.checkGenesis(gen)
+		if errCheck != nil {
+			return nil, errCheck
+		}
+	}
 	if err != nil {
-		if s.cfg.Bootstrap {
+		if len(s.cfg.GenesisFile) > 0 {
+			gen, err = s.loadGenesis()
+			if err != nil {
+				return nil, err
+			}
+		} else if s.cfg.Bootstrap {
 			gen, err = s.createGenesis()
 			if err != nil {
 				return nil, err
@@ -55,6 +120,8 @@ func (s *StateSync) SyncState() (*chain.State, error) {
 		return nil, fmt.Errorf("invalid genesis signature")
 	}
 	s.state = chain.NewState(&gen)
+	s.state.SetClock(s.cfg.Clock)
+	s.setStage(SyncBlocks)
 	err = chain.InitBlockStore(s.cfg.BlockStoreDir)
 	if err != nil {
 		return nil, err
@@ -65,6 +132,8 @@ func (s *StateSync) SyncState() (*chain.State, error) {
 	if err != nil {
 		return nil, err
 	}
+	// The blocks applied from now on are persisted to the block store
+	s.state.SetBlockStore(s.cfg.BlockStoreDir)
 
 	err = s.syncBlocks()
 
@@ -72,7 +141,11 @@ func (s *StateSync) SyncState() (*chain.State, error) {
 		return nil, err
 	}
 
-	fmt.Printf("=== Sync state \n %v", s.state)
+	s.setStage(SyncSynced)
+	s.log.Info(
+		"State synced", "block", s.state.LastBlock().Number,
+		"authority", s.state.Authority(),
+	)
 	return s.state, nil
 
 }
@@ -128,11 +201,60 @@ func (s *StateSync) createGenesis() (chain.SigGenesis, error) {
 	if err != nil {
 		return chain.SigGenesis{}, err
 	}
+	s.log.Info(
+		"Genesis created", "hash", sgen.Hash().String(),
+		"authority", auth.Address(), "owner", acc.Address(),
+	)
 
 	return sgen, nil
 
 }
 
+// loadGenesis reads the signed genesis from the genesis file and writes it
+// to the block store
+func (s *StateSync) loadGenesis() (chain.SigGenesis, error) {
+	gen, err := chain.ReadGenesisFile(s.cfg.GenesisFile)
+	if err != nil {
+		return chain.SigGenesis{}, err
+	}
+	err = gen.Validate()
+	if err != nil {
+		return chain.SigGenesis{}, err
+	}
+	valid, err := chain.VerifyGen(gen)
+	if err != nil {
+		return chain.SigGenesis{}, err
+	}
+	if !valid {
+		return chain.SigGenesis{}, fmt.Errorf("invalid genesis signature")
+	}
+	err = gen.Write(s.cfg.BlockStoreDir)
+	if err != nil {
+		return chain.SigGenesis{}, err
+	}
+	s.log.Info(
+		"Genesis loaded", "file", s.cfg.GenesisFile, "hash", gen.Hash().String(),
+		"authority", gen.Authority, "accounts", len(gen.Balances),
+	)
+	return gen, nil
+}
+
+// checkGenesis rejects a genesis file that differs from the genesis of the
+// block store
+func (s *StateSync) checkGenesis(gen chain.SigGenesis) error {
+	file, err := chain.ReadGenesisFile(s.cfg.GenesisFile)
+	if err != nil {
+		return err
+	}
+	if file.Hash() != gen.Hash() {
+		return fmt.Errorf(
+			"genesis %v of %v differs from genesis %v of the block store",
+			file.Hash(), s.cfg.GenesisFile, gen.Hash(),
+		)
+	}
+	return nil
+}
+
 func (s *StateSync) syncGenesis() (chain.SigGenesis, error) {
 	jgen, err := s.grpcGenesisSync()
 	if err != nil {
@@ -159,6 +281,7 @@ func (s *StateSync) syncGenesis() (chain.SigGenesis, error) {
 	if err != nil {
 		return chain.SigGenesis{}, err
 	}
+	s.log.Info("Genesis synced", "peer", s.cfg.SeedAddr, "hash", gen.Hash().String())
 
 	return gen, nil
 
@@ -208,15 +331,7 @@ func (s *StateSync) syncBlocks() error {
 			if err != nil {
 				return err
 			}
-			clone := s.state.Clone()
-			err = clone.ApplyBlock(blk)
-			if err != nil {
-				return err
-			}
-			s.state.Apply(clone)
-
-			err = blk.Write(s.cfg.BlockStoreDir)
-
+			err = s.state.ApplyBlockToState(blk)
 			if err != nil {
 				return err
 			}
@@ -229,6 +344,7 @@ func (s *StateSync) syncBlocks() error {
 func (s *StateSync) grpcGenesisSync() ([]byte, error) {
 	conn, err := grpc.NewClient(
 		s.cfg.SeedAddr, grpc.WithTransportCredentials(insecure.NewCredentials()),
+		rpc.WithMaxMsgSize(),
 	)
 	if err != nil {
 		return nil, err
@@ -252,6 +368,7 @@ func (s *StateSync) grpcBlockSync(peer string) (
 ) {
 	conn, err := grpc.NewClient(
 		peer, grpc.WithTransportCredentials(insecure.NewCredentials()),
+		rpc.WithMaxMsgSize(),
 	)
 	if err != nil {
 		return nil, nil, err
@@ -274,7 +391,7 @@ func (s *StateSync) grpcBlockSync(peer string) (
 	blocks := func(yield func(err error, jblk []byte) bool) {
 		for more {
 			res, err := stream.Recv()
-			if err != io.EOF {
+			if err == io.EOF {
 				return
 			}
 			if err != nil {
@@ -0,0 +1,124 @@
+package node
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/Ansh1902396/chain"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/stats"
+)
+
+var tracer = otel.Tracer("github.com/Ansh1902396/node")
+
+// NewTracerProvider exports the spans of the node to an OTLP gRPC collector
+func NewTracerProvider(
+	ctx context.Context, otlpAddr, nodeAddr string,
+) (*sdktrace.TracerProvider, error) {
+	exp, err := otlptracegrpc.New(
+		ctx, otlptracegrpc.WithEndpoint(otlpAddr), otlptracegrpc.WithInsecure(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	res := resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName("ruchain"),
+		semconv.ServiceVersion(Version),
+		semconv.ServiceInstanceID(nodeAddr),
+	)
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exp), sdktrace.WithResource(res),
+	)
+	return tp, nil
+}
+
+// setupTracing propagates the W3C trace context between the nodes and, with
+// a collector address, exports the spans of the node. The returned func
+// flushes the spans
+func setupTracing(
+	ctx context.Context, otlpAddr, nodeAddr string,
+) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	if len(otlpAddr) == 0 {
+		return func(context.Context) error { return nil }, nil
+	}
+	tp, err := NewTracerProvider(ctx, otlpAddr, nodeAddr)
+	if err != nil {
+		return nil, err
+	}
+	otel.SetTracerProvider(tp)
+	return tp.Shutdown, nil
+}
+
+// grpcTraceHandler traces the gRPC requests to the node. The relay streams
+// are traced per message and the peer discovery is not traced
+func grpcTraceHandler() stats.Handler {
+	return otelgrpc.NewServerHandler(otelgrpc.WithFilter(
+		func(info *stats.RPCTagInfo) bool {
+			for _, method := range []string{
+				"/PeerDiscover", "/TxReceive", "/BlockReceive",
+				"/ConsensusReceive",
+			} {
+				if strings.HasSuffix(info.FullMethodName, method) {
+					return false
+				}
+			}
+			return true
+		},
+	))
+}
+
+// Traces keeps the span context of the most recent transactions and blocks
+// by hash. The oldest span context is dropped once the capacity is reached
+type Traces struct {
+	mtx   sync.Mutex
+	spans map[chain.Hash]trace.SpanContext
+	order []chain.Hash
+	cap   int
+}
+
+func NewTraces(cap int) *Traces {
+	return &Traces{spans: make(map[chain.Hash]trace.SpanContext), cap: cap}
+}
+
+func (t *Traces) SaveTrace(ctx context.Context, hash chain.Hash) {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return
+	}
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	_, exist := t.spans[hash]
+	t.spans[hash] = sc
+	if exist {
+		return
+	}
+	t.order = append(t.order, hash)
+	for len(t.order) > t.cap {
+		delete(t.spans, t.order[0])
+		t.order = t.order[1:]
+	}
+}
+
+// TraceContext returns the context with the saved span context of the hash
+// as the remote parent
+func (t *Traces) TraceContext(
+	ctx context.Context, hash chain.Hash,
+) (context.Context, bool) {
+	t.mtx.Lock()
+	sc, exist := t.spans[hash]
+	t.mtx.Unlock()
+	if !exist {
+		return ctx, false
+	}
+	return trace.ContextWithRemoteSpanContext(ctx, sc), true
+}
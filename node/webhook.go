@@ -0,0 +1,440 @@
+package node
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/Ansh1902396/chain"
+)
+
+const (
+	webhookAttempts   = 8
+	webhookBackoff    = time.Second
+	webhookMaxBackoff = time.Minute
+	webhookTimeout    = 10 * time.Second
+	// The delivery progress is persisted every webhookFlush instead of on
+	// every attempt. The events after the persisted cursors are delivered
+	// again after a crash
+	webhookFlush = 5 * time.Second
+)
+
+// webhookPayload is an event with the JSON encoded tx or block body embedded
+// as is
+type webhookPayload struct {
+	Webhook string          `json:"webhook"`
+	Seq     uint64          `json:"seq"`
+	Height  uint64          `json:"height"`
+	Type    string          `json:"type"`
+	Action  string          `json:"action"`
+	Body    json.RawMessage `json:"body"`
+}
+
+type webhookWorker struct {
+	hook     chain.Webhook
+	chNotify chan struct{}
+	cancel   context.CancelFunc
+}
+
+// WebhookDispatcher delivers persisted events to the registered webhooks.
+// Each webhook follows the event store from its own cursor, so deliveries
+// are ordered and resume after a node restart
+type WebhookDispatcher struct {
+	ctx          context.Context
+	wg           *sync.WaitGroup
+	dir          string
+	evStream     *EventStream
+	allowPrivate bool
+	client       *http.Client
+	mtx          sync.Mutex
+	head         uint64
+	workers      map[string]*webhookWorker
+	dirty        bool
+	log          *slog.Logger
+}
+
+// NewWebhookDispatcher creates a dispatcher that only calls public
+// addresses, unless private addresses are allowed by the operator
+func NewWebhookDispatcher(
+	ctx context.Context, wg *sync.WaitGroup, dir string, evStream *EventStream,
+	allowPrivate bool, log *slog.Logger,
+) *WebhookDispatcher {
+	d := &WebhookDispatcher{
+		ctx: ctx, wg: wg, dir: dir, evStream: evStream, allowPrivate: allowPrivate,
+		workers: make(map[string]*webhookWorker),
+		log:     log,
+	}
+	// The addresses are checked on connect, as a host may resolve to another
+	// address than on registration
+	dialer := &net.Dialer{Timeout: webhookTimeout, Control: d.checkAddr}
+	d.client = &http.Client{
+		Timeout:   webhookTimeout,
+		Transport: &http.Transport{DialContext: dialer.DialContext},
+	}
+	return d
+}
+
+// publicIP reports whether the address is neither of the node host nor of
+// a private or link-local network
+func publicIP(ip net.IP) bool {
+	return !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsUnspecified() &&
+		!ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() && !ip.IsMulticast()
+}
+
+func (d *WebhookDispatcher) checkAddr(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if !d.allowPrivate && (ip == nil || !publicIP(ip)) {
+		return fmt.Errorf("%w: private address %v", chain.ErrWebhookTarget, host)
+	}
+	return nil
+}
+
+// checkTarget rejects the URL of a host that resolves to a private address
+func (d *WebhookDispatcher) checkTarget(rawURL string) error {
+	if d.allowPrivate {
+		return nil
+	}
+	hookURL, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("%w: %v", chain.ErrWebhookTarget, err)
+	}
+	ips, err := net.DefaultResolver.LookupIPAddr(d.ctx, hookURL.Hostname())
+	if err != nil {
+		return fmt.Errorf("%w: %v", chain.ErrWebhookTarget, err)
+	}
+	for _, ip := range ips {
+		if !publicIP(ip.IP) {
+			return fmt.Errorf(
+				"%w: %v resolves to private address %v", chain.ErrWebhookTarget,
+				hookURL.Hostname(), ip.IP,
+			)
+		}
+	}
+	return nil
+}
+
+func (d *WebhookDispatcher) DispatchEvents() {
+	defer d.wg.Done()
+	chEvent, seq := d.evStream.AddSubscriber("webhooks")
+	defer d.evStream.RemoveSubscriber("webhooks")
+	hooks, err := chain.ReadWebhooks(d.dir)
+	if err != nil {
+		d.log.Error("Webhooks read", "err", err)
+	}
+	d.mtx.Lock()
+	d.head = seq
+	for _, hook := range hooks {
+		_, exist := d.workers[hook.ID]
+		if !exist {
+			d.startWorker(hook)
+		}
+	}
+	d.mtx.Unlock()
+	tick := time.NewTicker(webhookFlush)
+	defer tick.Stop()
+	// Persist the progress of the last deliveries on shutdown
+	defer d.flushWebhooks()
+	for {
+		select {
+		case <-d.ctx.Done():
+			return
+		case <-tick.C:
+			d.flushWebhooks()
+		case event, open := <-chEvent:
+			if !open {
+				return
+			}
+			d.mtx.Lock()
+			d.head = max(d.head, event.Seq)
+			for _, worker := range d.workers {
+				select {
+				case worker.chNotify <- struct{}{}:
+				default:
+				}
+			}
+			d.mtx.Unlock()
+		}
+	}
+}
+
+// AddWebhook persists a webhook, then starts to deliver the events to the
+// webhook. Without a cursor or a minimum height only new events are delivered
+func (d *WebhookDispatcher) AddWebhook(
+	url, secret string, filter chain.EventFilter, cursor uint64,
+) (chain.Webhook, error) {
+	err := d.checkTarget(url)
+	if err != nil {
+		return chain.Webhook{}, err
+	}
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	if cursor == 0 && filter.MinHeight == 0 {
+		cursor = d.head
+	}
+	hook, err := chain.NewWebhook(url, secret, filter, min(cursor, d.head))
+	if err != nil {
+		return chain.Webhook{}, err
+	}
+	err = d.writeWebhooks(hook)
+	if err != nil {
+		return chain.Webhook{}, err
+	}
+	d.startWorker(hook)
+	d.log.Info("Webhook added", "hook", hook.ID, "url", hook.URL)
+	return hook, nil
+}
+
+func (d *WebhookDispatcher) RemoveWebhook(id string) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	worker, exist := d.workers[id]
+	if !exist {
+		return fmt.Errorf("webhook %v not found", id)
+	}
+	worker.cancel()
+	delete(d.workers, id)
+	d.log.Info("Webhook removed", "hook", id)
+	return d.writeWebhooks()
+}
+
+// Webhooks returns the delivery status of the webhooks without the secrets
+func (d *WebhookDispatcher) Webhooks() []chain.Webhook {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	hooks := make([]chain.Webhook, 0, len(d.workers))
+	for _, worker := range d.workers {
+		hook := worker.hook
+		hook.Secret = ""
+		hooks = append(hooks, hook)
+	}
+	slices.SortFunc(hooks, func(a, b chain.Webhook) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+	return hooks
+}
+
+// writeWebhooks persists the webhooks of the workers and the added ones
+func (d *WebhookDispatcher) writeWebhooks(added ...chain.Webhook) error {
+	hooks := make([]chain.Webhook, 0, len(d.workers)+len(added))
+	for _, worker := range d.workers {
+		hooks = append(hooks, worker.hook)
+	}
+	hooks = append(hooks, added...)
+	err := chain.WriteWebhooks(d.dir, hooks)
+	if err != nil {
+		return err
+	}
+	d.dirty = false
+	return nil
+}
+
+// flushWebhooks persists the delivery progress since the last write
+func (d *WebhookDispatcher) flushWebhooks() {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	if !d.dirty {
+		return
+	}
+	err := d.writeWebhooks()
+	if err != nil {
+		d.log.Error("Webhooks write", "err", err)
+	}
+}
+
+// updateWebhook applies the delivery progress to a webhook that has not
+// been removed meanwhile. The progress is persisted by the next flush
+func (d *WebhookDispatcher) updateWebhook(id string, update func(hook *chain.Webhook)) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	worker, exist := d.workers[id]
+	if !exist {
+		return
+	}
+	update(&worker.hook)
+	d.dirty = true
+}
+
+func (d *WebhookDispatcher) startWorker(hook chain.Webhook) {
+	ctx, cancel := context.WithCancel(d.ctx)
+	worker := &webhookWorker{
+		hook: hook, chNotify: make(chan struct{}, 1), cancel: cancel,
+	}
+	d.workers[hook.ID] = worker
+	d.wg.Add(1)
+	go d.deliverEvents(ctx, hook)
+}
+
+func (d *WebhookDispatcher) deliverEvents(ctx context.Context, hook chain.Webhook) {
+	defer d.wg.Done()
+	for {
+		d.mtx.Lock()
+		head := d.head
+		worker, exist := d.workers[hook.ID]
+		d.mtx.Unlock()
+		if !exist {
+			return
+		}
+		if hook.Cursor < head {
+			cursor, err := d.deliverRange(ctx, hook, head)
+			if err != nil {
+				return
+			}
+			hook.Cursor = cursor
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-worker.chNotify:
+		}
+	}
+}
+
+// deliverRange delivers the matching events after the webhook cursor up to
+// and including the head and returns the new cursor
+func (d *WebhookDispatcher) deliverRange(
+	ctx context.Context, hook chain.Webhook, head uint64,
+) (uint64, error) {
+	log := d.log.With("hook", hook.ID)
+	events, closeEvents, err := chain.ReadEvents(d.dir, hook.Cursor)
+	if err != nil {
+		log.Error("Event store read", "err", err)
+		return hook.Cursor, nil
+	}
+	defer closeEvents()
+	cursor := hook.Cursor
+	for err, event := range events {
+		if err != nil {
+			log.Error("Event store read", "err", err)
+			break
+		}
+		if event.Seq > cursor && hook.Filter.Match(event) {
+			err = d.deliverEvent(ctx, hook, event)
+			if err != nil {
+				return cursor, err
+			}
+		}
+		cursor = max(cursor, event.Seq)
+		// Stop before the events being appended to the event store
+		if event.Seq >= head {
+			break
+		}
+	}
+	d.updateWebhook(hook.ID, func(hook *chain.Webhook) {
+		hook.Cursor = max(hook.Cursor, cursor)
+	})
+	return cursor, nil
+}
+
+// deliverEvent posts the event with exponential backoff between attempts.
+// The event is dead lettered once all attempts fail. An error is returned
+// only when the delivery is canceled
+func (d *WebhookDispatcher) deliverEvent(
+	ctx context.Context, hook chain.Webhook, event chain.Event,
+) error {
+	backoff := webhookBackoff
+	for attempt := uint64(1); ; attempt++ {
+		err := d.postEvent(ctx, hook, event)
+		if err == nil {
+			d.updateWebhook(hook.ID, func(hook *chain.Webhook) {
+				hook.Cursor = event.Seq
+				hook.Delivered++
+				hook.Attempts = 0
+				hook.LastError = ""
+				hook.LastDelivery = time.Now()
+			})
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		log := d.log.With("hook", hook.ID, "seq", event.Seq)
+		log.Warn("Webhook delivery", "attempt", attempt, "err", err)
+		if attempt == webhookAttempts {
+			dead := chain.DeadLetter{
+				Webhook: hook.ID, URL: hook.URL, Event: event, Attempts: attempt,
+				Error: err.Error(), Time: time.Now(),
+			}
+			log.Error("Webhook dead letter", "err", err)
+			err = dead.Write(d.dir)
+			if err != nil {
+				log.Error("Dead letter write", "err", err)
+			}
+			d.updateWebhook(hook.ID, func(hook *chain.Webhook) {
+				hook.Cursor = event.Seq
+				hook.Failed++
+				hook.Attempts = 0
+				hook.LastError = dead.Error
+			})
+			return nil
+		}
+		d.updateWebhook(hook.ID, func(hook *chain.Webhook) {
+			hook.Attempts = attempt
+			hook.LastError = err.Error()
+		})
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		backoff = min(backoff*2, webhookMaxBackoff)
+	}
+}
+
+// postEvent signs the payload and posts it to the webhook URL. Any 2xx
+// status acknowledges the delivery
+func (d *WebhookDispatcher) postEvent(
+	ctx context.Context, hook chain.Webhook, event chain.Event,
+) error {
+	payload := webhookPayload{
+		Webhook: hook.ID, Seq: event.Seq, Height: event.Height,
+		Type: event.Type.String(), Action: event.Action, Body: event.Body,
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(
+		ctx, http.MethodPost, hook.URL, bytes.NewReader(body),
+	)
+	if err != nil {
+		return err
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "RuChain-Webhook")
+	req.Header.Set("X-RuChain-Webhook", hook.ID)
+	req.Header.Set("X-RuChain-Seq", strconv.FormatUint(event.Seq, 10))
+	req.Header.Set("X-RuChain-Timestamp", strconv.FormatInt(timestamp, 10))
+	req.Header.Set(
+		"X-RuChain-Signature", chain.WebhookSignature(hook.Secret, timestamp, body),
+	)
+	res, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("status %v", res.Status)
+	}
+	return nil
+}